@@ -0,0 +1,102 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	cl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BlockingResourcesConfigMap is the name of the ConfigMap in the operator's namespace that adds kinds to, or
+	// waives kinds of, the resources blocking deletion of a MultiClusterEngine
+	BlockingResourcesConfigMap = "backplane-operator-blocking-resources"
+	// BlockingResourcesKey is the ConfigMap key holding the list of blocking kinds
+	BlockingResourcesKey = "blockingResources"
+)
+
+// defaultBlockingResources lists the kinds that block deletion unless the ConfigMap waives them
+//
+//go:embed blocking_resources.yaml
+var defaultBlockingResources []byte
+
+// blockingKind is a kind whose resources block deletion of a MultiClusterEngine
+type blockingKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Waived removes a kind of the default list
+	Waived bool `json:"waived,omitempty"`
+}
+
+// listGVK returns the GroupVersionKind to list resources of the kind with
+func (k blockingKind) listGVK() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: k.Group, Version: k.Version, Kind: k.Kind + "List"}
+}
+
+// parseBlockingKinds reads a YAML list of blocking kinds
+func parseBlockingKinds(data []byte) ([]blockingKind, error) {
+	kinds := []blockingKind{}
+	if err := yaml.UnmarshalStrict(data, &kinds); err != nil {
+		return nil, err
+	}
+	for _, k := range kinds {
+		if k.Version == "" || k.Kind == "" {
+			return nil, fmt.Errorf("blocking resource %+v must set a version and kind", k)
+		}
+	}
+	return kinds, nil
+}
+
+// blockingKinds returns the kinds that block deletion: the default list, updated by the entries of the blocking
+// resources ConfigMap if it exists. An entry for a kind already listed replaces it.
+func blockingKinds(ctx context.Context, c cl.Reader) ([]blockingKind, error) {
+	kinds, err := parseBlockingKinds(defaultBlockingResources)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		return kinds, nil
+	}
+	cm := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Name: BlockingResourcesConfigMap, Namespace: namespace}, cm)
+	if apierrors.IsNotFound(err) {
+		return kinds, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get ConfigMap %s: %s", BlockingResourcesConfigMap, err)
+	}
+	configured, err := parseBlockingKinds([]byte(cm.Data[BlockingResourcesKey]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in ConfigMap %s: %s", BlockingResourcesKey, BlockingResourcesConfigMap, err)
+	}
+
+	for _, k := range configured {
+		replaced := false
+		for i := range kinds {
+			if kinds[i].Group == k.Group && kinds[i].Kind == k.Kind {
+				kinds[i], replaced = k, true
+			}
+		}
+		if !replaced {
+			kinds = append(kinds, k)
+		}
+	}
+	result := []blockingKind{}
+	for _, k := range kinds {
+		if !k.Waived {
+			result = append(result, k)
+		}
+	}
+	return result, nil
+}
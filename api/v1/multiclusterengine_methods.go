@@ -18,6 +18,15 @@ limitations under the License.
 
 package v1
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
 const (
 	ManagedServiceAccount string = "managedserviceaccount-preview"
 	ConsoleMCE            string = "console-mce"
@@ -28,8 +37,14 @@ const (
 	ClusterManager        string = "cluster-manager"
 	ServerFoundation      string = "server-foundation"
 	HyperShift            string = "hypershift-preview"
+	ClusterBackup         string = "cluster-backup"
+	ClusterProxyAddon     string = "cluster-proxy-addon"
 )
 
+// AnnotationIgnoreExistingResources lets a MultiClusterEngine be deleted while resources that depend on it still
+// exist, for disaster recovery when those resources can't be cleaned up first
+const AnnotationIgnoreExistingResources = "multicluster.openshift.io/ignore-existing-resources"
+
 var allComponents = []string{
 	AssistedService,
 	ClusterLifecycle,
@@ -40,6 +55,47 @@ var allComponents = []string{
 	ConsoleMCE,
 	ManagedServiceAccount,
 	HyperShift,
+	ClusterBackup,
+	ClusterProxyAddon,
+}
+
+// defaultEnabledComponents are enabled when the MultiClusterEngine does not configure them
+var defaultEnabledComponents = []string{
+	AssistedService,
+	ClusterLifecycle,
+	ClusterManager,
+	Discovery,
+	Hive,
+	ServerFoundation,
+	// ConsoleMCE, // determined by OCP version
+	// HyperShift,
+}
+
+// defaultDisabledComponents are disabled when the MultiClusterEngine does not configure them
+var defaultDisabledComponents = []string{
+	ManagedServiceAccount,
+	HyperShift,
+	ClusterBackup,
+	ClusterProxyAddon,
+}
+
+// SetDefaultComponents adds the components the MultiClusterEngine does not configure with their default state.
+// It returns true if changes are made.
+func (mce *MultiClusterEngine) SetDefaultComponents() bool {
+	updated := false
+	for _, c := range defaultEnabledComponents {
+		if !mce.ComponentPresent(c) {
+			mce.Enable(c)
+			updated = true
+		}
+	}
+	for _, c := range defaultDisabledComponents {
+		if !mce.ComponentPresent(c) {
+			mce.Disable(c)
+			updated = true
+		}
+	}
+	return updated
 }
 
 func (mce *MultiClusterEngine) ComponentPresent(s string) bool {
@@ -67,6 +123,19 @@ func (mce *MultiClusterEngine) Enabled(s string) bool {
 	return false
 }
 
+// GetComponentConfig returns the override configuration for the named component, or nil if none is defined
+func (mce *MultiClusterEngine) GetComponentConfig(s string) *ComponentConfig {
+	if mce.Spec.Overrides == nil {
+		return nil
+	}
+	for i, c := range mce.Spec.Overrides.Components {
+		if c.Name == s {
+			return &mce.Spec.Overrides.Components[i]
+		}
+	}
+	return nil
+}
+
 func (mce *MultiClusterEngine) Enable(s string) {
 	if mce.Spec.Overrides == nil {
 		mce.Spec.Overrides = &Overrides{}
@@ -99,6 +168,134 @@ func (mce *MultiClusterEngine) Disable(s string) {
 	})
 }
 
+const (
+	minProgressDeadlineSeconds = 60
+	maxProgressDeadlineSeconds = 3600
+)
+
+// validateComponentOverrides returns an error if a component's optional settings are out of bounds
+func validateComponentOverrides(c ComponentConfig) error {
+	if c.ProgressDeadlineSeconds != nil {
+		pds := *c.ProgressDeadlineSeconds
+		if pds < minProgressDeadlineSeconds || pds > maxProgressDeadlineSeconds {
+			return fmt.Errorf("invalid component config: %s progressDeadlineSeconds must be between %d and %d",
+				c.Name, minProgressDeadlineSeconds, maxProgressDeadlineSeconds)
+		}
+	}
+	if c.Replicas != nil && *c.Replicas < 1 {
+		return fmt.Errorf("invalid component config: %s replicas must be at least 1", c.Name)
+	}
+	if c.Resources != nil {
+		return validateResources(c.Name, c.Resources)
+	}
+	return nil
+}
+
+// validateResources returns an error if a resource quantity is negative or a request exceeds its limit
+func validateResources(component string, resources *corev1.ResourceRequirements) error {
+	for name, quantity := range resources.Requests {
+		if quantity.Sign() < 0 {
+			return fmt.Errorf("invalid component config: %s %s request must not be negative", component, name)
+		}
+		if limit, ok := resources.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+			return fmt.Errorf("invalid component config: %s %s request must not exceed its limit", component, name)
+		}
+	}
+	for name, quantity := range resources.Limits {
+		if quantity.Sign() < 0 {
+			return fmt.Errorf("invalid component config: %s %s limit must not be negative", component, name)
+		}
+	}
+	return nil
+}
+
+// validateTopologySpreadConstraints returns an error if a topology spread constraint override is invalid
+func validateTopologySpreadConstraints(o *Overrides) error {
+	if o == nil {
+		return nil
+	}
+	for _, tsc := range o.TopologySpreadConstraints {
+		if tsc.MaxSkew < 1 {
+			return fmt.Errorf("invalid topologySpreadConstraints: maxSkew for topologyKey %s must be at least 1", tsc.TopologyKey)
+		}
+	}
+	return nil
+}
+
+// validateMetadataOverrides returns an error if a label or annotation override is not a valid label or
+// annotation
+func validateMetadataOverrides(o *Overrides) error {
+	if o == nil {
+		return nil
+	}
+	for key, value := range o.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid labels: %s: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid labels: value of %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	for key := range o.Annotations {
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("invalid annotations: %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// IsHosted returns true if the components are installed on a hosted cluster rather than the local one
+func (mce *MultiClusterEngine) IsHosted() bool {
+	return mce.Spec.DeploymentMode == ModeHosted
+}
+
+// IgnoresExistingResources returns true if the MultiClusterEngine is annotated to be deleted regardless of the
+// resources blocking deletion
+func (mce *MultiClusterEngine) IgnoresExistingResources() bool {
+	return strings.EqualFold(mce.GetAnnotations()[AnnotationIgnoreExistingResources], "true")
+}
+
+// SameTarget returns true if both MultiClusterEngines install on the same cluster. Only one may install on the
+// local cluster, as its components share cluster-scoped resources, and only one on each hosted cluster.
+func (mce *MultiClusterEngine) SameTarget(other *MultiClusterEngine) bool {
+	if mce.IsHosted() != other.IsHosted() {
+		return false
+	}
+	return !mce.IsHosted() || mce.Spec.HostedKubeconfigSecret == other.Spec.HostedKubeconfigSecret
+}
+
+// validateDeploymentMode returns an error if the hosted deployment mode is missing its kubeconfig secret
+func validateDeploymentMode(mce *MultiClusterEngine) error {
+	if mce.IsHosted() && mce.Spec.HostedKubeconfigSecret == "" {
+		return errors.New("hostedKubeconfigSecret is required in the Hosted deployment mode")
+	}
+	return nil
+}
+
+// validateImmutableFields returns an error if an update changes a field that cannot change after the
+// multiclusterengine is installed, as the operator does not move resources it already created
+func validateImmutableFields(oldMCE, newMCE *MultiClusterEngine) error {
+	if oldMCE.Spec.TargetNamespace != "" && newMCE.Spec.TargetNamespace != oldMCE.Spec.TargetNamespace {
+		return errors.New("TargetNamespace is immutable after creation")
+	}
+
+	oldNS, newNS := "", ""
+	if oldMCE.Spec.Overrides != nil {
+		oldNS = oldMCE.Spec.Overrides.InfrastructureCustomNamespace
+	}
+	if newMCE.Spec.Overrides != nil {
+		newNS = newMCE.Spec.Overrides.InfrastructureCustomNamespace
+	}
+	if oldNS != newNS {
+		return errors.New("InfrastructureCustomNamespace is immutable after creation")
+	}
+
+	if oldMCE.IsHosted() != newMCE.IsHosted() {
+		return errors.New("DeploymentMode is immutable after creation")
+	}
+	return nil
+}
+
 // a component is valid if its name matches a known component
 func validComponent(c ComponentConfig) bool {
 	for _, name := range allComponents {
@@ -23,6 +23,36 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// UninstallPolicyType ...
+type UninstallPolicyType string
+
+const (
+	// UninstallPolicyOrphan leaves the operand CRDs in place when the MultiClusterEngine is deleted
+	UninstallPolicyOrphan UninstallPolicyType = "Orphan"
+	// UninstallPolicyDelete removes the operand CRDs when the MultiClusterEngine is deleted
+	UninstallPolicyDelete UninstallPolicyType = "Delete"
+)
+
+// DeploymentMode ...
+type DeploymentMode string
+
+const (
+	// ModeDefault installs the components on the cluster the operator runs on
+	ModeDefault DeploymentMode = "Default"
+	// ModeHosted installs the components on a remote cluster
+	ModeHosted DeploymentMode = "Hosted"
+)
+
+// SchedulingProfile ...
+type SchedulingProfile string
+
+const (
+	// SchedulingDefault places the component pods using only the nodeSelector and tolerations
+	SchedulingDefault SchedulingProfile = "Default"
+	// SchedulingInfra places the component pods on infra nodes where available
+	SchedulingInfra SchedulingProfile = "Infra"
+)
+
 // AvailabilityType ...
 type AvailabilityType string
 
@@ -34,9 +64,14 @@ const (
 )
 
 // MultiClusterEngineSpec defines the desired state of MultiClusterEngine
+//+kubebuilder:validation:XValidation:rule="!has(self.deploymentMode) || self.deploymentMode != 'Hosted' || (has(self.hostedKubeconfigSecret) && self.hostedKubeconfigSecret != '')",message="hostedKubeconfigSecret is required in the Hosted deployment mode"
+//+kubebuilder:validation:XValidation:rule="!has(oldSelf.targetNamespace) || has(self.targetNamespace) && self.targetNamespace == oldSelf.targetNamespace",message="TargetNamespace is immutable after creation"
+//+kubebuilder:validation:XValidation:rule="(has(self.deploymentMode) && self.deploymentMode == 'Hosted') == (has(oldSelf.deploymentMode) && oldSelf.deploymentMode == 'Hosted')",message="DeploymentMode is immutable after creation"
+//+kubebuilder:validation:XValidation:rule="has(self.overrides) && has(self.overrides.infrastructureCustomNamespace) ? has(oldSelf.overrides) && has(oldSelf.overrides.infrastructureCustomNamespace) && self.overrides.infrastructureCustomNamespace == oldSelf.overrides.infrastructureCustomNamespace : !has(oldSelf.overrides) || !has(oldSelf.overrides.infrastructureCustomNamespace)",message="InfrastructureCustomNamespace is immutable after creation"
 type MultiClusterEngineSpec struct {
 
 	// Specifies deployment replication for improved availability. Options are: Basic and High (default)
+	//+kubebuilder:validation:Enum=High;Basic
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Availability Configuration",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:High","urn:alm:descriptor:com.tectonic.ui:select:Basic"}
 	AvailabilityConfig AvailabilityType `json:"availabilityConfig,omitempty"`
 
@@ -54,17 +89,120 @@ type MultiClusterEngineSpec struct {
 	// Tolerations causes all components to tolerate any taints.
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
-	// Location where MCE resources will be placed
+	// Location where MCE resources will be placed. It cannot be changed or removed once set.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Target Namespace",xDescriptors={"urn:alm:descriptor:io.kubernetes:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// UninstallPolicy determines what happens to the operand CRDs when the MultiClusterEngine is deleted. Orphan,
+	// the default, leaves the CRDs and their custom resources in place for a reinstall. Delete removes them.
+	//+kubebuilder:validation:Enum=Orphan;Delete
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Uninstall Policy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:Orphan","urn:alm:descriptor:com.tectonic.ui:select:Delete"}
+	UninstallPolicy UninstallPolicyType `json:"uninstallPolicy,omitempty"`
+
+	// DeploymentMode determines where the components are installed. Default installs them on the cluster the
+	// operator runs on. Hosted installs them on the cluster whose kubeconfig is in HostedKubeconfigSecret.
+	//+kubebuilder:validation:Enum=Default;Hosted
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Deployment Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:Default","urn:alm:descriptor:com.tectonic.ui:select:Hosted"}
+	DeploymentMode DeploymentMode `json:"deploymentMode,omitempty"`
+
+	// Name of the secret in the operator's namespace holding the kubeconfig of the hosted cluster under its
+	// kubeconfig key. Required in the Hosted deployment mode.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Hosted Kubeconfig Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	HostedKubeconfigSecret string `json:"hostedKubeconfigSecret,omitempty"`
+
+	// SchedulingProfile places the component pods. Default uses the nodeSelector and tolerations as set. Infra
+	// also makes the pods tolerate the NoSchedule and NoExecute infra taints and prefer nodes with the infra role.
+	//+kubebuilder:validation:Enum=Default;Infra
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scheduling Profile",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:Default","urn:alm:descriptor:com.tectonic.ui:select:Infra"}
+	SchedulingProfile SchedulingProfile `json:"schedulingProfile,omitempty"`
+
+	// DryRun renders the manifests of the install without applying them. They are written, together with any
+	// rendering errors, to the ConfigMap named after the MultiClusterEngine with a -dry-run suffix in the
+	// operator's namespace.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Dry Run",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// LogLevel sets the log level of the operator and its components, one of error, info or debug. It takes
+	// effect without restarting the operator, while the component deployments roll out with the new level. The
+	// operator uses the most verbose level of all MultiClusterEngines. A level set on any MultiClusterEngine
+	// overrides the log level ConfigMap. Unset on all of them leaves the operator's level to its log level
+	// ConfigMap and --log-level flag.
+	//+kubebuilder:validation:Enum=error;info;debug
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Log Level",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:select:error","urn:alm:descriptor:com.tectonic.ui:select:info","urn:alm:descriptor:com.tectonic.ui:select:debug"}
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// LocalClusterEnabled imports the hub cluster as a ManagedCluster named local-cluster. Disabling it, or
+	// deleting the MultiClusterEngine, detaches the local-cluster again.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Local Cluster Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	LocalClusterEnabled bool `json:"localClusterEnabled,omitempty"`
 }
 
 // ComponentConfig provides optional configuration items for individual components
 type ComponentConfig struct {
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	Name    string `json:"name"`
 	Enabled bool   `json:"enabled"`
+
+	// Overrides the number of seconds the component's deployments may take to progress before
+	// they are considered failed
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// Overrides the number of replicas of the component's deployments. When set this takes
+	// precedence over the count derived from availabilityConfig.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Do not mount the trusted CA bundle into the component's pods
+	// +optional
+	SkipTrustedCABundle bool `json:"skipTrustedCABundle,omitempty"`
+
+	// Do not enable FIPS crypto in the component's pods when the cluster runs in FIPS mode, for
+	// components that don't support it yet
+	// +optional
+	SkipFIPS bool `json:"skipFIPS,omitempty"`
+
+	// What happens when the component's liveness probe fails. Restart (the default) leaves the probe
+	// as is so the kubelet restarts the container. Unready replaces the liveness probe with a readiness
+	// probe so the pod is removed from service instead. This avoids cascading restarts on transient
+	// failures, at the cost of a hung container never recovering on its own.
+	// +kubebuilder:validation:Enum=Restart;Unready
+	// +optional
+	LivenessFailurePolicy LivenessFailurePolicy `json:"livenessFailurePolicy,omitempty"`
+
+	// Scale the component's deployments to zero, for example during maintenance. The rest of the
+	// MultiClusterEngine keeps running and the component is reported as paused. Unsetting it restores
+	// the replica count.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Environment variables to set in the containers of the component's deployments, for example to
+	// turn on feature gates. They take precedence over variables of the same name set by the operator.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// CPU and memory requests and limits for the containers of the component's deployments. Each
+	// quantity set here replaces the default of the same resource; the others are left unchanged.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
+// LivenessFailurePolicy describes how a component responds to a failing liveness probe
+type LivenessFailurePolicy string
+
+const (
+	// LivenessFailureRestart restarts the container when its liveness probe fails
+	LivenessFailureRestart LivenessFailurePolicy = "Restart"
+	// LivenessFailureUnready marks the pod unready when its liveness probe fails
+	LivenessFailureUnready LivenessFailurePolicy = "Unready"
+)
+
 // Overrides provides developer overrides for MCE installation
 type Overrides struct {
 	// Pull policy for the MCE images
@@ -75,10 +213,95 @@ type Overrides struct {
 	// +optional
 	Components []ComponentConfig `json:"components,omitempty"`
 
-	// Namespace to install Assisted Installer operator
+	// Namespace to install Assisted Installer operator. It can only be set when the MultiClusterEngine is created.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom Infrastructure Operator Namespace",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
 	// +optional
 	InfrastructureCustomNamespace string `json:"infrastructureCustomNamespace,omitempty"`
+
+	// Name of a ConfigMap in the target namespace holding a trusted CA bundle under the key ca-bundle.crt.
+	// The bundle is mounted into all component pods. If unset, a ConfigMap named trusted-ca-bundle is used
+	// when present.
+	// +optional
+	TrustedCABundleConfigMap string `json:"trustedCABundleConfigMap,omitempty"`
+
+	// Name of the PriorityClass given to all component pods. Changing it rolls out the component deployments.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Security settings applied to all component pods, for example to satisfy the restricted Pod Security
+	// Standard. Changing it rolls out the component deployments.
+	// +optional
+	SecurityContext *SecurityContextOverrides `json:"securityContext,omitempty"`
+
+	// Affinity rules for all component pods. Each of nodeAffinity, podAffinity and podAntiAffinity that is
+	// set replaces the one derived from availabilityConfig.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Topology spread constraints for all component pods, for example to spread replicas across zones.
+	// A constraint without a labelSelector selects the pods of the component's own deployment.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Registry that replaces the registry of every component image, for example a mirror registry in a
+	// disconnected install. The repository path and tag or digest of each image are kept. Images overridden
+	// individually are not rewritten.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Replace the tag of every component image with the digest it points to, looked up in the mirrors of
+	// the cluster's ImageContentSourcePolicies and ImageDigestMirrorSets before the source registry. Tags are
+	// resolved once per operator run with the cluster pull secret and imagePullSecret.
+	// +optional
+	ResolveImageDigests bool `json:"resolveImageDigests,omitempty"`
+
+	// Require the pods of each component deployment to run on nodes of an architecture all of its images are
+	// built for, as listed in the image manifests. The architectures are looked up in the same mirrors and with
+	// the same credentials as resolveImageDigests.
+	// +optional
+	ArchitectureAffinity bool `json:"architectureAffinity,omitempty"`
+
+	// Name of a ConfigMap in the target namespace holding patches for the rendered component manifests. Each
+	// key is a file of a kustomize overlay: strategic merge patches, JSON 6902 patches and an optional
+	// kustomization.yaml listing them under patchesStrategicMerge and patchesJson6902.
+	// +optional
+	ManifestPatchesConfigMap string `json:"manifestPatchesConfigMap,omitempty"`
+
+	// Labels added to every resource the operator deploys and to the pods of component deployments, for
+	// example for cost allocation or backup policies. Labels the operator sets itself are not overridden.
+	// Changing them rolls out the component deployments.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations added to every resource the operator deploys and to the pods of component deployments.
+	// Annotations the operator sets itself are not overridden. Changing them rolls out the component
+	// deployments.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SecurityContextOverrides tightens the security context of component pods. Settings required by the
+// operator's own manifests are never loosened.
+type SecurityContextOverrides struct {
+	// Require all containers to run as a non-root user
+	// +optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// The seccomp profile used by component pods. An Unconfined profile is ignored.
+	// +optional
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+
+	// Capabilities dropped from every container, in addition to those the manifests already drop
+	// +optional
+	DropCapabilities []corev1.Capability `json:"dropCapabilities,omitempty"`
+
+	// Harden all component pods to the restricted Pod Security Standard and label the target namespace to
+	// enforce it. Containers run as non-root without privilege escalation, with all capabilities dropped and
+	// the RuntimeDefault seccomp profile unless another is set.
+	// +optional
+	Restricted bool `json:"restricted,omitempty"`
 }
 
 // MultiClusterEngineStatus defines the observed state of MultiClusterEngine
@@ -89,6 +312,68 @@ type MultiClusterEngineStatus struct {
 	Components []ComponentCondition `json:"components,omitempty"`
 
 	Conditions []MultiClusterEngineCondition `json:"conditions,omitempty"`
+
+	// The version of the operator that last brought the MultiClusterEngine to the Available phase
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// The version of the running operator, which the MultiClusterEngine is being brought to. While it differs
+	// from currentVersion an install or upgrade is in progress.
+	// +optional
+	DesiredVersion string `json:"desiredVersion,omitempty"`
+
+	// The generation of the spec that was last fully applied. While it differs from metadata.generation
+	// the latest spec change is still being rolled out.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The time of the last reconcile that completed without error
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// Existing resources that must be deleted before the MultiClusterEngine can be deleted. At most 100
+	// resources are listed.
+	// +optional
+	BlockingResources []BlockingResource `json:"blockingResources,omitempty"`
+
+	// The percentage of enabled components that are available. It is 100 only in the Available phase.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Progress int `json:"progress,omitempty"`
+
+	// The number of enabled components that are available out of the number enabled, for example 7/9
+	// +optional
+	AvailableComponents string `json:"availableComponents,omitempty"`
+
+	// The component images, keyed by image key, after the imageRegistry override and digest resolution are
+	// applied. Only set when an imageRegistry is configured or image digests are resolved.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// Resources that are still being removed while the MultiClusterEngine is uninstalling. The
+	// MultiClusterEngine is deleted once none remain. At most 100 resources are listed.
+	// +optional
+	RemainingResources []BlockingResource `json:"remainingResources,omitempty"`
+
+	// True when the cluster runs in FIPS mode and FIPS crypto is enabled in the component pods
+	// +optional
+	FIPSEnabled bool `json:"fipsEnabled,omitempty"`
+
+	// The MultiClusterHub, as namespace/name, that installed the MultiClusterEngine or shares the cluster with
+	// it. The components the MultiClusterHub still runs are left to it.
+	// +optional
+	MultiClusterHub string `json:"multiClusterHub,omitempty"`
+}
+
+// BlockingResource identifies a resource that prevents the MultiClusterEngine from being deleted
+type BlockingResource struct {
+	Kind string `json:"kind"`
+
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	Name string `json:"name"`
 }
 
 // ComponentCondition contains condition information for tracked components
@@ -99,6 +384,10 @@ type ComponentCondition struct {
 	// The resource kind this condition represents
 	Kind string `json:"kind,omitempty"`
 
+	// The namespace of the resource, if it is namespaced
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// Available indicates whether this component is considered properly running
 	Available bool `json:"-"`
 
@@ -123,6 +412,24 @@ type ComponentCondition struct {
 	// Message is a human-readable message indicating details about the last status change.
 	// +required
 	Message string `json:"message,omitempty"`
+
+	// DesiredReplicas is the number of replicas requested for the component
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// ReadyReplicas is the number of the component's replicas that are ready
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Retries is the number of consecutive failed attempts to apply the component
+	// +optional
+	Retries int `json:"retries,omitempty"`
+
+	// LastError is the error of the last failed attempt to apply the component
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// NextRetryTime is when the component is applied again after a failed attempt
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
 }
 
 // PhaseType is a summary of the current state of the MultiClusterEngine in its lifecycle
@@ -133,6 +440,8 @@ const (
 	MultiClusterEnginePhaseAvailable    PhaseType = "Available"
 	MultiClusterEnginePhaseUninstalling PhaseType = "Uninstalling"
 	MultiClusterEnginePhaseError        PhaseType = "Error"
+	MultiClusterEnginePhaseFailed       PhaseType = "Failed"
+	MultiClusterEnginePhaseDryRun       PhaseType = "DryRun"
 )
 
 type MultiClusterEngineConditionType string
@@ -147,6 +456,23 @@ const (
 	// up or old pods scale down. Progress is not estimated for paused deployments or
 	// when progressDeadlineSeconds is not specified.
 	MultiClusterEngineProgressing MultiClusterEngineConditionType = "Progressing"
+	// Degraded means one or more components have failed to roll out and require attention.
+	MultiClusterEngineDegraded MultiClusterEngineConditionType = "Degraded"
+	// ConfigReloaded reports whether the external configuration read from ConfigMaps, such as
+	// image overrides and the trusted CA bundle, was last loaded successfully.
+	MultiClusterEngineConfigReloaded MultiClusterEngineConditionType = "ConfigReloaded"
+	// Restored means the multiclusterengine was restored from a backup and the operator adopts the resources
+	// that already exist instead of recreating them.
+	MultiClusterEngineRestored MultiClusterEngineConditionType = "Restored"
+	// WebhookRepaired means the operator had to recreate or restore its validating webhook configuration
+	// after it was deleted or changed.
+	MultiClusterEngineWebhookRepaired MultiClusterEngineConditionType = "WebhookRepaired"
+	// PrerequisiteFailed means the cluster does not meet the requirements to install the multiclusterengine,
+	// such as the minimum OpenShift version, and the operator is waiting for them to be met.
+	MultiClusterEnginePrerequisiteFailed MultiClusterEngineConditionType = "PrerequisiteFailed"
+	// ComponentsUpgrading means the operator version changed and the components are being upgraded one at a
+	// time, in dependency order. It is false once every component runs the new version.
+	MultiClusterEngineComponentsUpgrading MultiClusterEngineConditionType = "ComponentsUpgrading"
 	// Failure is added in a deployment when one of its pods fails to be created
 	// or deleted.
 	MultiClusterEngineFailure MultiClusterEngineConditionType = "MultiClusterEngineFailure"
@@ -181,7 +507,10 @@ type MultiClusterEngineCondition struct {
 //+kubebuilder:resource:scope=Cluster,shortName=mce
 
 // MultiClusterEngine is the Schema for the multiclusterengines API
-//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="The overall state of the MultiClusterEngine"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The overall state of the MultiClusterEngine"
+//+kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.availableComponents",description="The number of enabled components that are available"
+//+kubebuilder:printcolumn:name="Current Version",type="string",JSONPath=".status.currentVersion",description="The version of the operator that last installed the MultiClusterEngine"
+//+kubebuilder:printcolumn:name="Desired Version",type="string",JSONPath=".status.desiredVersion",description="The version of the operator the MultiClusterEngine is being brought to",priority=1
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+operator-sdk:csv:customresourcedefinitions:displayName="MultiCluster Engine"
 type MultiClusterEngine struct {
@@ -0,0 +1,102 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("MultiClusterEngine CRD validation", func() {
+	Context("when a MultiClusterEngine is created", func() {
+		It("should require the kubeconfig secret in the Hosted deployment mode", func() {
+			mce := &MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{Name: "hosted-without-secret"},
+				Spec:       MultiClusterEngineSpec{DeploymentMode: ModeHosted},
+			}
+			err := k8sClient.Create(context.Background(), mce)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an invalid error, got %v", err)
+			Expect(err).To(MatchError(ContainSubstring("hostedKubeconfigSecret is required in the Hosted deployment mode")))
+		})
+
+		It("should reject a badly formed component name", func() {
+			mce := &MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{Name: "bad-component-name"},
+				Spec: MultiClusterEngineSpec{
+					Overrides: &Overrides{Components: []ComponentConfig{{Name: "Bad_Component", Enabled: true}}},
+				},
+			}
+			err := k8sClient.Create(context.Background(), mce)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an invalid error, got %v", err)
+			Expect(err).To(MatchError(ContainSubstring("spec.overrides.components[0].name")))
+		})
+	})
+
+	Context("when a MultiClusterEngine is updated", Ordered, func() {
+		var mce *MultiClusterEngine
+
+		BeforeAll(func() {
+			mce = &MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{Name: "immutable-fields"},
+				Spec: MultiClusterEngineSpec{
+					TargetNamespace: "mce-validation",
+					DeploymentMode:  ModeDefault,
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), mce)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(context.Background(), mce)).To(Succeed())
+			})
+		})
+
+		expectInvalidUpdate := func(update func(*MultiClusterEngine), message string) {
+			updated := &MultiClusterEngine{}
+			Expect(k8sClient.Get(context.Background(), cl.ObjectKeyFromObject(mce), updated)).To(Succeed())
+			update(updated)
+			err := k8sClient.Update(context.Background(), updated)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an invalid error, got %v", err)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		}
+
+		It("should reject changing the targetNamespace", func() {
+			expectInvalidUpdate(func(m *MultiClusterEngine) {
+				m.Spec.TargetNamespace = "other-namespace"
+			}, "TargetNamespace is immutable after creation")
+		})
+
+		It("should reject removing the targetNamespace", func() {
+			expectInvalidUpdate(func(m *MultiClusterEngine) {
+				m.Spec.TargetNamespace = ""
+			}, "TargetNamespace is immutable after creation")
+		})
+
+		It("should reject switching the deploymentMode", func() {
+			expectInvalidUpdate(func(m *MultiClusterEngine) {
+				m.Spec.DeploymentMode = ModeHosted
+				m.Spec.HostedKubeconfigSecret = "hosted-kubeconfig"
+			}, "DeploymentMode is immutable after creation")
+		})
+
+		It("should reject setting the infrastructureCustomNamespace after creation", func() {
+			expectInvalidUpdate(func(m *MultiClusterEngine) {
+				if m.Spec.Overrides == nil {
+					m.Spec.Overrides = &Overrides{}
+				}
+				m.Spec.Overrides.InfrastructureCustomNamespace = "infra-namespace"
+			}, "InfrastructureCustomNamespace is immutable after creation")
+		})
+
+		It("should allow updates that keep the immutable fields", func() {
+			updated := &MultiClusterEngine{}
+			Expect(k8sClient.Get(context.Background(), cl.ObjectKeyFromObject(mce), updated)).To(Succeed())
+			updated.Spec.AvailabilityConfig = HABasic
+			Expect(k8sClient.Update(context.Background(), updated)).To(Succeed())
+		})
+	})
+})
@@ -21,10 +21,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	ctrl "sigs.k8s.io/controller-runtime"
 	cl "sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,54 +40,21 @@ import (
 
 const (
 	DefaultTargetNamespace = "multicluster-engine"
+
+	// maxBlockingResources limits how many blocking resources are reported, to bound the status size
+	maxBlockingResources = 100
 )
 
 // log is for logging in this package.
 var (
 	backplaneconfiglog = logf.Log.WithName("backplaneconfig-resource")
-	Client             cl.Client
-
-	blockDeletionResources = []struct {
-		Name string
-		GVK  schema.GroupVersionKind
-	}{
-		{
-			Name: "ManagedCluster",
-			GVK: schema.GroupVersionKind{
-				Group:   "cluster.open-cluster-management.io",
-				Version: "v1",
-				Kind:    "ManagedClusterList",
-			},
-		},
-		{
-			Name: "BareMetalAsset",
-			GVK: schema.GroupVersionKind{
-				Group:   "inventory.open-cluster-management.io",
-				Version: "v1alpha1",
-				Kind:    "BareMetalAssetList",
-			},
-		},
-		{
-			Name: "DiscoveryConfig",
-			GVK: schema.GroupVersionKind{
-				Group:   "discovery.open-cluster-management.io",
-				Version: "v1",
-				Kind:    "DiscoveryConfigList",
-			},
-		},
-		{
-			Name: "AgentServiceConfig",
-			GVK: schema.GroupVersionKind{
-				Group:   "agent-install.openshift.io",
-				Version: "v1beta1",
-				Kind:    "AgentServiceConfigList",
-			},
-		},
-	}
+	// Client reads directly from the API server so checks see every namespace, even when the manager's
+	// cache is scoped to the watched namespaces
+	Client cl.Reader
 )
 
 func (r *MultiClusterEngine) SetupWebhookWithManager(mgr ctrl.Manager) error {
-	Client = mgr.GetClient()
+	Client = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -91,12 +63,18 @@ func (r *MultiClusterEngine) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 var _ webhook.Defaulter = &MultiClusterEngine{}
 
-// Default implements webhook.Defaulter so a webhook will be registered for the type
+// Default implements webhook.Defaulter so a webhook will be registered for the type. It fills in the
+// availability, target namespace and component defaults so they are visible in the stored spec. The
+// controller applies the same defaults when the webhook is not in use.
 func (r *MultiClusterEngine) Default() {
 	backplaneconfiglog.Info("default", "name", r.Name)
+	if r.Spec.AvailabilityConfig == "" {
+		r.Spec.AvailabilityConfig = HAHigh
+	}
 	if r.Spec.TargetNamespace == "" {
 		r.Spec.TargetNamespace = DefaultTargetNamespace
 	}
+	r.SetDefaultComponents()
 }
 
 var _ webhook.Validator = &MultiClusterEngine{}
@@ -116,18 +94,43 @@ func (r *MultiClusterEngine) ValidateCreate() error {
 			if !validComponent(c) {
 				return errors.New(fmt.Sprintf("invalid component config: %s is not a known component", c.Name))
 			}
+			if err := validateComponentOverrides(c); err != nil {
+				return err
+			}
 		}
 	}
 
+	if err := validateTopologySpreadConstraints(r.Spec.Overrides); err != nil {
+		return err
+	}
+
+	if err := validateMetadataOverrides(r.Spec.Overrides); err != nil {
+		return err
+	}
+
+	if err := validatePriorityClass(r); err != nil {
+		return err
+	}
+
+	if err := validateDeploymentMode(r); err != nil {
+		return err
+	}
+
 	backplaneConfigList := &MultiClusterEngineList{}
 	if err := Client.List(ctx, backplaneConfigList); err != nil {
 		return fmt.Errorf("unable to list BackplaneConfigs: %s", err)
 	}
-	if len(backplaneConfigList.Items) == 0 {
-		return nil
+	for i := range backplaneConfigList.Items {
+		existing := &backplaneConfigList.Items[i]
+		if !r.SameTarget(existing) {
+			continue
+		}
+		if r.IsHosted() {
+			return fmt.Errorf("MultiClusterEngine %s already installs on the hosted cluster of secret %s", existing.Name, r.Spec.HostedKubeconfigSecret)
+		}
+		return errors.New("only 1 backplaneconfig resource may exist")
 	}
-	// TODO(user): fill in your validation logic upon object creation.
-	return errors.New("only 1 backplaneconfig resource may exist")
+	return nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -135,20 +138,8 @@ func (r *MultiClusterEngine) ValidateUpdate(old runtime.Object) error {
 	backplaneconfiglog.Info("validate update", "name", r.Name)
 
 	oldMCE := old.(*MultiClusterEngine)
-	backplaneconfiglog.Info(oldMCE.Spec.TargetNamespace)
-	if (r.Spec.TargetNamespace != oldMCE.Spec.TargetNamespace) && (oldMCE.Spec.TargetNamespace != "") {
-		return errors.New("changes cannot be made to target namespace")
-	}
-
-	oldNS, newNS := "", ""
-	if oldMCE.Spec.Overrides != nil {
-		oldNS = oldMCE.Spec.Overrides.InfrastructureCustomNamespace
-	}
-	if r.Spec.Overrides != nil {
-		newNS = r.Spec.Overrides.InfrastructureCustomNamespace
-	}
-	if oldNS != newNS {
-		return errors.New("changes cannot be made to InfrastructureCustomNamespace")
+	if err := validateImmutableFields(oldMCE, r); err != nil {
+		return err
 	}
 
 	if (r.Spec.AvailabilityConfig != HABasic) && (r.Spec.AvailabilityConfig != HAHigh) && (r.Spec.AvailabilityConfig != "") {
@@ -161,9 +152,31 @@ func (r *MultiClusterEngine) ValidateUpdate(old runtime.Object) error {
 			if !validComponent(c) {
 				return errors.New(fmt.Sprintf("invalid component config: %s is not a known component", c.Name))
 			}
+			if err := validateComponentOverrides(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := validateTopologySpreadConstraints(r.Spec.Overrides); err != nil {
+		return err
+	}
+
+	if err := validateMetadataOverrides(r.Spec.Overrides); err != nil {
+		return err
+	}
+
+	// A PriorityClass deleted after it was set must not block other edits, such as removing the finalizer
+	if r.GetDeletionTimestamp() == nil && priorityClassName(r) != priorityClassName(oldMCE) {
+		if err := validatePriorityClass(r); err != nil {
+			return err
 		}
 	}
 
+	if err := validateDeploymentMode(r); err != nil {
+		return err
+	}
+
 	// Block disable if relevant resources present
 	if r.ComponentPresent(Discovery) && !r.Enabled(Discovery) {
 		cfg, err := config.GetConfig()
@@ -185,7 +198,7 @@ func (r *MultiClusterEngine) ValidateUpdate(old runtime.Object) error {
 		list.SetGroupVersionKind(gvk)
 		err = discovery.ServerSupportsVersion(c, gvk.GroupVersion())
 		if err == nil {
-			if err := Client.List(context.TODO(), list); err != nil {
+			if err := listIfInstalled(context.TODO(), Client, list); err != nil {
 				return fmt.Errorf("unable to list %s: %s", "DiscoveryConfig", err)
 			}
 			if len(list.Items) != 0 {
@@ -201,31 +214,93 @@ func (r *MultiClusterEngine) ValidateUpdate(old runtime.Object) error {
 func (r *MultiClusterEngine) ValidateDelete() error {
 	// TODO(user): fill in your validation logic upon object deletion.
 	backplaneconfiglog.Info("validate delete", "name", r.Name)
-	ctx := context.Background()
 
-	cfg, err := config.GetConfig()
+	blocking, err := FindBlockingResources(context.Background(), Client)
 	if err != nil {
 		return err
 	}
+	if len(blocking) == 0 {
+		return nil
+	}
+	kinds := []string{}
+	for _, b := range blocking {
+		if len(kinds) == 0 || kinds[len(kinds)-1] != b.Kind {
+			kinds = append(kinds, b.Kind)
+		}
+	}
+	if r.IgnoresExistingResources() {
+		backplaneconfiglog.Info("deleting despite existing resources", "name", r.Name, "kinds", strings.Join(kinds, ", "),
+			"annotation", AnnotationIgnoreExistingResources)
+		return nil
+	}
+	return fmt.Errorf("cannot delete %s resource. Existing %s resources must first be deleted, or the resource annotated with %s=true to delete it regardless",
+		r.Name, strings.Join(kinds, ", "), AnnotationIgnoreExistingResources)
+}
 
-	c, err := discovery.NewDiscoveryClientForConfig(cfg)
+// FindBlockingResources returns the existing resources that prevent a MultiClusterEngine from being deleted,
+// grouped by kind. The kinds are the embedded defaults updated by the blocking resources ConfigMap. At most
+// maxBlockingResources are returned.
+func FindBlockingResources(ctx context.Context, c cl.Reader) ([]BlockingResource, error) {
+	kinds, err := blockingKinds(ctx, c)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	for _, resource := range blockDeletionResources {
+	blocking := []BlockingResource{}
+	for _, resource := range kinds {
 		list := &unstructured.UnstructuredList{}
-		list.SetGroupVersionKind(resource.GVK)
-		err := discovery.ServerSupportsVersion(c, list.GroupVersionKind().GroupVersion())
-		if err == nil {
-			if err := Client.List(ctx, list); err != nil {
-				return fmt.Errorf("unable to list %s: %s", resource.Name, err)
-			}
-			if len(list.Items) == 0 {
+		list.SetGroupVersionKind(resource.listGVK())
+		if err := listIfInstalled(ctx, c, list); err != nil {
+			return nil, fmt.Errorf("unable to list %s: %s", resource.Kind, err)
+		}
+		for _, item := range list.Items {
+			// The operator removes the resources it created itself, such as the local-cluster, when uninstalling
+			if _, managed := item.GetLabels()["backplaneconfig.name"]; managed {
 				continue
 			}
-			return fmt.Errorf("cannot delete %s resource. Existing %s resources must first be deleted", r.Name, resource.Name)
+			if len(blocking) == maxBlockingResources {
+				return blocking, nil
+			}
+			blocking = append(blocking, BlockingResource{
+				Kind:      resource.Kind,
+				Namespace: item.GetNamespace(),
+				Name:      item.GetName(),
+			})
 		}
 	}
+	return blocking, nil
+}
+
+// listIfInstalled lists resources into list. If the CRD for the kind is not installed then no resources of
+// that kind can exist, so the list is left empty rather than returning an error.
+func listIfInstalled(ctx context.Context, c cl.Reader, list *unstructured.UnstructuredList) error {
+	err := c.List(ctx, list)
+	if meta.IsNoMatchError(err) {
+		list.Items = nil
+		return nil
+	}
+	return err
+}
+
+// priorityClassName returns the PriorityClass set in the overrides, or an empty string if none is set
+func priorityClassName(r *MultiClusterEngine) string {
+	if r.Spec.Overrides == nil {
+		return ""
+	}
+	return r.Spec.Overrides.PriorityClassName
+}
+
+// validatePriorityClass returns an error if the MultiClusterEngine names a PriorityClass that does not exist
+func validatePriorityClass(r *MultiClusterEngine) error {
+	if r.Spec.Overrides == nil || r.Spec.Overrides.PriorityClassName == "" {
+		return nil
+	}
+	pc := &schedulingv1.PriorityClass{}
+	err := Client.Get(context.TODO(), types.NamespacedName{Name: r.Spec.Overrides.PriorityClassName}, pc)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("invalid priorityClassName: PriorityClass %s does not exist", r.Spec.Overrides.PriorityClassName)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get PriorityClass %s: %s", r.Spec.Overrides.PriorityClassName, err)
+	}
 	return nil
 }
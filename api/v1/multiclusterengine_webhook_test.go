@@ -0,0 +1,317 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("MultiClusterEngine webhook", func() {
+	Context("when a dependency CRD is not installed", func() {
+		It("should treat the kind as having no resources", func() {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "observability.open-cluster-management.io",
+				Version: "v1beta2",
+				Kind:    "MultiClusterObservabilityList",
+			})
+
+			Expect(listIfInstalled(context.Background(), k8sClient, list)).To(Succeed())
+			Expect(list.Items).To(BeEmpty())
+		})
+	})
+
+	Context("when resources blocking deletion exist", func() {
+		It("should list each of them", func() {
+			managedCluster := &unstructured.Unstructured{}
+			managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "cluster.open-cluster-management.io",
+				Version: "v1",
+				Kind:    "ManagedCluster",
+			})
+			managedCluster.SetName("local-cluster")
+			c := fake.NewClientBuilder().WithObjects(managedCluster).Build()
+
+			blocking, err := FindBlockingResources(context.Background(), c)
+			Expect(err).To(Succeed())
+			Expect(blocking).To(Equal([]BlockingResource{{Kind: "ManagedCluster", Name: "local-cluster"}}))
+		})
+
+		It("should not list the resources the operator created itself", func() {
+			managedCluster := &unstructured.Unstructured{}
+			managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "cluster.open-cluster-management.io",
+				Version: "v1",
+				Kind:    "ManagedCluster",
+			})
+			managedCluster.SetName("local-cluster")
+			managedCluster.SetLabels(map[string]string{"backplaneconfig.name": "engine"})
+			c := fake.NewClientBuilder().WithObjects(managedCluster).Build()
+
+			blocking, err := FindBlockingResources(context.Background(), c)
+			Expect(err).To(Succeed())
+			Expect(blocking).To(BeEmpty())
+		})
+
+		It("should only allow deletion when annotated to ignore them", func() {
+			managedCluster := &unstructured.Unstructured{}
+			managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "cluster.open-cluster-management.io",
+				Version: "v1",
+				Kind:    "ManagedCluster",
+			})
+			managedCluster.SetName("local-cluster")
+			defer func(c cl.Reader) { Client = c }(Client)
+			Client = fake.NewClientBuilder().WithObjects(managedCluster).Build()
+
+			mce := &MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "engine"}}
+			Expect(mce.ValidateDelete()).To(MatchError(ContainSubstring("Existing ManagedCluster resources must first be deleted")))
+
+			mce.SetAnnotations(map[string]string{AnnotationIgnoreExistingResources: "true"})
+			Expect(mce.ValidateDelete()).To(Succeed())
+		})
+	})
+
+	Context("when the blocking resources are configured", func() {
+		It("should add and waive kinds", func() {
+			os.Setenv("POD_NAMESPACE", "backplane")
+			defer os.Unsetenv("POD_NAMESPACE")
+
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: BlockingResourcesConfigMap, Namespace: "backplane"},
+				Data: map[string]string{BlockingResourcesKey: `
+- group: cluster.open-cluster-management.io
+  version: v1
+  kind: ManagedCluster
+  waived: true
+- group: observability.open-cluster-management.io
+  version: v1beta2
+  kind: MultiClusterObservability
+`},
+			}
+			managedCluster := &unstructured.Unstructured{}
+			managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "cluster.open-cluster-management.io",
+				Version: "v1",
+				Kind:    "ManagedCluster",
+			})
+			managedCluster.SetName("local-cluster")
+			observability := &unstructured.Unstructured{}
+			observability.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "observability.open-cluster-management.io",
+				Version: "v1beta2",
+				Kind:    "MultiClusterObservability",
+			})
+			observability.SetName("observability")
+			c := fake.NewClientBuilder().WithObjects(cm, managedCluster, observability).Build()
+
+			blocking, err := FindBlockingResources(context.Background(), c)
+			Expect(err).To(Succeed())
+			Expect(blocking).To(Equal([]BlockingResource{{Kind: "MultiClusterObservability", Name: "observability"}}))
+		})
+
+		It("should reject an invalid list", func() {
+			os.Setenv("POD_NAMESPACE", "backplane")
+			defer os.Unsetenv("POD_NAMESPACE")
+
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: BlockingResourcesConfigMap, Namespace: "backplane"},
+				Data:       map[string]string{BlockingResourcesKey: `- group: example.com`},
+			}
+			_, err := FindBlockingResources(context.Background(), fake.NewClientBuilder().WithObjects(cm).Build())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when defaults are applied", func() {
+		It("should fill in the unset fields and components", func() {
+			mce := &MultiClusterEngine{}
+			mce.Default()
+			Expect(mce.Spec.AvailabilityConfig).To(Equal(HAHigh))
+			Expect(mce.Spec.TargetNamespace).To(Equal(DefaultTargetNamespace))
+			Expect(mce.Enabled(ClusterManager)).To(BeTrue())
+			Expect(mce.ComponentPresent(HyperShift)).To(BeTrue())
+			Expect(mce.Enabled(HyperShift)).To(BeFalse())
+		})
+
+		It("should keep the configured values", func() {
+			mce := &MultiClusterEngine{Spec: MultiClusterEngineSpec{
+				AvailabilityConfig: HABasic,
+				TargetNamespace:    "engine",
+				Overrides:          &Overrides{Components: []ComponentConfig{{Name: Hive, Enabled: false}}},
+			}}
+			mce.Default()
+			Expect(mce.Spec.AvailabilityConfig).To(Equal(HABasic))
+			Expect(mce.Spec.TargetNamespace).To(Equal("engine"))
+			Expect(mce.Enabled(Hive)).To(BeFalse())
+		})
+	})
+
+	DescribeTable("when an update changes an immutable field",
+		func(oldSpec, newSpec MultiClusterEngineSpec, message string) {
+			oldMCE := &MultiClusterEngine{Spec: oldSpec}
+			newMCE := &MultiClusterEngine{Spec: newSpec}
+			err := validateImmutableFields(oldMCE, newMCE)
+			if message == "" {
+				Expect(err).To(Succeed())
+			} else {
+				Expect(err).To(MatchError(message))
+			}
+		},
+		Entry("allows an unchanged spec",
+			MultiClusterEngineSpec{TargetNamespace: "multicluster-engine"},
+			MultiClusterEngineSpec{TargetNamespace: "multicluster-engine"},
+			""),
+		Entry("allows the TargetNamespace to be set when it was empty",
+			MultiClusterEngineSpec{},
+			MultiClusterEngineSpec{TargetNamespace: "multicluster-engine"},
+			""),
+		Entry("rejects a TargetNamespace change",
+			MultiClusterEngineSpec{TargetNamespace: "multicluster-engine"},
+			MultiClusterEngineSpec{TargetNamespace: "other"},
+			"TargetNamespace is immutable after creation"),
+		Entry("rejects setting the InfrastructureCustomNamespace",
+			MultiClusterEngineSpec{},
+			MultiClusterEngineSpec{Overrides: &Overrides{InfrastructureCustomNamespace: "infra"}},
+			"InfrastructureCustomNamespace is immutable after creation"),
+		Entry("rejects an InfrastructureCustomNamespace change",
+			MultiClusterEngineSpec{Overrides: &Overrides{InfrastructureCustomNamespace: "infra"}},
+			MultiClusterEngineSpec{Overrides: &Overrides{InfrastructureCustomNamespace: "other"}},
+			"InfrastructureCustomNamespace is immutable after creation"),
+		Entry("rejects a DeploymentMode change",
+			MultiClusterEngineSpec{},
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-kubeconfig"},
+			"DeploymentMode is immutable after creation"),
+	)
+
+	DescribeTable("when another MultiClusterEngine exists",
+		func(spec, otherSpec MultiClusterEngineSpec, same bool) {
+			mce := &MultiClusterEngine{Spec: spec}
+			Expect(mce.SameTarget(&MultiClusterEngine{Spec: otherSpec})).To(Equal(same))
+		},
+		Entry("conflicts on the local cluster",
+			MultiClusterEngineSpec{TargetNamespace: "engine-a"},
+			MultiClusterEngineSpec{TargetNamespace: "engine-b"},
+			true),
+		Entry("allows a hosted instance next to a local one",
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-a"},
+			MultiClusterEngineSpec{},
+			false),
+		Entry("allows instances on different hosted clusters",
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-a"},
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-b"},
+			false),
+		Entry("conflicts on the same hosted cluster",
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-a"},
+			MultiClusterEngineSpec{DeploymentMode: ModeHosted, HostedKubeconfigSecret: "hosted-a"},
+			true),
+	)
+
+	DescribeTable("when a component overrides its resources",
+		func(resources corev1.ResourceRequirements, message string) {
+			err := validateComponentOverrides(ComponentConfig{Name: Hive, Enabled: true, Resources: &resources})
+			if message == "" {
+				Expect(err).To(Succeed())
+			} else {
+				Expect(err).To(MatchError(message))
+			}
+		},
+		Entry("allows requests within the limits",
+			corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+			""),
+		Entry("rejects a negative request",
+			corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("-1Gi")}},
+			"invalid component config: hive memory request must not be negative"),
+		Entry("rejects a negative limit",
+			corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("-1")}},
+			"invalid component config: hive cpu limit must not be negative"),
+		Entry("rejects a request above its limit",
+			corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			"invalid component config: hive memory request must not exceed its limit"),
+	)
+
+	DescribeTable("when labels and annotations are overridden",
+		func(overrides Overrides, message string) {
+			err := validateMetadataOverrides(&overrides)
+			if message == "" {
+				Expect(err).To(Succeed())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			}
+		},
+		Entry("allows valid labels and annotations",
+			Overrides{
+				Labels:      map[string]string{"example.com/cost-center": "1234"},
+				Annotations: map[string]string{"example.com/Owner": "platform team"},
+			},
+			""),
+		Entry("rejects an invalid label key",
+			Overrides{Labels: map[string]string{"cost center": "1234"}},
+			"invalid labels: cost center"),
+		Entry("rejects an invalid label value",
+			Overrides{Labels: map[string]string{"cost-center": "not valid"}},
+			"invalid labels: value of cost-center"),
+		Entry("rejects an invalid annotation key",
+			Overrides{Annotations: map[string]string{"-owner": "platform"}},
+			"invalid annotations: -owner"),
+	)
+
+	Context("when the PriorityClass is deleted after it was set", func() {
+		var oldMCE *MultiClusterEngine
+
+		BeforeEach(func() {
+			oldMCE = &MultiClusterEngine{Spec: MultiClusterEngineSpec{Overrides: &Overrides{PriorityClassName: "removed"}}}
+			previous := Client
+			DeferCleanup(func() { Client = previous })
+			Client = fake.NewClientBuilder().Build()
+		})
+
+		It("should allow updates that keep it", func() {
+			mce := oldMCE.DeepCopy()
+			mce.Spec.AvailabilityConfig = HABasic
+			Expect(mce.ValidateUpdate(oldMCE)).To(Succeed())
+		})
+
+		It("should allow the update removing the finalizer of a deleted MultiClusterEngine", func() {
+			mce := oldMCE.DeepCopy()
+			mce.Spec.Overrides.PriorityClassName = "other"
+			now := metav1.Now()
+			mce.SetDeletionTimestamp(&now)
+			Expect(mce.ValidateUpdate(oldMCE)).To(Succeed())
+		})
+
+		It("should reject changing it to a PriorityClass that does not exist", func() {
+			mce := oldMCE.DeepCopy()
+			mce.Spec.Overrides.PriorityClassName = "other"
+			Expect(mce.ValidateUpdate(oldMCE)).To(MatchError("invalid priorityClassName: PriorityClass other does not exist"))
+		})
+	})
+
+	Context("when the Hosted deployment mode is set", func() {
+		It("should require the kubeconfig secret", func() {
+			mce := &MultiClusterEngine{Spec: MultiClusterEngineSpec{DeploymentMode: ModeHosted}}
+			Expect(validateDeploymentMode(mce)).To(MatchError("hostedKubeconfigSecret is required in the Hosted deployment mode"))
+
+			mce.Spec.HostedKubeconfigSecret = "hosted-kubeconfig"
+			Expect(validateDeploymentMode(mce)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,171 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fieldManager is the field manager the operator applies resources as
+const fieldManager = "backplane-operator"
+
+// applyConflictError is returned when a resource is not applied because it would overwrite fields set by another
+// field manager
+type applyConflictError struct {
+	message string
+}
+
+func (e *applyConflictError) Error() string {
+	return e.message
+}
+
+// unchanged returns true if existing was applied from the manifest with the given hash and still has every field
+// obj sets, so a field that was changed by hand since the last apply is applied again
+func unchanged(existing, obj *unstructured.Unstructured, hash string) bool {
+	if existing.GetAnnotations()[utils.AnnotationManifestHash] != hash {
+		return false
+	}
+	for key, value := range obj.Object {
+		if key == "metadata" {
+			continue
+		}
+		if !containsFields(existing.Object[key], value) {
+			return false
+		}
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		live, _, _ := unstructured.NestedFieldNoCopy(existing.Object, "metadata", field)
+		desired, _, _ := unstructured.NestedFieldNoCopy(obj.Object, "metadata", field)
+		if !containsFields(live, desired) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFields returns true if live has every field set in desired with the same value. Fields that only live
+// sets, such as those defaulted by the API server, are ignored. List items are compared in order.
+func containsFields(live, desired interface{}) bool {
+	switch desired := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return len(desired) == 0 && live == nil
+		}
+		for key, value := range desired {
+			if !containsFields(liveMap[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			return len(desired) == 0 && live == nil
+		}
+		if len(liveList) != len(desired) {
+			return false
+		}
+		for i := range desired {
+			if !containsFields(liveList[i], desired[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(live, desired)
+	}
+}
+
+// manifestHash returns a hash of the rendered manifest, leaving out the hash annotation itself
+func manifestHash(obj *unstructured.Unstructured) (string, error) {
+	manifest := obj.DeepCopy()
+	annotations := manifest.GetAnnotations()
+	delete(annotations, utils.AnnotationManifestHash)
+	if len(annotations) == 0 {
+		// An empty map would still be marshalled, so a manifest without annotations hashes the same either way
+		unstructured.RemoveNestedField(manifest.Object, "metadata", "annotations")
+	} else {
+		manifest.SetAnnotations(annotations)
+	}
+	b, err := json.Marshal(manifest.Object)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// applyResource server-side applies obj. The hash of the manifest is stored in an annotation, and a resource
+// that was applied from the same manifest and still has its fields is skipped, so a resync doesn't rewrite every
+// resource. Fields set by others that the operator doesn't manage, such as the replicas of a scaled deployment,
+// are left alone. If another field manager changed a field the operator manages, the resource is not applied and
+// the conflict is reported with a Progressing condition. Resources annotated for adoption, and those of a
+// restored backplaneConfig, are applied regardless, taking the fields over.
+func (r *MultiClusterEngineReconciler) applyResource(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, obj *unstructured.Unstructured) error {
+	// Chart resources already have them, but custom resources and CRDs are not rendered from a chart
+	if err := renderer.ApplyMetadataOverrides(obj, backplaneConfig); err != nil {
+		return err
+	}
+
+	hash, err := manifestHash(obj)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[utils.AnnotationManifestHash] = hash
+	obj.SetAnnotations(annotations)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	getErr := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, existing)
+	if getErr == nil && unchanged(existing, obj, hash) {
+		return nil
+	}
+
+	err = r.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager))
+	if apierrors.IsConflict(err) && getErr == nil {
+		err = r.resolveApplyConflict(ctx, backplaneConfig, obj, existing, err)
+	}
+	return err
+}
+
+// resolveApplyConflict force applies obj if existing is annotated for adoption or the backplaneConfig was
+// restored, and reports the conflict otherwise
+func (r *MultiClusterEngineReconciler) resolveApplyConflict(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine,
+	obj, existing *unstructured.Unstructured, conflict error) error {
+	if strings.EqualFold(existing.GetAnnotations()[utils.AnnotationAdopt], "true") || utils.IsRestored(backplaneConfig) {
+		log.FromContext(ctx).Info(fmt.Sprintf("Overwriting changes to %s %s", obj.GetKind(), obj.GetName()))
+		return r.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	}
+
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	message := fmt.Sprintf("%s %s was changed outside the operator and was not updated: %s. Add the annotation %s=true to it to let the operator overwrite the changes.",
+		obj.GetKind(), name, conflict.Error(), utils.AnnotationAdopt)
+	r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.ApplyConflictReason, message))
+	return &applyConflictError{message: message}
+}
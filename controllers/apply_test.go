@@ -0,0 +1,88 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Applied resources", func() {
+	newConfigMap := func(data map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"data":       data,
+		}}
+		return obj
+	}
+
+	It("should hash the manifest without the hash annotation", func() {
+		obj := newConfigMap(map[string]interface{}{"a": "1", "b": "2"})
+		hash, err := manifestHash(obj)
+		Expect(err).NotTo(HaveOccurred())
+
+		obj.SetAnnotations(map[string]string{utils.AnnotationManifestHash: hash})
+		Expect(manifestHash(obj)).To(Equal(hash))
+
+		Expect(manifestHash(newConfigMap(map[string]interface{}{"a": "1", "b": "3"}))).NotTo(Equal(hash))
+	})
+
+	It("should only skip resources applied from the same manifest", func() {
+		obj := newConfigMap(map[string]interface{}{"a": "1"})
+		hash, err := manifestHash(obj)
+		Expect(err).NotTo(HaveOccurred())
+		obj.SetAnnotations(map[string]string{utils.AnnotationManifestHash: hash})
+
+		By("applying resources without a hash")
+		existing := newConfigMap(map[string]interface{}{"a": "1"})
+		Expect(unchanged(existing, obj, hash)).To(BeFalse())
+
+		existing.SetAnnotations(map[string]string{utils.AnnotationManifestHash: hash})
+		Expect(unchanged(existing, obj, hash)).To(BeTrue())
+
+		By("skipping a resource whose status, resource version or unmanaged fields changed")
+		existing.SetResourceVersion("2")
+		Expect(unstructured.SetNestedField(existing.Object, "updated", "status", "phase")).To(Succeed())
+		Expect(unstructured.SetNestedField(existing.Object, "defaulted", "data", "b")).To(Succeed())
+		Expect(unchanged(existing, obj, hash)).To(BeTrue())
+
+		By("applying a changed manifest")
+		Expect(unchanged(existing, obj, "other")).To(BeFalse())
+	})
+
+	It("should apply a resource whose managed fields were edited", func() {
+		obj := newConfigMap(map[string]interface{}{"a": "1"})
+		obj.SetLabels(map[string]string{"backplaneconfig.name": "multiclusterengine"})
+		hash, err := manifestHash(obj)
+		Expect(err).NotTo(HaveOccurred())
+		obj.SetAnnotations(map[string]string{utils.AnnotationManifestHash: hash})
+
+		By("restoring an edited field")
+		existing := obj.DeepCopy()
+		Expect(unstructured.SetNestedField(existing.Object, "edited", "data", "a")).To(Succeed())
+		Expect(unchanged(existing, obj, hash)).To(BeFalse())
+
+		By("restoring a removed field")
+		existing = obj.DeepCopy()
+		unstructured.RemoveNestedField(existing.Object, "data", "a")
+		Expect(unchanged(existing, obj, hash)).To(BeFalse())
+
+		By("restoring an edited label")
+		existing = obj.DeepCopy()
+		existing.SetLabels(map[string]string{"backplaneconfig.name": "other"})
+		Expect(unchanged(existing, obj, hash)).To(BeFalse())
+	})
+
+	It("should compare list items in order", func() {
+		desired := []interface{}{map[string]interface{}{"name": "a"}}
+		Expect(containsFields([]interface{}{map[string]interface{}{"name": "a", "defaulted": true}}, desired)).To(BeTrue())
+		Expect(containsFields([]interface{}{map[string]interface{}{"name": "b"}}, desired)).To(BeFalse())
+		Expect(containsFields([]interface{}{}, desired)).To(BeFalse())
+	})
+})
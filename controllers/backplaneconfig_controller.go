@@ -24,33 +24,47 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/audit"
 	"github.com/stolostron/backplane-operator/pkg/foundation"
 	"github.com/stolostron/backplane-operator/pkg/hive"
 	"github.com/stolostron/backplane-operator/pkg/images"
+	"github.com/stolostron/backplane-operator/pkg/overlay"
 	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
 	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/toggle"
 	"github.com/stolostron/backplane-operator/pkg/utils"
+	"github.com/stolostron/backplane-operator/pkg/version"
 
 	clustermanager "open-cluster-management.io/api/operator/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
 	hiveconfig "github.com/openshift/hive/apis/hive/v1"
 
+	admissionregistration "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -58,23 +72,102 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
 
 	semver "github.com/Masterminds/semver"
 	pkgerrors "github.com/pkg/errors"
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // MultiClusterEngineReconciler reconciles a MultiClusterEngine object
 type MultiClusterEngineReconciler struct {
 	client.Client
+	// APIReader reads directly from the API server, for resources the cache does not hold. Defaults to Client.
+	APIReader     client.Reader
 	Scheme        *runtime.Scheme
-	Images        map[string]string
 	StatusManager *status.StatusTracker
+	// LabelFilteredCache is set when the cache only holds the Deployments and ConfigMaps carrying the
+	// backplaneconfig.name label. The others are then read through APIReader.
+	LabelFilteredCache bool
+	// MaxRequeueBackoff caps the exponential backoff applied when a reconcile returns an error
+	MaxRequeueBackoff time.Duration
+	// MaxConcurrentReconciles is the number of MultiClusterEngines reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+	// ApplyWorkers is the number of components that don't depend on each other applied in parallel. Defaults
+	// to 1.
+	ApplyWorkers int
+	// AuditSink optionally receives condition transitions for forwarding to an external system
+	AuditSink *audit.Sink
+	// Recorder optionally records Events on the MultiClusterEngine
+	Recorder record.EventRecorder
+
+	// DefaultPriorityClassName is applied to component workloads when the MultiClusterEngine does not set one
+	DefaultPriorityClassName string
+
+	// LogLevel is the operator's log level, which can be changed at runtime through the log level ConfigMap
+	LogLevel *zap.AtomicLevel
+	// DefaultLogLevel is the log level used when the log level ConfigMap is not present
+	DefaultLogLevel zapcore.Level
+
+	// WebhookDisabled is set when the operator runs without its validating webhook, in which case
+	// the reconciler enforces the checks the webhook would otherwise make
+	WebhookDisabled bool
+
+	// ImageManifest optionally provides the operand images in place of the OPERAND_IMAGE_ environment variables.
+	// The MultiClusterEngines are reconciled again when it changes.
+	ImageManifest *images.ManifestFile
+
+	// imageArchitectures are the architectures each image is built for, keyed by image reference, when
+	// architecture affinity is enabled. Component pods are kept to nodes of these architectures.
+	imageArchitectures map[string][]string
+
+	// render holds the inputs for rendering the components that are looked up while reconciling the
+	// MultiClusterEngine, such as its operand images, trusted CA bundle and the FIPS and proxy settings
+	render renderer.Options
+
+	// Overlay optionally patches rendered manifests before they are applied
+	Overlay *overlay.Overlay
+	// manifestPatches are the patches from the MultiClusterEngine's manifest patches ConfigMap. They are
+	// applied after Overlay.
+	manifestPatches *overlay.Overlay
+
+	// FIPSMode enables FIPS crypto in component pods even if the cluster install config does not enable it
+	FIPSMode bool
+
+	// ValidatingWebhook is the validating webhook configuration the operator keeps in place. It is nil when
+	// the webhook is disabled.
+	ValidatingWebhook *admissionregistration.ValidatingWebhookConfiguration
+
+	// hosted is set on the reconciler that installs the components on a hosted cluster
+	hosted bool
+
+	// configMapWatches watches the ConfigMaps the operator is configured with when the cache is label filtered
+	configMapWatches *configMapWatches
 }
 
 const (
 	requeuePeriod      = 15 * time.Second
 	backplaneFinalizer = "finalizer.multicluster.openshift.io"
+
+	// resyncPeriod is how often a healthy MultiClusterEngine is reconciled
+	resyncPeriod = 10 * time.Minute
+	// baseRequeueBackoff is the delay before the first retry of a failed reconcile
+	baseRequeueBackoff = 1 * time.Second
+	// baseComponentBackoff is the delay before a component that failed to apply is applied again. It doubles with
+	// every consecutive failure up to MaxRequeueBackoff.
+	baseComponentBackoff = 5 * time.Second
+	// DefaultMaxRequeueBackoff is the default upper bound on the delay between failed reconciles
+	DefaultMaxRequeueBackoff = 5 * time.Minute
+	// DefaultApplyWorkers is the default number of components applied in parallel
+	DefaultApplyWorkers = 4
+	// DefaultPriorityClassName is the priority class given to component workloads unless disabled
+	DefaultPriorityClassName = "system-cluster-critical"
+	// maxRemainingResources caps the number of resources listed in status while uninstalling
+	maxRemainingResources = 100
+	// consolePluginCRDName is the CRD the OpenShift console serves plugins from
+	consolePluginCRDName = "consoleplugins.console.openshift.io"
 )
 
 //+kubebuilder:rbac:groups=multicluster.openshift.io,resources=multiclusterengines,verbs=get;list;watch;create;update;patch;delete
@@ -85,7 +178,8 @@ const (
 //+kubebuilder:rbac:groups="discovery.open-cluster-management.io",resources=discoveryconfigs,verbs=get
 //+kubebuilder:rbac:groups="discovery.open-cluster-management.io",resources=discoveryconfigs,verbs=list
 //+kubebuilder:rbac:groups="discovery.open-cluster-management.io",resources=discoveryconfigs;discoveredclusters,verbs=create;get;list;watch;update;delete;deletecollection;patch;approve;escalate;bind
-//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions;clusteroperators;proxies;imagedigestmirrorsets,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=imagecontentsourcepolicies,verbs=get;list;watch
 //+kubebuilder:rbac:groups=console.openshift.io,resources=consoleplugins;consolequickstarts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=operator.openshift.io,resources=consoles,verbs=get;list;watch;update;patch
 
@@ -94,7 +188,7 @@ const (
 
 // ClusterManager RBAC
 //+kubebuilder:rbac:groups="",resources=configmaps;configmaps/status;namespaces;serviceaccounts;services;secrets,verbs=create;get;list;update;watch;patch;delete
-//+kubebuilder:rbac:groups="",resources=nodes;endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes;endpoints;pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 //+kubebuilder:rbac:groups="";events.k8s.io,resources=events,verbs=create;update;patch
 //+kubebuilder:rbac:groups=apps,resources=deployments;replicasets,verbs=create;get;list;update;watch;patch;delete
@@ -127,11 +221,16 @@ const (
 //+kubebuilder:rbac:groups="action.open-cluster-management.io",resources=managedclusteractions,verbs=get;create;update;delete
 //+kubebuilder:rbac:groups="cluster.open-cluster-management.io",resources=clustercurators;clustercurators/status,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups="operators.coreos.com",resources=subscriptions,verbs=get;list;watch
+//+kubebuilder:rbac:groups="scheduling.k8s.io",resources=priorityclasses,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (retRes ctrl.Result, retErr error) {
+func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = withReconcileID(ctx)
 	log := log.FromContext(ctx)
+
+	r.updateLogLevel(ctx)
+
 	// Fetch the BackplaneConfig instance
 	backplaneConfig, err := r.getBackplaneConfig(ctx, req)
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -144,36 +243,113 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
-	// reset status manager if req is a new MCE
 	uid := string(backplaneConfig.UID)
 	if uid == "" {
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.New("Resource missing UID")
 	}
-	if r.StatusManager.UID == "" || r.StatusManager.UID != string(backplaneConfig.UID) {
-		log.Info("Setting status manager to track new MCE", "UID", string(backplaneConfig.UID))
-		r.StatusManager.Reset(string(backplaneConfig.UID))
+
+	result, err := r.forInstance(uid).reconcileInstance(ctx, backplaneConfig)
+	if backplaneConfig.GetDeletionTimestamp() != nil && !controllerutil.ContainsFinalizer(backplaneConfig, backplaneFinalizer) {
+		r.StatusManager.RemoveInstance(uid)
 	}
+	return result, err
+}
+
+// forInstance returns a copy of r for reconciling the MultiClusterEngine with the given UID. It has the status
+// tracker of that MultiClusterEngine and none of the render inputs looked up for another, so MultiClusterEngines
+// don't see each other's state whether they are reconciled in turn or in parallel.
+func (r *MultiClusterEngineReconciler) forInstance(uid string) *MultiClusterEngineReconciler {
+	instance := *r
+	instance.StatusManager = r.StatusManager.Instance(uid)
+	instance.render = renderer.Options{}
+	instance.manifestPatches = nil
+	instance.imageArchitectures = nil
+	return &instance
+}
+
+// reconcileInstance installs, upgrades or uninstalls the components of the MultiClusterEngine and reports its
+// status
+func (r *MultiClusterEngineReconciler) reconcileInstance(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (retRes ctrl.Result, retErr error) {
+	log := log.FromContext(ctx)
 
 	defer func() {
 		log.Info("Updating status")
+		previousConditions := backplaneConfig.Status.Conditions
 		backplaneConfig.Status = r.StatusManager.ReportStatus(*backplaneConfig)
+		if blocking, err := backplanev1.FindBlockingResources(ctx, r.unlabeledReader()); err != nil {
+			log.Error(err, "Failed to find resources blocking deletion")
+		} else {
+			backplaneConfig.Status.BlockingResources = blocking
+		}
+		if retErr == nil {
+			now := metav1.Now()
+			backplaneConfig.Status.LastReconcileTime = &now
+			// The spec is only fully applied once every component has rolled out
+			if backplaneConfig.Status.Phase == backplanev1.MultiClusterEnginePhaseAvailable {
+				backplaneConfig.Status.ObservedGeneration = backplaneConfig.Generation
+			}
+		}
 		err := r.Client.Status().Update(ctx, backplaneConfig)
-		if backplaneConfig.Status.Phase != backplanev1.MultiClusterEnginePhaseAvailable && !utils.IsPaused(backplaneConfig) {
-			retRes = ctrl.Result{RequeueAfter: 10 * time.Second}
+		if err == nil && r.AuditSink != nil {
+			for _, e := range audit.ConditionTransitions(backplaneConfig.Name, previousConditions, backplaneConfig.Status.Conditions) {
+				r.AuditSink.Record(e)
+			}
 		}
+		if err == nil {
+			r.recordTransitionEvents(backplaneConfig, previousConditions)
+		}
+		retRes = requeueAfterStatus(backplaneConfig, retRes)
 		if err != nil {
 			retErr = err
 		}
+		r.StatusManager.RecordReconcile(*backplaneConfig, retErr)
+		recordReconcile(retErr)
 	}()
 
+	// Without the webhook nothing prevents a second MultiClusterEngine from installing on the same cluster, so
+	// only the oldest one is installed. Deleting a duplicate must not finalize the components it shares with the
+	// installed one. A MultiClusterHub is not refused here: as with the webhook, the MultiClusterEngine coexists
+	// with it and leaves it the components it runs, which keeps the two from managing the same components.
+	if r.WebhookDisabled {
+		other, err := r.olderBackplaneConfig(ctx, backplaneConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if other != "" {
+			if backplaneConfig.GetDeletionTimestamp() != nil {
+				if controllerutil.ContainsFinalizer(backplaneConfig, backplaneFinalizer) {
+					controllerutil.RemoveFinalizer(backplaneConfig, backplaneFinalizer)
+					return ctrl.Result{}, r.Client.Update(ctx, backplaneConfig)
+				}
+				return ctrl.Result{}, nil
+			}
+			log.Info("Another MultiClusterEngine already exists. Not reconciling.", "existing", other)
+			r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.DuplicateInstanceReason,
+				fmt.Sprintf("Only one MultiClusterEngine may install on a cluster. MultiClusterEngine %s is already installed.", other)))
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// The webhook guards deletion, so repair it before anything else
+	if err := r.ensureValidatingWebhook(ctx); err != nil {
+		log.Error(err, "Failed to ensure the validating webhook configuration")
+		return ctrl.Result{}, err
+	}
+
 	// If deletion detected, finalize backplane config
 	if backplaneConfig.GetDeletionTimestamp() != nil {
 		if controllerutil.ContainsFinalizer(backplaneConfig, backplaneFinalizer) {
-			err := r.finalizeBackplaneConfig(ctx, backplaneConfig) // returns all errors
+			installer, err := r.componentReconciler(ctx, backplaneConfig)
 			if err != nil {
 				log.Info(err.Error())
 				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 			}
+			err = installer.finalizeBackplaneConfig(ctx, backplaneConfig) // returns all errors
+			if err != nil {
+				log.Info(err.Error())
+				r.recordEvent(backplaneConfig, corev1.EventTypeWarning, UninstallBlockedEvent, err.Error())
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
 
 			log.Info("all subcomponents have been finalized successfully - removing finalizer")
 			controllerutil.RemoveFinalizer(backplaneConfig, backplaneFinalizer)
@@ -193,6 +369,35 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
+	// Refuse to reconcile an install made by a newer operator, since older manifests may corrupt it
+	if running := version.Get().GitVersion; version.IsDowngrade(backplaneConfig.Status.CurrentVersion, running) && !utils.IsDowngradeForced(backplaneConfig) {
+		log.Info("Operator is older than the installed version. Not reconciling.", "installed", backplaneConfig.Status.CurrentVersion, "running", running)
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.DowngradeUnsupportedReason,
+			fmt.Sprintf("Downgrade from %s to %s is not supported. Add the annotation %s=true to force it.", backplaneConfig.Status.CurrentVersion, running, utils.AnnotationForceDowngrade)))
+		return ctrl.Result{}, nil
+	}
+
+	// Don't install or upgrade on a cluster that can't run the components. Once the running version is installed,
+	// an unmet requirement is only reported, so that a cluster operator that is briefly unavailable doesn't stop
+	// the components from being reconciled.
+	unmet, err := r.checkPrerequisites(ctx, backplaneConfig)
+	if errors.Is(err, errClusterVersionUnknown) {
+		log.Info("OpenShift version is not yet known. Requeuing.")
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+	if unmet != "" {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEnginePrerequisiteFailed, metav1.ConditionTrue, status.PrerequisitesNotMetReason, unmet))
+		if backplaneConfig.Status.CurrentVersion != version.Get().GitVersion {
+			log.Info("Cluster prerequisites are not met. Not installing.", "requirement", unmet, "installed", backplaneConfig.Status.CurrentVersion)
+			return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+		}
+		log.Info("Cluster prerequisites are not met", "requirement", unmet)
+	} else {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEnginePrerequisiteFailed, metav1.ConditionFalse, status.PrerequisitesMetReason, ""))
+	}
+
 	var result ctrl.Result
 
 	result, err = r.setDefaults(ctx, backplaneConfig)
@@ -203,16 +408,77 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	result, err = r.validateNamespace(ctx, backplaneConfig)
-	if result != (ctrl.Result{}) {
-		return ctrl.Result{}, err
+	// In the Hosted deployment mode the components are installed on the hosted cluster
+	installer, err := r.componentReconciler(ctx, backplaneConfig)
+	if err != nil {
+		log.Error(err, "Failed to connect to the hosted cluster")
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, err.Error()))
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
 	}
+
+	// A dry run does not create the target namespace
+	if !backplaneConfig.Spec.DryRun {
+		result, err = installer.validateNamespace(ctx, backplaneConfig)
+		if result != (ctrl.Result{}) {
+			return ctrl.Result{}, err
+		}
+		if err != nil {
+			return ctrl.Result{Requeue: true}, err
+		}
+	}
+
+	// The configuration ConfigMaps of a hosted cluster are not on the cluster the operator watches
+	if !installer.hosted {
+		if err := r.watchConfigMaps(backplaneConfig.Spec.TargetNamespace); err != nil {
+			return ctrl.Result{Requeue: true}, err
+		}
+	}
+
+	result, err = installer.ensureTrustedCABundle(ctx, backplaneConfig)
+	if result != (ctrl.Result{}) || err != nil {
+		return result, err
+	}
+
+	result, err = installer.ensureManifestPatches(ctx, backplaneConfig)
+	if result != (ctrl.Result{}) || err != nil {
+		return result, err
+	}
+
+	// Installing without FIPS crypto on a FIPS cluster is not undone by a later reconcile, so wait for the setting
+	backplaneConfig.Status.FIPSEnabled, err = r.fipsMode(ctx)
 	if err != nil {
-		return ctrl.Result{Requeue: true}, err
+		log.Error(err, "Failed to read FIPS mode from the cluster install config")
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason,
+			fmt.Sprintf("Failed to read FIPS mode from the cluster install config: %s", err.Error())))
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+	installer.render.FIPS = backplaneConfig.Status.FIPSEnabled
+
+	// A hosted cluster is not shared with a MultiClusterHub on the cluster the operator runs on
+	backplaneConfig.Status.MultiClusterHub = ""
+	if !installer.hosted {
+		backplaneConfig.Status.MultiClusterHub, err = r.findMultiClusterHub(ctx, backplaneConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	installer.render.ClusterProxy, err = r.clusterProxy(ctx)
+	if err != nil {
+		log.Error(err, "Failed to read the cluster proxy configuration")
+		return ctrl.Result{}, err
+	}
+
+	// A hosted cluster has no access to the operator's namespace, so its pull secret is not copied
+	if !installer.hosted && !backplaneConfig.Spec.DryRun {
+		if err := r.ensurePullSecret(ctx, backplaneConfig); err != nil {
+			log.Error(err, "Failed to copy the image pull secret to the target namespace")
+			return ctrl.Result{}, err
+		}
 	}
 
-	// Read images from environmental variables
-	imgs, err := images.GetImagesWithOverrides(r.Client, backplaneConfig)
+	// Read images from the image manifest file or environmental variables
+	imgs, err := r.operandImages(backplaneConfig)
+	r.StatusManager.AddCondition(status.NewConfigReloadedCondition(err))
 	if err != nil {
 		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, fmt.Sprintf("Issue building image references: %s", err.Error())))
 		return ctrl.Result{}, err
@@ -222,7 +488,17 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, "No image references defined in deployment"))
 		return ctrl.Result{RequeueAfter: requeuePeriod}, errors.New("no image references exist. images must be defined as environment variables")
 	}
-	r.Images = imgs
+	installer.render.Images = imgs
+	installer.render.DefaultPriorityClassName = r.DefaultPriorityClassName
+	backplaneConfig.Status.Images = nil
+	if o := backplaneConfig.Spec.Overrides; o != nil && (o.ImageRegistry != "" || o.ResolveImageDigests) {
+		backplaneConfig.Status.Images = imgs
+	}
+	installer.imageArchitectures, err = images.GetImageArchitectures(r.Client, backplaneConfig, imgs)
+	if err != nil {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, fmt.Sprintf("Issue reading image architectures: %s", err.Error())))
+		return ctrl.Result{}, err
+	}
 
 	// Do not reconcile objects if this instance of mce is labeled "paused"
 	if utils.IsPaused(backplaneConfig) {
@@ -231,22 +507,59 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
-	result, err = r.adoptExistingSubcomponents(ctx, backplaneConfig)
+	if backplaneConfig.Spec.DryRun {
+		return r.dryRun(ctx, backplaneConfig, installer)
+	}
+
+	if utils.IsRestored(backplaneConfig) {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineRestored, metav1.ConditionTrue, status.AdoptingExistingResourcesReason,
+			fmt.Sprintf("Restored by %s. Existing resources are adopted instead of recreated.", backplaneConfig.GetLabels()[utils.VeleroRestoreLabel])))
+	}
+
+	result, err = installer.adoptExistingSubcomponents(ctx, backplaneConfig)
 	if err != nil {
 		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionUnknown, status.DeployFailedReason, err.Error()))
 		return result, err
 	}
 
-	result, err = r.DeployAlwaysSubcomponents(ctx, backplaneConfig)
-	if err != nil {
-		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionUnknown, status.DeployFailedReason, err.Error()))
+	result, err = installer.ensureOperandCRDs(ctx, backplaneConfig)
+	if err != nil || (result != ctrl.Result{}) {
 		return result, err
 	}
 
-	result, err = r.ensureToggleableComponents(ctx, backplaneConfig)
+	result, err = installer.DeployAlwaysSubcomponents(ctx, backplaneConfig)
 	if err != nil {
+		// Ownership and apply conflicts have already been reported with their own condition
+		var conflict *ownershipConflictError
+		var applyConflict *applyConflictError
+		if !errors.As(err, &conflict) && !errors.As(err, &applyConflict) {
+			r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionUnknown, status.DeployFailedReason, err.Error()))
+		}
+		r.recordEvent(backplaneConfig, corev1.EventTypeWarning, ApplyFailedEvent, err.Error())
 		return result, err
 	}
+	if result != (ctrl.Result{}) && isUpgrading(backplaneConfig) {
+		return result, nil
+	}
+
+	componentsResult, err := installer.ensureToggleableComponents(ctx, backplaneConfig)
+	if err != nil {
+		r.recordEvent(backplaneConfig, corev1.EventTypeWarning, ApplyFailedEvent, err.Error())
+		return componentsResult, err
+	}
+
+	// In hosted mode the components are not installed on the cluster the operator runs on, so it is not imported
+	if !installer.hosted {
+		result, err = r.ensureLocalCluster(ctx, backplaneConfig)
+		if err != nil || result != (ctrl.Result{}) {
+			return result, err
+		}
+	}
+
+	// Components waiting on a dependency or backing off after a failed attempt are applied on the next reconcile
+	if componentsResult != (ctrl.Result{}) {
+		return componentsResult, nil
+	}
 
 	r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.DeploySuccessReason, "All components deployed"))
 
@@ -255,12 +568,12 @@ func (r *MultiClusterEngineReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MultiClusterEngineReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&backplanev1.MultiClusterEngine{}).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&backplanev1.MultiClusterEngine{}, builder.WithPredicates(specChangedPredicate)).
+		WithOptions(controller.Options{RateLimiter: r.requeueRateLimiter(), MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Watches(&source.Kind{Type: &appsv1.Deployment{}}, &handler.EnqueueRequestForOwner{
 			OwnerType: &backplanev1.MultiClusterEngine{},
-		}).
+		}, builder.WithPredicates(specChangedPredicate)).
 		Watches(&source.Kind{Type: &hiveconfig.HiveConfig{}}, &handler.Funcs{
 			DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
 				labels := e.Object.GetLabels()
@@ -292,8 +605,169 @@ func (r *MultiClusterEngineReconciler) SetupWithManager(mgr ctrl.Manager) error
 					}})
 				}
 			},
-		}, builder.WithPredicates(predicate.LabelChangedPredicate{})).
-		Complete(r)
+		}, builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	// Edits and deletions of the other resources the operator applies are reverted right away rather than on the
+	// next resync
+	for _, owned := range ownedTypes {
+		b = b.Watches(&source.Kind{Type: owned}, &handler.EnqueueRequestForOwner{
+			OwnerType: &backplanev1.MultiClusterEngine{},
+		}, builder.WithPredicates(ownedChangedPredicate))
+	}
+	b = b.Watches(&source.Kind{Type: &apixv1.CustomResourceDefinition{}}, &handler.EnqueueRequestForOwner{
+		OwnerType: &backplanev1.MultiClusterEngine{},
+	}, builder.WithPredicates(specChangedPredicate))
+	if r.ImageManifest != nil {
+		manifestChanged := make(chan event.GenericEvent, 10)
+		r.ImageManifest.OnChange = func() { r.enqueueAll(manifestChanged) }
+		b = b.Watches(&source.Channel{Source: manifestChanged}, &handler.EnqueueRequestForObject{})
+	}
+	if r.ValidatingWebhook != nil {
+		b = b.Watches(&source.Kind{Type: &admissionregistration.ValidatingWebhookConfiguration{}},
+			handler.EnqueueRequestsFromMapFunc(r.validatingWebhookRequests))
+	}
+	// The Proxy resource only exists on OpenShift
+	if _, err := mgr.GetRESTMapper().RESTMapping(schema.GroupKind{Group: configv1.GroupName, Kind: "Proxy"}, configv1.GroupVersion.Version); err == nil {
+		b = b.Watches(&source.Kind{Type: &configv1.Proxy{}}, handler.EnqueueRequestsFromMapFunc(r.clusterProxyRequests))
+	}
+	// A label filtered cache doesn't hold the ConfigMaps the operator is configured with, so those of the operator's
+	// namespace and of the target namespaces are watched separately
+	if !r.LabelFilteredCache {
+		b = b.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.configMapRequests))
+		return b.Complete(r)
+	}
+	c, err := b.Build(r)
+	if err != nil {
+		return err
+	}
+	r.configMapWatches = &configMapWatches{
+		mgr:        mgr,
+		controller: c,
+		handler:    handler.EnqueueRequestsFromMapFunc(r.configMapRequests),
+		namespaces: map[string]bool{},
+	}
+	return r.watchConfigMaps(utils.OperatorNamespace())
+}
+
+// operandImages returns the images of the components, read from the image manifest file if there is one and from
+// the environment otherwise, with the overrides of the backplaneConfig applied
+func (r *MultiClusterEngineReconciler) operandImages(backplaneConfig *backplanev1.MultiClusterEngine) (map[string]string, error) {
+	if r.ImageManifest == nil {
+		return images.GetImagesWithOverrides(r.unlabeledReader(), backplaneConfig)
+	}
+	imgs, err := r.ImageManifest.Images()
+	if err != nil {
+		return nil, err
+	}
+	return images.OverrideImages(r.unlabeledReader(), backplaneConfig, imgs)
+}
+
+// enqueueAll sends an event for every MultiClusterEngine to the channel. Events that don't fit are dropped, as
+// they only arrive before this replica is leader, and the controller reconciles everything when it starts.
+func (r *MultiClusterEngineReconciler) enqueueAll(events chan<- event.GenericEvent) {
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(context.TODO(), mceList); err != nil {
+		log.Log.WithName("image-manifest").Error(err, "Failed to list MultiClusterEngines to update their images")
+		return
+	}
+	for i := range mceList.Items {
+		select {
+		case events <- event.GenericEvent{Object: &mceList.Items[i]}:
+		default:
+		}
+	}
+}
+
+// ownedTypes are the kinds of operand resources, besides deployments and CRDs, whose changes are reconciled
+var ownedTypes = []client.Object{
+	&corev1.Service{},
+	&corev1.ServiceAccount{},
+	&rbacv1.ClusterRole{},
+	&rbacv1.ClusterRoleBinding{},
+	&rbacv1.Role{},
+	&rbacv1.RoleBinding{},
+}
+
+// specChangedPredicate filters out events that only touch an object's status
+var specChangedPredicate = predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
+// ownedChangedPredicate filters out events that only touch the status or the metadata the API server maintains of
+// an owned resource. Most of the ownedTypes don't track a generation, so their contents are compared instead.
+var ownedChangedPredicate = predicate.Or(specChangedPredicate, predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectNew.GetGeneration() != 0 {
+			return false
+		}
+		return !equality.Semantic.DeepEqual(contents(e.ObjectOld), contents(e.ObjectNew))
+	},
+})
+
+// contents returns the fields of obj other than its metadata and status, such as the rules of a role or the
+// subjects of a binding. An object that can't be converted has no contents.
+func contents(obj client.Object) map[string]interface{} {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	delete(u, "metadata")
+	delete(u, "status")
+	return u
+}
+
+// configMapRequests returns a request for every MultiClusterEngine that mounts the given ConfigMap as its
+// trusted CA bundle or reads its manifest patches from it, or for all of them if it is the log level ConfigMap
+func (r *MultiClusterEngineReconciler) configMapRequests(obj client.Object) []reconcile.Request {
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(context.TODO(), mceList); err != nil {
+		return nil
+	}
+	// A change to the blocking resources may unblock the deletion of a MultiClusterEngine
+	operatorConfigChanged := obj.GetNamespace() == os.Getenv("POD_NAMESPACE") &&
+		(obj.GetName() == utils.LogLevelConfigMap || obj.GetName() == backplanev1.BlockingResourcesConfigMap)
+	requests := []reconcile.Request{}
+	for _, mce := range mceList.Items {
+		inTargetNamespace := mce.Spec.TargetNamespace == obj.GetNamespace()
+		manifestPatches := mce.Spec.Overrides != nil && mce.Spec.Overrides.ManifestPatchesConfigMap == obj.GetName()
+		if operatorConfigChanged || (inTargetNamespace && (trustedCABundleName(&mce) == obj.GetName() || manifestPatches)) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: mce.Name}})
+		}
+	}
+	return requests
+}
+
+// requeueAfterStatus returns when to reconcile the MultiClusterEngine again once its status is updated. A requeue
+// asked for by the reconcile, such as the backoff of a component that failed to apply, is kept up to the resync
+// period. Otherwise a MultiClusterEngine that isn't available is checked again after 10 seconds.
+func requeueAfterStatus(mce *backplanev1.MultiClusterEngine, result ctrl.Result) ctrl.Result {
+	if utils.IsPaused(mce) {
+		return result
+	}
+	if result == (ctrl.Result{}) {
+		if mce.Status.Phase != backplanev1.MultiClusterEnginePhaseAvailable {
+			return ctrl.Result{RequeueAfter: 10 * time.Second}
+		}
+		return ctrl.Result{RequeueAfter: resyncPeriod}
+	}
+	if result.RequeueAfter > resyncPeriod {
+		result.RequeueAfter = resyncPeriod
+	}
+	return result
+}
+
+// requeueRateLimiter backs off exponentially on failed reconciles up to MaxRequeueBackoff. The
+// backoff for a request is reset as soon as it reconciles without error.
+func (r *MultiClusterEngineReconciler) requeueRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(baseRequeueBackoff, r.maxRequeueBackoff())
+}
+
+// maxRequeueBackoff returns MaxRequeueBackoff, or its default if it is not set
+func (r *MultiClusterEngineReconciler) maxRequeueBackoff() time.Duration {
+	if r.MaxRequeueBackoff < baseRequeueBackoff {
+		return DefaultMaxRequeueBackoff
+	}
+	return r.MaxRequeueBackoff
 }
 
 // DeployAlwaysSubcomponents ensures all subcomponents exist
@@ -302,7 +776,7 @@ func (r *MultiClusterEngineReconciler) DeployAlwaysSubcomponents(ctx context.Con
 
 	chartsDir := renderer.AlwaysChartsDir
 	// Renders all templates from charts
-	templates, errs := renderer.RenderCharts(chartsDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderCharts(chartsDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -311,11 +785,12 @@ func (r *MultiClusterEngineReconciler) DeployAlwaysSubcomponents(ctx context.Con
 	}
 
 	// Applies all templates
+	deployments := []types.NamespacedName{}
 	for _, template := range templates {
 		if template.GetKind() == "Deployment" {
-			r.StatusManager.AddComponent(status.DeploymentStatus{
-				NamespacedName: types.NamespacedName{Name: template.GetName(), Namespace: template.GetNamespace()},
-			})
+			nn := types.NamespacedName{Name: template.GetName(), Namespace: template.GetNamespace()}
+			deployments = append(deployments, nn)
+			r.StatusManager.AddComponent(status.DeploymentStatus{NamespacedName: nn})
 		}
 		result, err := r.applyTemplate(ctx, backplaneConfig, template)
 		if err != nil {
@@ -328,175 +803,191 @@ func (r *MultiClusterEngineReconciler) DeployAlwaysSubcomponents(ctx context.Con
 		return result, err
 	}
 
+	// The toggleable components run on the components that are always installed, so they are upgraded once
+	// these are
+	if isUpgrading(backplaneConfig) {
+		for _, nn := range deployments {
+			if !(status.DeploymentStatus{NamespacedName: nn}).Status(r.Client).Available {
+				r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineComponentsUpgrading, metav1.ConditionTrue, status.UpgradingComponentsReason,
+					fmt.Sprintf("Waiting for %s to become available before upgrading the toggleable components", nn.Name)))
+				return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+			}
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func (r *MultiClusterEngineReconciler) ensureToggleableComponents(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
-	errs := map[string]error{}
-	requeue := false
+// toggleableComponent holds the functions that install and remove a component along with the
+// deployment used to tell whether it is available
+type toggleableComponent struct {
+	ensure     func(context.Context, *backplanev1.MultiClusterEngine) (ctrl.Result, error)
+	ensureNo   func(context.Context, *backplanev1.MultiClusterEngine) (ctrl.Result, error)
+	deployment types.NamespacedName
+}
 
-	if backplaneConfig.Enabled(backplanev1.ManagedServiceAccount) {
-		result, err := r.ensureManagedServiceAccount(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ManagedServiceAccount] = err
-		}
-	} else {
-		result, err := r.ensureNoManagedServiceAccount(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ManagedServiceAccount] = err
-		}
+func (r *MultiClusterEngineReconciler) toggleableComponents(backplaneConfig *backplanev1.MultiClusterEngine) map[string]toggleableComponent {
+	ns := backplaneConfig.Spec.TargetNamespace
+	infraNS := ns
+	if backplaneConfig.Spec.Overrides != nil && backplaneConfig.Spec.Overrides.InfrastructureCustomNamespace != "" {
+		infraNS = backplaneConfig.Spec.Overrides.InfrastructureCustomNamespace
 	}
 
-	if backplaneConfig.Enabled(backplanev1.HyperShift) {
-		result, err := r.ensureHyperShift(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.HyperShift] = err
-		}
-	} else {
-		result, err := r.ensureNoHyperShift(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.HyperShift] = err
-		}
+	return map[string]toggleableComponent{
+		backplanev1.ManagedServiceAccount: {r.ensureManagedServiceAccount, r.ensureNoManagedServiceAccount, types.NamespacedName{Name: "managed-serviceaccount-addon-manager", Namespace: ns}},
+		backplanev1.HyperShift:            {r.ensureHyperShift, r.ensureNoHyperShift, types.NamespacedName{Name: "hypershift-addon-manager", Namespace: ns}},
+		backplanev1.ConsoleMCE:            {r.ensureConsoleMCE, r.ensureNoConsoleMCE, types.NamespacedName{Name: "console-mce-console", Namespace: ns}},
+		backplanev1.Discovery:             {r.ensureDiscovery, r.ensureNoDiscovery, types.NamespacedName{Name: "discovery-operator", Namespace: ns}},
+		backplanev1.Hive:                  {r.ensureHive, r.ensureNoHive, types.NamespacedName{Name: "hive-operator", Namespace: ns}},
+		backplanev1.AssistedService:       {r.ensureAssistedService, r.ensureNoAssistedService, types.NamespacedName{Name: "infrastructure-operator", Namespace: infraNS}},
+		backplanev1.ClusterLifecycle:      {r.ensureClusterLifecycle, r.ensureNoClusterLifecycle, types.NamespacedName{Name: "cluster-curator-controller", Namespace: ns}},
+		backplanev1.ClusterManager:        {r.ensureClusterManager, r.ensureNoClusterManager, types.NamespacedName{Name: "cluster-manager", Namespace: ns}},
+		backplanev1.ServerFoundation:      {r.ensureServerFoundation, r.ensureNoServerFoundation, types.NamespacedName{Name: "ocm-controller", Namespace: ns}},
+		backplanev1.ClusterBackup:         {r.ensureClusterBackup, r.ensureNoClusterBackup, types.NamespacedName{Name: "cluster-backup-controller", Namespace: ns}},
+		backplanev1.ClusterProxyAddon:     {r.ensureClusterProxyAddon, r.ensureNoClusterProxyAddon, types.NamespacedName{Name: "cluster-proxy-addon-manager", Namespace: ns}},
 	}
+}
 
-	if backplaneConfig.Enabled(backplanev1.ConsoleMCE) {
-		result, err := r.ensureConsoleMCE(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ConsoleMCE] = err
-		}
-	} else {
-		result, err := r.ensureNoConsoleMCE(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ConsoleMCE] = err
-		}
+// ensureToggleableComponents installs enabled components in dependency order and removes disabled ones. An
+// enabled component is not installed until the enabled components it depends on are available.
+func (r *MultiClusterEngineReconciler) ensureToggleableComponents(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	components := r.toggleableComponents(backplaneConfig)
+	result, err := r.ensureComponentsInOrder(ctx, backplaneConfig, components)
+	if err != nil || result != (ctrl.Result{}) {
+		return result, err
 	}
 
-	if backplaneConfig.Enabled(backplanev1.Discovery) {
-		result, err := r.ensureDiscovery(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.Discovery] = err
-		}
-	} else {
-		result, err := r.ensureNoDiscovery(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.Discovery] = err
+	// Every component is now installed or removed, so whatever else is labeled for a component is stale
+	enabled := []string{}
+	for name := range components {
+		if backplaneConfig.Enabled(name) {
+			enabled = append(enabled, name)
 		}
 	}
+	if err := r.removeStaleResources(ctx, backplaneConfig, enabled); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
 
-	if backplaneConfig.Enabled(backplanev1.Hive) {
-		result, err := r.ensureHive(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.Hive] = err
-		}
-	} else {
-		result, err := r.ensureNoHive(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.Hive] = err
-		}
+// removeStaleResources deletes the resources labeled for a component that is neither one of the enabled
+// components nor always installed. This removes what a disabled component's chart no longer renders, and the
+// resources of components dropped from the operator, which their ensureNo functions don't know about.
+func (r *MultiClusterEngineReconciler) removeStaleResources(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, enabled []string) error {
+	always, err := renderer.ChartComponents(renderer.AlwaysChartsDir)
+	if err != nil {
+		return err
+	}
+	owned, err := labels.NewRequirement("backplaneconfig.name", selection.Equals, []string{backplaneConfig.GetName()})
+	if err != nil {
+		return err
+	}
+	labeled, err := labels.NewRequirement(renderer.ComponentLabel, selection.Exists, nil)
+	if err != nil {
+		return err
 	}
+	stale, err := labels.NewRequirement(renderer.ComponentLabel, selection.NotIn, append(enabled, always...))
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*owned, *labeled, *stale)
 
-	if backplaneConfig.Enabled(backplanev1.AssistedService) {
-		result, err := r.ensureAssistedService(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.AssistedService] = err
-		}
-	} else {
-		result, err := r.ensureNoAssistedService(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
+	for _, gvk := range uninstallKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		err := r.Client.List(ctx, list, client.MatchingLabelsSelector{Selector: selector})
+		if err != nil && meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return err
 		}
-		if err != nil {
-			errs[backplanev1.AssistedService] = err
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			if item.GetDeletionTimestamp() != nil {
+				continue
+			}
+			log.FromContext(ctx).Info(fmt.Sprintf("Removing stale %s %s of component %s", item.GetKind(), item.GetName(), item.GetLabels()[renderer.ComponentLabel]))
+			if err := r.Client.Delete(ctx, item); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
 		}
 	}
+	return nil
+}
 
-	if backplaneConfig.Enabled(backplanev1.ClusterLifecycle) {
-		result, err := r.ensureClusterLifecycle(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ClusterLifecycle] = err
-		}
-	} else {
-		result, err := r.ensureNoClusterLifecycle(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ClusterLifecycle] = err
-		}
+// ensureComponentsInOrder ensures the components in dependency order. The components that don't depend on each
+// other are ensured in parallel by up to ApplyWorkers workers. During an upgrade the enabled components are
+// upgraded one at a time: those after a component that is not yet available keep their previous version until it
+// is.
+func (r *MultiClusterEngineReconciler) ensureComponentsInOrder(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, components map[string]toggleableComponent) (ctrl.Result, error) {
+	errs := map[string]error{}
+	requeue := false
+
+	names := []string{}
+	for name := range components {
+		names = append(names, name)
+	}
+	batches, err := toggle.InstallLevels(names, toggle.ComponentDependencies)
+	if err != nil {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, err.Error()))
+		return ctrl.Result{}, err
 	}
 
-	if backplaneConfig.Enabled(backplanev1.ClusterManager) {
-		result, err := r.ensureClusterManager(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ClusterManager] = err
-		}
-	} else {
-		result, err := r.ensureNoClusterManager(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ClusterManager] = err
+	upgrading := isUpgrading(backplaneConfig)
+	workers := r.ApplyWorkers
+	if upgrading {
+		// The upgrade of a component waits on the one before it, so they are ensured one at a time
+		order, _ := toggle.InstallOrder(names, toggle.ComponentDependencies)
+		batches = [][]string{}
+		for _, name := range order {
+			batches = append(batches, []string{name})
 		}
+		workers = 1
 	}
 
-	if backplaneConfig.Enabled(backplanev1.ServerFoundation) {
-		result, err := r.ensureServerFoundation(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ServerFoundation] = err
-		}
-	} else {
-		result, err := r.ensureNoServerFoundation(ctx, backplaneConfig)
-		if result != (ctrl.Result{}) {
-			requeue = true
-		}
-		if err != nil {
-			errs[backplanev1.ServerFoundation] = err
+	upgradeBlockedBy := ""
+	enabled, upgraded := 0, 0
+	var retryAfter time.Duration
+	available := map[string]bool{}
+	for _, batch := range batches {
+		results := r.ensureComponents(ctx, backplaneConfig, components, batch, available, upgradeBlockedBy != "", workers)
+		for i, name := range batch {
+			result := results[i]
+			if result.requeue {
+				requeue = true
+			}
+			if result.retryAfter > 0 && (retryAfter == 0 || result.retryAfter < retryAfter) {
+				retryAfter = result.retryAfter
+			}
+			if result.enabled {
+				enabled++
+			}
+			if result.err != nil {
+				errs[name] = result.err
+				continue
+			}
+			if !result.ensured {
+				continue
+			}
+			available[name] = result.available
+			if upgrading && !result.available && !result.leftToHub {
+				upgradeBlockedBy = name
+			} else {
+				upgraded++
+			}
 		}
 	}
 
+	if upgradeBlockedBy != "" {
+		log.FromContext(ctx).Info("Waiting on component to be upgraded before upgrading the next", "component", upgradeBlockedBy)
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineComponentsUpgrading, metav1.ConditionTrue, status.UpgradingComponentsReason,
+			fmt.Sprintf("Waiting for %s to become available before upgrading the next component. %d of %d components are upgraded.", upgradeBlockedBy, upgraded, enabled)))
+	} else if upgrading && len(errs) == 0 {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineComponentsUpgrading, metav1.ConditionFalse, status.ComponentsUpgradedReason,
+			fmt.Sprintf("All components are upgraded to %s", version.Get().GitVersion)))
+	}
+
 	if len(errs) > 0 {
 		errorMessages := []string{}
 		for k, v := range errs {
@@ -506,19 +997,173 @@ func (r *MultiClusterEngineReconciler) ensureToggleableComponents(ctx context.Co
 		log.FromContext(ctx).Error(errors.New("Errors applying components"), combinedError)
 		return ctrl.Result{RequeueAfter: requeuePeriod}, errors.New(combinedError)
 	}
-	if requeue {
-		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	if requeue && (retryAfter == 0 || requeuePeriod < retryAfter) {
+		retryAfter = requeuePeriod
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: retryAfter}, nil
+}
+
+// componentResult is the outcome of ensuring a single component
+type componentResult struct {
+	// enabled is set for an enabled component the cluster supports
+	enabled bool
+	// ensured is set once an enabled component is installed, or left to the MultiClusterHub
+	ensured   bool
+	available bool
+	leftToHub bool
+	requeue   bool
+	// retryAfter is how long a component that failed to apply waits before it is applied again
+	retryAfter time.Duration
+	err        error
+}
+
+// ensureComponents ensures the components of a batch, none of which depend on each other, with up to workers of
+// them in parallel. The results are in the order of the batch. available holds the components of earlier batches
+// that are available and is not changed.
+func (r *MultiClusterEngineReconciler) ensureComponents(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, components map[string]toggleableComponent,
+	batch []string, available map[string]bool, upgradeBlocked bool, workers int) []componentResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]componentResult, len(batch))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, name := range batch {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = r.ensureComponent(ctx, backplaneConfig, name, components[name], available, upgradeBlocked)
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+// ensureComponent installs an enabled component once its dependencies are available, or removes a disabled one
+func (r *MultiClusterEngineReconciler) ensureComponent(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, name string, component toggleableComponent,
+	available map[string]bool, upgradeBlocked bool) componentResult {
+	// A component the MultiClusterHub still runs is neither installed nor removed, so the two don't fight
+	// over it while the hub migrates to the MultiClusterEngine
+	if backplaneConfig.Status.MultiClusterHub != "" {
+		hubRuns, err := r.hubRunsComponent(ctx, component)
+		if err != nil {
+			return componentResult{err: err}
+		}
+		if hubRuns {
+			log.FromContext(ctx).Info("Leaving component to the MultiClusterHub", "component", name, "multiclusterhub", backplaneConfig.Status.MultiClusterHub)
+			if !backplaneConfig.Enabled(name) {
+				return componentResult{}
+			}
+			r.StatusManager.AddComponent(toggle.EnabledStatus(component.deployment))
+			return componentResult{
+				enabled:   true,
+				ensured:   true,
+				available: toggle.EnabledStatus(component.deployment).Status(r.Client).Available,
+				leftToHub: true,
+			}
+		}
+	}
+
+	supported := true
+	if backplaneConfig.Enabled(name) {
+		var err error
+		if supported, err = r.componentSupported(ctx, name); err != nil {
+			return componentResult{err: err}
+		}
+		if !supported {
+			log.FromContext(ctx).Info("Skipping component not supported by the cluster", "component", name)
+		}
+	}
+	if !backplaneConfig.Enabled(name) || !supported {
+		result, err := component.ensureNo(ctx, backplaneConfig)
+		return componentResult{requeue: result != (ctrl.Result{}), err: err}
+	}
+
+	if upgradeBlocked {
+		return componentResult{enabled: true, requeue: true}
+	}
+
+	if dependency := r.unavailableDependency(backplaneConfig, name, available); dependency != "" {
+		log.FromContext(ctx).Info("Waiting on dependency before installing component", "component", name, "dependency", dependency)
+		r.StatusManager.RemoveComponent(toggle.EnabledStatus(component.deployment))
+		r.StatusManager.AddComponent(toggle.WaitingStatus(component.deployment, dependency))
+		return componentResult{enabled: true, requeue: true}
+	}
+	r.StatusManager.RemoveComponent(toggle.WaitingStatus(component.deployment, ""))
+
+	// A component that keeps failing, for example on a webhook timeout, is retried with a growing delay
+	// instead of on every reconcile
+	reporter := toggle.EnabledStatus(component.deployment)
+	if wait := r.StatusManager.RetryAfter(reporter); wait > 0 {
+		log.FromContext(ctx).Info("Backing off before applying component again", "component", name, "retryAfter", wait.Round(time.Second))
+		return componentResult{enabled: true, retryAfter: wait}
+	}
+
+	result, err := component.ensure(ctx, backplaneConfig)
+	if err != nil {
+		r.StatusManager.AddComponent(reporter)
+		wait := r.StatusManager.ApplyFailed(reporter, err, baseComponentBackoff, r.maxRequeueBackoff())
+		return componentResult{enabled: true, requeue: result != (ctrl.Result{}), retryAfter: wait, err: err}
+	}
+	r.StatusManager.ApplySucceeded(reporter)
+	return componentResult{
+		enabled:   true,
+		ensured:   true,
+		available: toggle.EnabledStatus(component.deployment).Status(r.Client).Available,
+		requeue:   result != (ctrl.Result{}),
+	}
+}
+
+// componentSupported returns false for an enabled component that can't run on this cluster, such as the console
+// plugin on a cluster without the OpenShift console. Such a component is removed rather than installed.
+func (r *MultiClusterEngineReconciler) componentSupported(ctx context.Context, name string) (bool, error) {
+	switch name {
+	case backplanev1.ConsoleMCE:
+		return r.consoleSupported(ctx)
+	}
+	return true, nil
+}
+
+// unavailableDependency returns the first enabled dependency of the component that is not yet available
+func (r *MultiClusterEngineReconciler) unavailableDependency(backplaneConfig *backplanev1.MultiClusterEngine, name string, available map[string]bool) string {
+	for _, dependency := range toggle.ComponentDependencies[name] {
+		if backplaneConfig.Enabled(dependency) && !available[dependency] {
+			return dependency
+		}
+	}
+	return ""
 }
 
 func (r *MultiClusterEngineReconciler) applyTemplate(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, template *unstructured.Unstructured) (ctrl.Result, error) {
+	ctx = withResource(ctx, template)
+
 	// Set owner reference.
-	err := ctrl.SetControllerReference(backplaneConfig, template, r.Scheme)
+	err := r.setOwner(backplaneConfig, template)
 	if err != nil {
 		return ctrl.Result{}, pkgerrors.Wrapf(err, "Error setting controller reference on resource %s", template.GetName())
 	}
 
+	// The affinity is added before the patches, so a patch can still change it
+	if err := renderer.ApplyArchitectureAffinity(template, r.imageArchitectures); err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.Overlay != nil {
+		if err := r.Overlay.Apply(template); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if r.manifestPatches != nil {
+		if err := r.manifestPatches.Apply(template); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.checkOwnership(ctx, backplaneConfig, template); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if template.GetKind() == "APIService" {
 		result, err := r.ensureUnstructuredResource(ctx, backplaneConfig, template)
 		if err != nil {
@@ -526,22 +1171,85 @@ func (r *MultiClusterEngineReconciler) applyTemplate(ctx context.Context, backpl
 		}
 	} else {
 		// Apply the object data.
-		force := true
-		err = r.Client.Patch(ctx, template, client.Apply, &client.PatchOptions{Force: &force, FieldManager: "backplane-operator"})
-		if err != nil {
+		err = r.applyResource(ctx, backplaneConfig, template)
+		var conflict *applyConflictError
+		if errors.As(err, &conflict) {
+			return ctrl.Result{}, err
+		} else if err != nil {
 			return ctrl.Result{}, pkgerrors.Wrapf(err, "error applying object Name: %s Kind: %s", template.GetName(), template.GetKind())
 		}
 	}
 	return ctrl.Result{}, nil
 }
 
+// ownershipConflictError is returned when a resource the operator would apply is owned by another controller
+type ownershipConflictError struct {
+	message string
+}
+
+func (e *ownershipConflictError) Error() string {
+	return e.message
+}
+
+// checkOwnership returns an error if the resource described by template already exists and is managed by something
+// other than the backplaneConfig, so that it is not overwritten. A resource annotated for adoption has the other
+// controller's reference removed, as a resource can only have one controller.
+func (r *MultiClusterEngineReconciler) checkOwnership(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, template *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(template.GroupVersionKind())
+	err := r.Client.Get(ctx, types.NamespacedName{Name: template.GetName(), Namespace: template.GetNamespace()}, existing)
+	if err != nil && (apierrors.IsNotFound(err) || meta.IsNoMatchError(err)) {
+		return nil
+	} else if err != nil {
+		return pkgerrors.Wrapf(err, "error getting object Name: %s Kind: %s", template.GetName(), template.GetKind())
+	}
+
+	if owner := utils.ForeignOwner(existing, backplaneConfig); owner != "" {
+		name := existing.GetName()
+		if existing.GetNamespace() != "" {
+			name = existing.GetNamespace() + "/" + name
+		}
+		message := fmt.Sprintf("%s %s is owned by %s. Add the annotation %s=true to it to let the operator adopt it.",
+			existing.GetKind(), name, owner, utils.AnnotationAdopt)
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.OwnershipConflictReason, message))
+		return &ownershipConflictError{message: message}
+	}
+
+	if !removeForeignController(ctx, backplaneConfig, existing) {
+		return nil
+	}
+	if err := r.Client.Update(ctx, existing); err != nil {
+		return pkgerrors.Wrapf(err, "error adopting object Name: %s Kind: %s", existing.GetName(), existing.GetKind())
+	}
+	return nil
+}
+
+// removeForeignController removes the controller reference of obj if it points to an owner other than the
+// backplaneConfig. Returns true if obj was changed.
+func removeForeignController(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, obj *unstructured.Unstructured) bool {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.UID == backplaneConfig.GetUID() {
+		return false
+	}
+	log.FromContext(ctx).Info(fmt.Sprintf("Adopting %s %s from %s %s", obj.GetKind(), obj.GetName(), owner.Kind, owner.Name))
+	refs := []metav1.OwnerReference{}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != owner.UID {
+			refs = append(refs, ref)
+		}
+	}
+	obj.SetOwnerReferences(refs)
+	return true
+}
+
 // deleteTemplate return true if resource does not exist and returns an error if a GET or DELETE errors unexpectedly. A false response without error
 // means the resource is in the process of deleting.
 func (r *MultiClusterEngineReconciler) deleteTemplate(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, template *unstructured.Unstructured) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	err := r.Client.Get(ctx, types.NamespacedName{Name: template.GetName(), Namespace: template.GetNamespace()}, template)
 
-	if err != nil && apierrors.IsNotFound(err) {
+	// A missing CRD means no resources of that kind exist
+	if err != nil && (apierrors.IsNotFound(err) || meta.IsNoMatchError(err)) {
 		return ctrl.Result{}, nil
 	}
 
@@ -571,11 +1279,10 @@ func (r *MultiClusterEngineReconciler) ensureCustomResources(ctx context.Context
 		}
 		for _, addonTemplate := range addonTemplates {
 			addonTemplate.SetNamespace(backplaneConfig.Spec.TargetNamespace)
-			if err := ctrl.SetControllerReference(backplaneConfig, addonTemplate, r.Scheme); err != nil {
+			if err := r.setOwner(backplaneConfig, addonTemplate); err != nil {
 				return ctrl.Result{}, pkgerrors.Wrapf(err, "Error setting controller reference on resource %s", addonTemplate.GetName())
 			}
-			force := true
-			err := r.Client.Patch(ctx, addonTemplate, client.Apply, &client.PatchOptions{Force: &force, FieldManager: "backplane-operator"})
+			err := r.applyResource(ctx, backplaneConfig, addonTemplate)
 			if err != nil {
 				return ctrl.Result{}, pkgerrors.Wrapf(err, "error applying object Name: %s Kind: %s", addonTemplate.GetName(), addonTemplate.GetKind())
 			}
@@ -596,6 +1303,17 @@ func (r *MultiClusterEngineReconciler) finalizeBackplaneConfig(ctx context.Conte
 		return err
 	}
 
+	// The local cluster is detached while the registration and import controllers are still running
+	removed, err := r.removeLocalCluster(ctx, backplaneConfig)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.WaitingForResourceReason,
+			fmt.Sprintf("Waiting for %s to be detached", foundation.LocalClusterName)))
+		return fmt.Errorf("waiting for %s to be detached before proceeding with uninstallation", foundation.LocalClusterName)
+	}
+
 	clusterManager := &unstructured.Unstructured{}
 	clusterManager.SetGroupVersionKind(
 		schema.GroupVersionKind{
@@ -612,7 +1330,7 @@ func (r *MultiClusterEngineReconciler) finalizeBackplaneConfig(ctx context.Conte
 		if err != nil {
 			return err
 		}
-	} else if err != nil && !apierrors.IsNotFound(err) { // Return error, if error is not not found error
+	} else if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) { // Return error, if error is not not found error
 		return err
 	}
 
@@ -633,9 +1351,131 @@ func (r *MultiClusterEngineReconciler) finalizeBackplaneConfig(ctx context.Conte
 		return err
 	}
 
+	// Operand CRDs are removed while the operators are still running, so they can release their resources
+	if backplaneConfig.Spec.UninstallPolicy == backplanev1.UninstallPolicyDelete {
+		remaining, err := r.removeOperandCRDs(ctx)
+		if err != nil {
+			return err
+		}
+		backplaneConfig.Status.RemainingResources = remaining
+		if len(remaining) > 0 {
+			r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.WaitingForResourceReason,
+				fmt.Sprintf("Waiting for %d CRDs to be removed", len(remaining))))
+			return fmt.Errorf("waiting for %d CRDs to be removed before proceeding with uninstallation", len(remaining))
+		}
+	}
+
+	// Only let the multiclusterengine go once everything it installed is gone
+	remaining, err := r.removeOwnedResources(ctx, backplaneConfig)
+	if err != nil {
+		return err
+	}
+	backplaneConfig.Status.RemainingResources = remaining
+	if len(remaining) > 0 {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.WaitingForResourceReason,
+			fmt.Sprintf("Waiting for %d resources to be removed", len(remaining))))
+		return fmt.Errorf("waiting for %d resources to be removed before proceeding with uninstallation", len(remaining))
+	}
+
 	return nil
 }
 
+// uninstallKinds are the kinds of resources the operator removes when the multiclusterengine is deleted. The
+// operator finds them by their backplaneconfig.name label.
+var uninstallKinds = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "DeploymentList"},
+	{Group: "", Version: "v1", Kind: "ServiceList"},
+	{Group: "", Version: "v1", Kind: "ServiceAccountList"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleList"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBindingList"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleList"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBindingList"},
+	{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "ClusterManagerList"},
+	{Group: "hive.openshift.io", Version: "v1", Kind: "HiveConfigList"},
+}
+
+// removeOwnedResources deletes the resources labeled for the backplaneConfig and returns those that still exist.
+// At most maxRemainingResources are returned.
+func (r *MultiClusterEngineReconciler) removeOwnedResources(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) ([]backplanev1.BlockingResource, error) {
+	log := log.FromContext(ctx)
+	remaining := []backplanev1.BlockingResource{}
+	for _, gvk := range uninstallKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		err := r.Client.List(ctx, list, client.MatchingLabels{"backplaneconfig.name": backplaneConfig.GetName()})
+		if err != nil && meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			if item.GetDeletionTimestamp() == nil {
+				log.Info(fmt.Sprintf("Removing %s %s", item.GetKind(), item.GetName()))
+				if err := r.Client.Delete(ctx, item); err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+			}
+			if len(remaining) < maxRemainingResources {
+				remaining = append(remaining, backplanev1.BlockingResource{
+					Kind:      item.GetKind(),
+					Namespace: item.GetNamespace(),
+					Name:      item.GetName(),
+				})
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// operandCRDPaths are the directories of the operand CRDs removed under the Delete uninstall policy
+var operandCRDPaths = []string{
+	operandCRDsDir,
+	toggle.ManagedServiceAccountCRDPath,
+}
+
+// removeOperandCRDs deletes the operand CRDs and returns those that still exist. At most maxRemainingResources
+// are returned.
+func (r *MultiClusterEngineReconciler) removeOperandCRDs(ctx context.Context) ([]backplanev1.BlockingResource, error) {
+	log := log.FromContext(ctx)
+	remaining := []backplanev1.BlockingResource{}
+	for _, crdPath := range operandCRDPaths {
+		crds, errs := renderer.RenderCRDs(crdPath)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				log.Info(err.Error())
+			}
+			return nil, fmt.Errorf("failed to render the CRDs in %s", crdPath)
+		}
+
+		for _, crd := range crds {
+			existing := &unstructured.Unstructured{}
+			existing.SetGroupVersionKind(crd.GroupVersionKind())
+			err := r.Client.Get(ctx, types.NamespacedName{Name: crd.GetName()}, existing)
+			if apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			if existing.GetDeletionTimestamp() == nil {
+				log.Info(fmt.Sprintf("Removing CustomResourceDefinition %s", existing.GetName()))
+				if err := r.Client.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+			}
+			if len(remaining) < maxRemainingResources {
+				remaining = append(remaining, backplanev1.BlockingResource{
+					Kind: "CustomResourceDefinition",
+					Name: existing.GetName(),
+				})
+			}
+		}
+	}
+	return remaining, nil
+}
+
 func (r *MultiClusterEngineReconciler) getBackplaneConfig(ctx context.Context, req ctrl.Request) (*backplanev1.MultiClusterEngine, error) {
 	log := log.FromContext(ctx)
 	backplaneConfig := &backplanev1.MultiClusterEngine{}
@@ -713,14 +1553,18 @@ func (r *MultiClusterEngineReconciler) setDefaults(ctx context.Context, m *backp
 		updateNecessary = true
 	}
 
+	if utils.AddBackupLabel(m) {
+		updateNecessary = true
+	}
+
 	// If OCP 4.10+ then set then enable the MCE console. Else ensure it is disabled
 	currentClusterVersion, err := r.getClusterVersion(ctx, m)
 	if err != nil {
 		return ctrl.Result{}, pkgerrors.Wrapf(err, "failed to detect clusterversion")
 	}
 
-	// Set OCP version as env var, so that charts can render this value
-	os.Setenv("ACM_HUB_OCP_VERSION", currentClusterVersion)
+	// Pass the OCP version to the renderer, so that charts can render this value
+	r.render.OCPVersion = currentClusterVersion
 
 	currentVersion, err := semver.NewVersion(currentClusterVersion)
 	if err != nil {
@@ -739,9 +1583,16 @@ func (r *MultiClusterEngineReconciler) setDefaults(ctx context.Context, m *backp
 	if constraint.Check(currentVersion) {
 		// If ConsoleMCE config already exists, then don't overwrite it
 		if !m.ComponentPresent(backplanev1.ConsoleMCE) {
-			log.Info("Dynamic plugins are supported. ConsoleMCE Config is not detected. Enabling ConsoleMCE")
-			m.Enable(backplanev1.ConsoleMCE)
-			updateNecessary = true
+			// The console is an optional capability, so it is only enabled by default where it is installed
+			consoleSupported, err := r.consoleSupported(ctx)
+			if err != nil {
+				return ctrl.Result{}, pkgerrors.Wrapf(err, "failed to detect the console")
+			}
+			if consoleSupported {
+				log.Info("Dynamic plugins are supported. ConsoleMCE Config is not detected. Enabling ConsoleMCE")
+				m.Enable(backplanev1.ConsoleMCE)
+				updateNecessary = true
+			}
 		}
 	} else {
 		if m.Enabled(backplanev1.ConsoleMCE) {
@@ -777,7 +1628,8 @@ func (r *MultiClusterEngineReconciler) validateNamespace(ctx context.Context, m
 	checkNs := &corev1.Namespace{}
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: m.Spec.TargetNamespace}, checkNs)
 	if err != nil && apierrors.IsNotFound(err) {
-		if err := ctrl.SetControllerReference(m, newNs, r.Scheme); err != nil {
+		utils.SetPodSecurityLabels(newNs, utils.IsPodSecurityRestricted(m))
+		if err := r.setOwner(m, newNs); err != nil {
 			return ctrl.Result{}, pkgerrors.Wrapf(err, "Error setting controller reference on resource %s", m.Spec.TargetNamespace)
 		}
 		err = r.Client.Create(context.TODO(), newNs)
@@ -791,16 +1643,220 @@ func (r *MultiClusterEngineReconciler) validateNamespace(ctx context.Context, m
 	if err != nil && !apierrors.IsNotFound(err) {
 		return ctrl.Result{Requeue: true}, err
 	}
+
+	// Label the namespace for the Pod Security level the component pods are hardened to
+	if utils.SetPodSecurityLabels(checkNs, utils.IsPodSecurityRestricted(m)) {
+		if err := r.Client.Update(ctx, checkNs); err != nil {
+			log.Error(err, "Could not update the Pod Security labels of the namespace")
+			return ctrl.Result{}, err
+		}
+		log.Info("Namespace Pod Security labels updated")
+	}
 	return ctrl.Result{}, nil
 }
 
+// updateLogLevel sets the operator's log level from the MultiClusterEngines, or else from the log level ConfigMap,
+// falling back to the default level if neither sets one. The operator's level is shared by every
+// MultiClusterEngine, so the most verbose of their levels is used.
+func (r *MultiClusterEngineReconciler) updateLogLevel(ctx context.Context) {
+	if r.LogLevel == nil {
+		return
+	}
+	log := log.FromContext(ctx)
+
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(ctx, mceList); err != nil {
+		log.Error(err, "Failed to list multiclusterengines")
+		return
+	}
+	level, set := r.DefaultLogLevel, false
+	for _, mce := range mceList.Items {
+		if mce.Spec.LogLevel == "" {
+			continue
+		}
+		mceLevel, err := utils.ParseLogLevel(mce.Spec.LogLevel)
+		if err != nil {
+			log.Error(err, "Invalid log level in multiclusterengine", "name", mce.Name)
+			continue
+		}
+		if !set || mceLevel < level {
+			level, set = mceLevel, true
+		}
+	}
+
+	if !set {
+		cm := &corev1.ConfigMap{}
+		err := r.unlabeledReader().Get(ctx, types.NamespacedName{Name: utils.LogLevelConfigMap, Namespace: os.Getenv("POD_NAMESPACE")}, cm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get log level configmap")
+			return
+		}
+		if err == nil {
+			if level, err = utils.ParseLogLevel(cm.Data[utils.LogLevelKey]); err != nil {
+				log.Error(err, "Invalid log level in configmap", "name", utils.LogLevelConfigMap)
+				return
+			}
+		}
+	}
+
+	if r.LogLevel.Level() != level {
+		log.Info("Setting log level", "level", level.String())
+		r.LogLevel.SetLevel(level)
+	}
+}
+
+// trustedCABundleName returns the name of the ConfigMap holding the trusted CA bundle for the MCE
+func trustedCABundleName(m *backplanev1.MultiClusterEngine) string {
+	if m.Spec.Overrides != nil && m.Spec.Overrides.TrustedCABundleConfigMap != "" {
+		return m.Spec.Overrides.TrustedCABundleConfigMap
+	}
+	return utils.DefaultTrustedCABundle
+}
+
+// ensureTrustedCABundle looks up the trusted CA bundle ConfigMap in the target namespace and sets it in the
+// render options. A ConfigMap named in the spec must exist, while the default is only used if present.
+func (r *MultiClusterEngineReconciler) ensureTrustedCABundle(ctx context.Context, m *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	name := trustedCABundleName(m)
+
+	cm := &corev1.ConfigMap{}
+	err := r.unlabeledReader().Get(ctx, types.NamespacedName{Name: name, Namespace: m.Spec.TargetNamespace}, cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		r.StatusManager.AddCondition(status.NewConfigReloadedCondition(err))
+		return ctrl.Result{Requeue: true}, err
+	}
+	r.render.TrustedCABundle = types.NamespacedName{}
+	r.render.TrustedCABundleHash = ""
+	if apierrors.IsNotFound(err) {
+		if name != utils.DefaultTrustedCABundle {
+			log.Info("Trusted CA bundle ConfigMap not found", "name", name)
+			r.StatusManager.AddCondition(status.NewConfigReloadedCondition(err))
+			r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason, fmt.Sprintf("Trusted CA bundle ConfigMap %s not found", name)))
+			return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
+	hash, err := utils.HashData(cm.Data)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.render.TrustedCABundle = types.NamespacedName{Name: name, Namespace: m.Spec.TargetNamespace}
+	r.render.TrustedCABundleHash = hash
+	return ctrl.Result{}, nil
+}
+
+// ensureManifestPatches reads the manifest patches ConfigMap named in the spec from the target namespace. The
+// ConfigMap must exist and hold a valid overlay, as applying the manifests without the patches could undo them.
+func (r *MultiClusterEngineReconciler) ensureManifestPatches(ctx context.Context, m *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	r.manifestPatches = nil
+	if m.Spec.Overrides == nil || m.Spec.Overrides.ManifestPatchesConfigMap == "" {
+		return ctrl.Result{}, nil
+	}
+	name := m.Spec.Overrides.ManifestPatchesConfigMap
+
+	cm := &corev1.ConfigMap{}
+	err := r.unlabeledReader().Get(ctx, types.NamespacedName{Name: name, Namespace: m.Spec.TargetNamespace}, cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{Requeue: true}, err
+	}
+	if err == nil {
+		r.manifestPatches, err = overlay.FromConfigMap(cm)
+	}
+	if err != nil {
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.RequirementsNotMetReason,
+			fmt.Sprintf("Invalid manifest patches ConfigMap %s: %s", name, err.Error())))
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// ensurePullSecret copies the image pull secret from the operator's namespace into the target namespace, where the
+// components' pods can use it. A secret created directly in the target namespace is left alone.
+func (r *MultiClusterEngineReconciler) ensurePullSecret(ctx context.Context, m *backplanev1.MultiClusterEngine) error {
+	log := log.FromContext(ctx)
+	name := m.Spec.ImagePullSecret
+	if name == "" || m.Spec.TargetNamespace == utils.OperatorNamespace() {
+		return nil
+	}
+
+	source := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: utils.OperatorNamespace()}, source)
+	if apierrors.IsNotFound(err) {
+		log.Info("Image pull secret not found in the operator namespace. Expecting it in the target namespace.", "name", name)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: m.Spec.TargetNamespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if apierrors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: m.Spec.TargetNamespace},
+			Type:       source.Type,
+			Data:       source.Data,
+		}
+		if err := ctrl.SetControllerReference(m, secret, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Copying image pull secret to the target namespace", "name", name, "namespace", m.Spec.TargetNamespace)
+		return r.Client.Create(ctx, secret)
+	}
+
+	// Only keep the copy in sync, not a secret that was created in the target namespace
+	if !metav1.IsControlledBy(existing, m) || equality.Semantic.DeepEqual(existing.Data, source.Data) {
+		return nil
+	}
+	existing.Data = source.Data
+	return r.Client.Update(ctx, existing)
+}
+
+// installConfig holds the settings of the OpenShift install config the operator uses
+type installConfig struct {
+	FIPS bool `json:"fips"`
+}
+
+// fipsMode returns true if FIPS mode is forced by flag or enabled on the cluster. The cluster setting is read
+// from the install config, which only exists on OpenShift. It is read past the cache, which does not hold
+// kube-system when the operator watches only some namespaces.
+func (r *MultiClusterEngineReconciler) fipsMode(ctx context.Context) (bool, error) {
+	if r.FIPSMode {
+		return true, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := r.apiReader().Get(ctx, types.NamespacedName{Name: "cluster-config-v1", Namespace: "kube-system"}, cm)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	config := &installConfig{}
+	if err := yaml.Unmarshal([]byte(cm.Data["install-config"]), config); err != nil {
+		return false, fmt.Errorf("invalid install config: %w", err)
+	}
+	return config.FIPS, nil
+}
+
+// apiReader returns the reader for resources the cache does not hold
+func (r *MultiClusterEngineReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
 // adoptExistingSubcomponents checks for the existence of subcomponents installed by the MCH, and adds a label
 // signaling that they have been adopted by the MCE.
 func (r *MultiClusterEngineReconciler) adoptExistingSubcomponents(ctx context.Context, mce *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
 
 	log := log.FromContext(ctx)
 
-	cmTemplate := foundation.ClusterManager(mce, r.Images)
+	cmTemplate := foundation.ClusterManager(mce, r.render.Images)
 	hiveTemplate := hive.HiveConfig(mce)
 
 	resources := []*unstructured.Unstructured{cmTemplate, hiveTemplate}
@@ -810,15 +1866,20 @@ func (r *MultiClusterEngineReconciler) adoptExistingSubcomponents(ctx context.Co
 		existingResource := &unstructured.Unstructured{}
 		existingResource.SetGroupVersionKind(resource.GroupVersionKind())
 		err := r.Get(ctx, types.NamespacedName{Name: resource.GetName(), Namespace: resource.GetNamespace()}, existingResource)
-		if err != nil && !apierrors.IsNotFound(err) {
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 			log.Info(fmt.Sprintf("Unable to get existing resource: %+v", err.Error()))
 			return ctrl.Result{}, err
-		} else if apierrors.IsNotFound(err) {
-			// Resource doesn't exist, no need to adopt
+		} else if err != nil {
+			// Resource or its CRD doesn't exist, no need to adopt
 			continue
 		}
 
-		if err := ctrl.SetControllerReference(mce, existingResource, r.Scheme); err != nil {
+		// A restored multiclusterengine has a new UID, so drop the reference to the instance it was restored from
+		if utils.ForeignOwner(existingResource, mce) == "" {
+			removeForeignController(ctx, mce, existingResource)
+		}
+
+		if err := r.setOwner(mce, existingResource); err != nil {
 			return ctrl.Result{}, pkgerrors.Wrapf(err, "Error setting controller reference on resource %s", existingResource.GetName())
 		}
 
@@ -851,8 +1912,31 @@ func (r *MultiClusterEngineReconciler) getClusterVersion(ctx context.Context, mc
 	}
 
 	if len(clusterVersion.Status.History) == 0 {
-		log.Error(err, "Failed to detect status in clusterversion.status.history")
-		return "", err
+		return "", errClusterVersionUnknown
 	}
 	return clusterVersion.Status.History[0].Version, nil
 }
+
+// errClusterVersionUnknown is returned by getClusterVersion while the ClusterVersion has no history yet, as on a
+// cluster that is still installing
+var errClusterVersionUnknown = errors.New("clusterversion has no status.history yet")
+
+// olderBackplaneConfig returns the name of a MultiClusterEngine installing on the same cluster that was created
+// before backplaneConfig, or an empty string if backplaneConfig is the oldest
+func (r *MultiClusterEngineReconciler) olderBackplaneConfig(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (string, error) {
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(ctx, mceList); err != nil {
+		return "", err
+	}
+	created := backplaneConfig.GetCreationTimestamp()
+	for _, mce := range mceList.Items {
+		if mce.Name == backplaneConfig.Name || !backplaneConfig.SameTarget(&mce) {
+			continue
+		}
+		other := mce.GetCreationTimestamp()
+		if other.Before(&created) || (other.Equal(&created) && mce.Name < backplaneConfig.Name) {
+			return mce.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -41,7 +41,9 @@ import (
 	admissionregistration "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -53,6 +55,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -444,6 +447,51 @@ var _ = Describe("BackplaneConfig controller", func() {
 					return k8sClient.Get(ctx, namespacedName, resourceType)
 				}, timeout, interval).Should(Succeed())
 
+				By("ensuring an operand ServiceAccount is recreated if deleted")
+				serviceAccount := &corev1.ServiceAccount{}
+				serviceAccountKey := types.NamespacedName{Name: "managed-serviceaccount", Namespace: DestinationNamespace}
+				Expect(k8sClient.Get(context.Background(), serviceAccountKey, serviceAccount)).To(Succeed())
+				Expect(k8sClient.Delete(context.Background(), serviceAccount)).To(Succeed())
+				Eventually(func(g Gomega) {
+					recreated := &corev1.ServiceAccount{}
+					g.Expect(k8sClient.Get(context.Background(), serviceAccountKey, recreated)).To(Succeed())
+					g.Expect(recreated.UID).NotTo(Equal(serviceAccount.UID))
+				}, timeout, interval).Should(Succeed())
+
+				By("ensuring a conflicting change to an operand is reported rather than overwritten")
+				deploymentKey := types.NamespacedName{Name: "ocm-webhook", Namespace: DestinationNamespace}
+				Eventually(func() error {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(context.Background(), deploymentKey, deployment); err != nil {
+						return err
+					}
+					deployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+					return k8sClient.Update(context.Background(), deployment, client.FieldOwner("test-editor"))
+				}, timeout, interval).Should(Succeed())
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: BackplaneConfigName}, backplaneConfig)).To(Succeed())
+					g.Expect(backplaneConfig.Status.Conditions).To(ContainElement(HaveField("Reason", status.ApplyConflictReason)))
+				}, timeout, interval).Should(Succeed())
+
+				By("ensuring the change is overwritten once the operand is annotated for adoption")
+				Eventually(func() error {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(context.Background(), deploymentKey, deployment); err != nil {
+						return err
+					}
+					annotations := deployment.GetAnnotations()
+					if annotations == nil {
+						annotations = map[string]string{}
+					}
+					annotations[utils.AnnotationAdopt] = "true"
+					deployment.SetAnnotations(annotations)
+					return k8sClient.Update(context.Background(), deployment)
+				}, timeout, interval).Should(Succeed())
+				Eventually(func(g Gomega) {
+					deployment := &appsv1.Deployment{}
+					g.Expect(k8sClient.Get(context.Background(), deploymentKey, deployment)).To(Succeed())
+					g.Expect(deployment.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+				}, timeout, interval).Should(Succeed())
 			})
 		})
 
@@ -501,6 +549,81 @@ var _ = Describe("BackplaneConfig controller", func() {
 			})
 		})
 
+		Context("and it is restored from a backup while its resources still exist", func() {
+			It("should adopt the existing resources", func() {
+				By("creating a deployment owned by the backed up backplane config")
+				ctx := context.Background()
+				err := k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: DestinationNamespace}})
+				if err != nil {
+					Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+				}
+
+				isController := true
+				existing := &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "discovery-operator",
+						Namespace: DestinationNamespace,
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: "multicluster.openshift.io/v1",
+							Kind:       "MultiClusterEngine",
+							Name:       BackplaneConfigName,
+							UID:        "backed-up-uid",
+							Controller: &isController,
+						}},
+					},
+					Spec: appsv1.DeploymentSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "discovery-operator"}},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "discovery-operator"}},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "discovery-operator", Image: "quay.io/test/test:old"}},
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+
+				By("creating the restored backplane config")
+				backplaneConfig := &v1.MultiClusterEngine{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "multicluster.openshift.io/v1",
+						Kind:       "MultiClusterEngine",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   BackplaneConfigName,
+						Labels: map[string]string{utils.VeleroRestoreLabel: "hub-restore"},
+					},
+					Spec: v1.MultiClusterEngineSpec{
+						TargetNamespace: DestinationNamespace,
+						ImagePullSecret: "testsecret",
+					},
+				}
+				Expect(k8sClient.Create(ctx, backplaneConfig)).Should(Succeed())
+
+				By("ensuring the deployment is adopted by the restored backplane config")
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: BackplaneConfigName}, backplaneConfig)).To(Succeed())
+					deployment := &appsv1.Deployment{}
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "discovery-operator", Namespace: DestinationNamespace}, deployment)).To(Succeed())
+					g.Expect(deployment.GetOwnerReferences()).To(HaveLen(1))
+					g.Expect(deployment.GetOwnerReferences()[0].UID).To(Equal(backplaneConfig.GetUID()))
+				}, timeout, interval).Should(Succeed())
+
+				By("ensuring the restore is reported in status")
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: BackplaneConfigName}, backplaneConfig)).To(Succeed())
+					g.Expect(backplaneConfig.GetLabels()).To(HaveKeyWithValue(utils.BackupLabel, utils.BackupLabelValue))
+					restored := false
+					for _, c := range backplaneConfig.Status.Conditions {
+						if c.Type == v1.MultiClusterEngineRestored && c.Status == metav1.ConditionTrue {
+							restored = true
+						}
+					}
+					g.Expect(restored).To(BeTrue())
+				}, timeout, interval).Should(Succeed())
+			})
+		})
+
 		Context("and enable ManagedServiceAccount", func() {
 			It("should deploy sub components", func() {
 				By("creating the backplane config")
@@ -735,3 +858,40 @@ var _ = Describe("BackplaneConfig controller", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("Owned resource events", func() {
+	role := func(verbs ...string) *rbacv1.Role {
+		return &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "role", Namespace: "default", ResourceVersion: "1"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: verbs}},
+		}
+	}
+
+	It("should reconcile an edit of a resource without a generation", func() {
+		edited := role("get", "list")
+		edited.ResourceVersion = "2"
+		Expect(ownedChangedPredicate.Update(event.UpdateEvent{ObjectOld: role("get"), ObjectNew: edited})).To(BeTrue())
+	})
+
+	It("should reconcile a label change", func() {
+		relabeled := role("get")
+		relabeled.Labels = map[string]string{"backplaneconfig.name": "multiclusterengine"}
+		Expect(ownedChangedPredicate.Update(event.UpdateEvent{ObjectOld: role("get"), ObjectNew: relabeled})).To(BeTrue())
+	})
+
+	It("should ignore updates of the status and server maintained metadata", func() {
+		old := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default", ResourceVersion: "1"}}
+		updated := old.DeepCopy()
+		updated.ResourceVersion = "2"
+		updated.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager"}}
+		updated.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		Expect(ownedChangedPredicate.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})).To(BeFalse())
+
+		resync := role("get")
+		Expect(ownedChangedPredicate.Update(event.UpdateEvent{ObjectOld: resync, ObjectNew: resync.DeepCopy()})).To(BeFalse())
+	})
+
+	It("should reconcile deletions", func() {
+		Expect(ownedChangedPredicate.Delete(event.DeleteEvent{Object: role("get")})).To(BeTrue())
+	})
+})
@@ -0,0 +1,193 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	"github.com/stolostron/backplane-operator/pkg/version"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Parallel component apply", func() {
+	// The cluster-manager and hive have no dependencies, while the others depend on the cluster-manager
+	allComponents := []string{v1.ClusterManager, v1.Hive, v1.Discovery, v1.ServerFoundation, v1.ClusterBackup}
+
+	var (
+		ctx        context.Context
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+
+		mu         sync.Mutex
+		ensured    []string
+		running    int
+		maxRunning int
+		failing    string
+	)
+
+	component := func(name string) toggleableComponent {
+		return toggleableComponent{
+			ensure: func(context.Context, *v1.MultiClusterEngine) (ctrl.Result, error) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				defer mu.Unlock()
+				running--
+				ensured = append(ensured, name)
+				if name == failing {
+					return ctrl.Result{}, errors.New("webhook timed out")
+				}
+				return ctrl.Result{}, nil
+			},
+			ensureNo: func(context.Context, *v1.MultiClusterEngine) (ctrl.Result, error) {
+				return ctrl.Result{}, nil
+			},
+			deployment: types.NamespacedName{Name: name, Namespace: "multicluster-engine"},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ensured, running, maxRunning, failing = []string{}, 0, 0, ""
+
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"},
+			Spec: v1.MultiClusterEngineSpec{
+				TargetNamespace: "multicluster-engine",
+				Overrides: &v1.Overrides{
+					Components: []v1.ComponentConfig{
+						{Name: v1.ClusterManager, Enabled: true},
+						{Name: v1.Hive, Enabled: true},
+						{Name: v1.Discovery, Enabled: true},
+						{Name: v1.ServerFoundation, Enabled: true},
+						{Name: v1.ClusterBackup, Enabled: true},
+					},
+				},
+			},
+			Status: v1.MultiClusterEngineStatus{CurrentVersion: version.Get().GitVersion},
+		}
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		objs := []runtime.Object{}
+		for _, name := range allComponents {
+			objs = append(objs, &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "multicluster-engine"},
+				Status: appsv1.DeploymentStatus{
+					Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+				},
+			})
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+		reconciler = &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+	})
+
+	components := func() map[string]toggleableComponent {
+		return map[string]toggleableComponent{
+			v1.ClusterManager:   component(v1.ClusterManager),
+			v1.Hive:             component(v1.Hive),
+			v1.Discovery:        component(v1.Discovery),
+			v1.ServerFoundation: component(v1.ServerFoundation),
+			v1.ClusterBackup:    component(v1.ClusterBackup),
+		}
+	}
+
+	It("should apply independent components in parallel, up to the number of workers", func() {
+		reconciler.ApplyWorkers = 2
+		_, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(Succeed())
+		Expect(ensured).To(ConsistOf(allComponents))
+		Expect(maxRunning).To(Equal(2))
+	})
+
+	It("should apply a component only after its dependencies", func() {
+		reconciler.ApplyWorkers = 5
+		_, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(Succeed())
+		Expect(ensured).To(HaveLen(len(allComponents)))
+		Expect(ensured[:2]).To(ConsistOf(v1.ClusterManager, v1.Hive))
+		Expect(maxRunning).To(Equal(3))
+	})
+
+	It("should apply one component at a time by default", func() {
+		_, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(Succeed())
+		Expect(maxRunning).To(Equal(1))
+	})
+
+	It("should apply the other components when one fails and report its error", func() {
+		reconciler.ApplyWorkers = 5
+		failing = v1.Hive
+		result, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(MatchError(ContainSubstring("error ensuring hive: webhook timed out")))
+		Expect(result.RequeueAfter).To(Equal(requeuePeriod))
+		Expect(ensured).To(ConsistOf(allComponents))
+	})
+
+	It("should back off a failing component rather than apply it on every reconcile", func() {
+		reconciler.ApplyWorkers = 5
+		failing = v1.Hive
+		_, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(HaveOccurred())
+
+		ensured = []string{}
+		result, err := reconciler.ensureComponentsInOrder(ctx, mce, components())
+		Expect(err).To(Succeed())
+		Expect(ensured).To(ConsistOf(v1.ClusterManager, v1.Discovery, v1.ServerFoundation, v1.ClusterBackup))
+		Expect(result.RequeueAfter).To(And(BeNumerically(">", 0), BeNumerically("<=", baseComponentBackoff)))
+
+		components := reconciler.StatusManager.ReportStatus(*mce).Components
+		Expect(components).To(ContainElement(And(
+			HaveField("Name", v1.Hive),
+			HaveField("Retries", 1),
+			HaveField("LastError", "webhook timed out"),
+		)))
+	})
+})
+
+var _ = DescribeTable("Requeue after the status update",
+	func(phase v1.PhaseType, paused bool, result, expected ctrl.Result) {
+		mce := &v1.MultiClusterEngine{Status: v1.MultiClusterEngineStatus{Phase: phase}}
+		if paused {
+			mce.SetAnnotations(map[string]string{utils.AnnotationMCEPause: "true"})
+		}
+		Expect(requeueAfterStatus(mce, result)).To(Equal(expected))
+	},
+	Entry("checks a progressing install again soon", v1.MultiClusterEnginePhaseProgressing, false,
+		ctrl.Result{}, ctrl.Result{RequeueAfter: 10 * time.Second}),
+	Entry("keeps the backoff of a failing component", v1.MultiClusterEnginePhaseProgressing, false,
+		ctrl.Result{RequeueAfter: 80 * time.Second}, ctrl.Result{RequeueAfter: 80 * time.Second}),
+	Entry("keeps a shorter requeue", v1.MultiClusterEnginePhaseProgressing, false,
+		ctrl.Result{RequeueAfter: 5 * time.Second}, ctrl.Result{RequeueAfter: 5 * time.Second}),
+	Entry("resyncs an available install", v1.MultiClusterEnginePhaseAvailable, false,
+		ctrl.Result{}, ctrl.Result{RequeueAfter: resyncPeriod}),
+	Entry("caps a requeue at the resync period", v1.MultiClusterEnginePhaseAvailable, false,
+		ctrl.Result{RequeueAfter: time.Hour}, ctrl.Result{RequeueAfter: resyncPeriod}),
+	Entry("leaves a paused install alone", v1.MultiClusterEnginePhaseProgressing, true,
+		ctrl.Result{}, ctrl.Result{}),
+)
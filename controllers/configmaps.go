@@ -0,0 +1,72 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// configMapWatches watches all the ConfigMaps of the namespaces the MultiClusterEngines read their configuration
+// from. It is used when the manager's cache only holds labeled ConfigMaps, which leaves out the log level and
+// blocking resources ConfigMaps and the trusted CA bundle and manifest patches ConfigMaps users create. Each
+// namespace gets a cache of its own, which is kept until the operator restarts.
+type configMapWatches struct {
+	mu         sync.Mutex
+	mgr        ctrl.Manager
+	controller controller.Controller
+	handler    handler.EventHandler
+	namespaces map[string]bool
+}
+
+// watch starts watching the ConfigMaps of the namespace, unless they already are
+func (w *configMapWatches) watch(namespace string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.namespaces[namespace] {
+		return nil
+	}
+
+	namespaceCache, err := cache.New(w.mgr.GetConfig(), cache.Options{
+		Scheme:    w.mgr.GetScheme(),
+		Mapper:    w.mgr.GetRESTMapper(),
+		Namespace: namespace,
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.mgr.Add(namespaceCache); err != nil {
+		return err
+	}
+	if err := w.controller.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, namespaceCache), w.handler); err != nil {
+		return err
+	}
+	w.namespaces[namespace] = true
+	return nil
+}
+
+// watchConfigMaps makes sure changes to the unlabeled ConfigMaps of the namespace are reconciled when the cache is
+// label filtered. Otherwise the manager's cache already watches them.
+func (r *MultiClusterEngineReconciler) watchConfigMaps(namespace string) error {
+	if r.configMapWatches == nil {
+		return nil
+	}
+	return r.configMapWatches.watch(namespace)
+}
+
+// unlabeledReader returns the reader for the Deployments and ConfigMaps the operator doesn't apply, such as its
+// configuration ConfigMaps. The cache does not hold them when it is label filtered, so they are read from the API
+// server instead.
+func (r *MultiClusterEngineReconciler) unlabeledReader() client.Reader {
+	if r.LabelFilteredCache {
+		return r.apiReader()
+	}
+	return r.Client
+}
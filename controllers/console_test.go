@@ -0,0 +1,68 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Console detection", func() {
+	var (
+		ctx        context.Context
+		s          *runtime.Scheme
+		consoleCRD *apixv1.CustomResourceDefinition
+		console    *operatorv1.Console
+	)
+
+	reconcilerWith := func(objs ...client.Object) *MultiClusterEngineReconciler {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+		return &MultiClusterEngineReconciler{Client: c, Scheme: s}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		Expect(apixv1.AddToScheme(s)).To(Succeed())
+		Expect(operatorv1.AddToScheme(s)).To(Succeed())
+
+		consoleCRD = &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: consolePluginCRDName}}
+		console = &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	})
+
+	It("should support the console plugin where the console is installed", func() {
+		r := reconcilerWith(consoleCRD, console)
+		Expect(r.consoleSupported(ctx)).To(BeTrue())
+		Expect(r.componentSupported(ctx, v1.ConsoleMCE)).To(BeTrue())
+	})
+
+	It("should not support the console plugin without the ConsolePlugin API", func() {
+		r := reconcilerWith(console)
+		Expect(r.consoleSupported(ctx)).To(BeFalse())
+		Expect(r.componentSupported(ctx, v1.ConsoleMCE)).To(BeFalse())
+	})
+
+	It("should not support the console plugin when the console is disabled", func() {
+		r := reconcilerWith(consoleCRD)
+		Expect(r.consoleSupported(ctx)).To(BeFalse())
+	})
+
+	It("should support the other components regardless of the console", func() {
+		r := reconcilerWith()
+		Expect(r.componentSupported(ctx, v1.Hive)).To(BeTrue())
+	})
+})
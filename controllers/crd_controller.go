@@ -0,0 +1,194 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// operandCRDsDir is the directory of the CRDs of the components that are always installed
+const operandCRDsDir = "pkg/templates/crds"
+
+// OperandCRDReconciler applies the operand CRDs. It runs apart from the MultiClusterEngine reconciler so the CRDs
+// are in place before the components using them are deployed, and restores a CRD that is changed or deleted.
+type OperandCRDReconciler struct {
+	Client client.Client
+	// CRDs are the rendered operand CRDs, keyed by name
+	CRDs map[string]*unstructured.Unstructured
+	// MaxConcurrentReconciles is the number of CRDs applied in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+}
+
+// storedVersionError is returned when a CRD is not updated because the new definition no longer serves a version
+// its resources are stored in
+type storedVersionError struct {
+	name    string
+	version string
+}
+
+func (e *storedVersionError) Error() string {
+	return fmt.Sprintf("refusing to update CRD %s: resources are stored in version %s, which the new definition no longer serves", e.name, e.version)
+}
+
+// RenderOperandCRDs renders the CRDs in dir, keyed by name
+func RenderOperandCRDs(dir string) (map[string]*unstructured.Unstructured, error) {
+	crds, errs := renderer.RenderCRDs(dir)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to render the CRDs in %s: %w", dir, errs[0])
+	}
+	rendered := map[string]*unstructured.Unstructured{}
+	for _, crd := range crds {
+		rendered[crd.GetName()] = crd
+	}
+	return rendered, nil
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=create;get;list;update;watch
+
+// Reconcile applies the operand CRD named in the request
+func (r *OperandCRDReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = withReconcileID(ctx)
+	log := log.FromContext(ctx)
+
+	crd, ok := r.CRDs[req.Name]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	uninstalling, err := r.uninstallingCRDs(ctx)
+	if err != nil || uninstalling {
+		return ctrl.Result{}, err
+	}
+
+	err = applyCRD(ctx, r.Client, crd)
+	var conflict *storedVersionError
+	if errors.As(err, &conflict) {
+		// Retrying does not resolve the conflict. It is reported in the status of the MultiClusterEngine.
+		log.Error(err, "Operand CRD not updated")
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// uninstallingCRDs returns true while a MultiClusterEngine is deleted under the Delete uninstall policy, so the
+// CRDs it removes are not restored
+func (r *OperandCRDReconciler) uninstallingCRDs(ctx context.Context) (bool, error) {
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(ctx, mceList); err != nil {
+		return false, err
+	}
+	for _, mce := range mceList.Items {
+		if mce.GetDeletionTimestamp() != nil && mce.Spec.UninstallPolicy == backplanev1.UninstallPolicyDelete {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Every operand CRD is reconciled once on start, and
+// again whenever it is changed or deleted.
+func (r *OperandCRDReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	initial := make(chan event.GenericEvent, len(r.CRDs))
+	for _, crd := range r.CRDs {
+		initial <- event.GenericEvent{Object: crd}
+	}
+
+	operandCRD := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, ok := r.CRDs[obj.GetName()]
+		return ok
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("operandcrd").
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		For(&apixv1.CustomResourceDefinition{}, builder.WithPredicates(operandCRD, predicate.GenerationChangedPredicate{})).
+		Watches(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
+
+// applyCRD creates or updates a CRD. An update that stops serving a version the resources of the CRD are stored
+// in is refused, as they could no longer be read. A CRD that preserves unknown fields keeps doing so, so fields
+// stored under the old definition are not pruned.
+func applyCRD(ctx context.Context, c client.Client, desired *unstructured.Unstructured) error {
+	existing := &apixv1.CustomResourceDefinition{}
+	err := c.Get(ctx, types.NamespacedName{Name: desired.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return c.Create(ctx, desired.DeepCopy())
+	} else if err != nil {
+		return err
+	}
+
+	if version := status.UnservedStoredVersion(existing, status.CRDVersions(desired)); version != "" {
+		return &storedVersionError{name: desired.GetName(), version: version}
+	}
+
+	crd := desired.DeepCopy()
+	if existing.Spec.PreserveUnknownFields {
+		if err := unstructured.SetNestedField(crd.Object, true, "spec", "preserveUnknownFields"); err != nil {
+			return err
+		}
+	}
+	crd.SetResourceVersion(existing.GetResourceVersion())
+	return c.Update(ctx, crd)
+}
+
+// ensureOperandCRDs tracks the status of the operand CRDs and requeues until all are established, so components
+// are only deployed once the CRDs they serve are in place. The OperandCRDReconciler only manages the CRDs of the
+// local cluster, so on a hosted cluster they are applied here.
+func (r *MultiClusterEngineReconciler) ensureOperandCRDs(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	crds, err := RenderOperandCRDs(operandCRDsDir)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pending := []string{}
+	for name, crd := range crds {
+		if r.hosted {
+			err := applyCRD(ctx, r.Client, crd)
+			var conflict *storedVersionError
+			if errors.As(err, &conflict) {
+				log.Error(err, "Operand CRD not updated")
+			} else if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		reporter := status.CRDStatus{Name: name, Versions: status.CRDVersions(crd)}
+		r.StatusManager.AddComponent(reporter)
+		if !reporter.Status(r.Client).Available {
+			pending = append(pending, name)
+		}
+	}
+
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.WaitingForResourceReason,
+			fmt.Sprintf("Waiting for CRDs to be established: %s", strings.Join(pending, ", "))))
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,121 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// renderedCRD returns an operand CRD as rendered from the templates, serving the given versions
+func renderedCRD(versions ...string) *unstructured.Unstructured {
+	specVersions := []interface{}{}
+	for i, v := range versions {
+		specVersions = append(specVersions, map[string]interface{}{
+			"name":    v,
+			"served":  true,
+			"storage": i == len(versions)-1,
+			"schema": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{"type": "object"},
+			},
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "mocks.example.com"},
+		"spec": map[string]interface{}{
+			"group":    "example.com",
+			"names":    map[string]interface{}{"kind": "Mock", "plural": "mocks"},
+			"scope":    "Namespaced",
+			"versions": specVersions,
+		},
+	}}
+}
+
+var _ = Describe("Operand CRD reconciler", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *OperandCRDReconciler
+		req        ctrl.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(apixv1.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).Build()
+		reconciler = &OperandCRDReconciler{Client: c, CRDs: map[string]*unstructured.Unstructured{}}
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "mocks.example.com"}}
+	})
+
+	It("should create a missing CRD", func() {
+		reconciler.CRDs["mocks.example.com"] = renderedCRD("v1")
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(Succeed())
+
+		crd := &apixv1.CustomResourceDefinition{}
+		Expect(c.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+		Expect(crd.Spec.Versions).To(HaveLen(1))
+	})
+
+	It("should refuse to stop serving a stored version", func() {
+		Expect(c.Create(ctx, renderedCRD("v1alpha1", "v1"))).To(Succeed())
+		crd := &apixv1.CustomResourceDefinition{}
+		Expect(c.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+		crd.Status.StoredVersions = []string{"v1alpha1", "v1"}
+		Expect(c.Status().Update(ctx, crd)).To(Succeed())
+
+		err := applyCRD(ctx, c, renderedCRD("v1"))
+		Expect(err).To(MatchError(ContainSubstring("resources are stored in version v1alpha1")))
+
+		reconciler.CRDs["mocks.example.com"] = renderedCRD("v1")
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).To(Succeed())
+		Expect(c.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+		Expect(crd.Spec.Versions).To(HaveLen(2))
+	})
+
+	It("should keep preserving unknown fields", func() {
+		existing := renderedCRD("v1")
+		Expect(unstructured.SetNestedField(existing.Object, true, "spec", "preserveUnknownFields")).To(Succeed())
+		Expect(c.Create(ctx, existing)).To(Succeed())
+
+		Expect(applyCRD(ctx, c, renderedCRD("v1", "v2"))).To(Succeed())
+		crd := &apixv1.CustomResourceDefinition{}
+		Expect(c.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+		Expect(crd.Spec.PreserveUnknownFields).To(BeTrue())
+		Expect(crd.Spec.Versions).To(HaveLen(2))
+	})
+
+	It("should not restore CRDs removed by an uninstall", func() {
+		now := metav1.Now()
+		mce := &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", DeletionTimestamp: &now, Finalizers: []string{backplaneFinalizer}},
+			Spec:       v1.MultiClusterEngineSpec{UninstallPolicy: v1.UninstallPolicyDelete},
+		}
+		Expect(c.Create(ctx, mce)).To(Succeed())
+		reconciler.CRDs["mocks.example.com"] = renderedCRD("v1")
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(Succeed())
+		Expect(c.Get(ctx, req.NamespacedName, &apixv1.CustomResourceDefinition{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,77 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// dryRunManifestsKey holds the rendered manifests in the dry run ConfigMap, as a multi-document YAML
+	dryRunManifestsKey = "manifests.yaml"
+	// dryRunErrorsKey holds the rendering errors in the dry run ConfigMap, one per line
+	dryRunErrorsKey = "errors"
+)
+
+// dryRunConfigMapName returns the name of the ConfigMap the results of a dry run of the backplaneConfig are
+// written to
+func dryRunConfigMapName(backplaneConfig *backplanev1.MultiClusterEngine) string {
+	return backplaneConfig.Name + "-dry-run"
+}
+
+// dryRun renders the manifests the installer would apply and writes them, with any errors, to a ConfigMap in the
+// operator's namespace. Nothing is applied to the cluster the components are installed on.
+func (r *MultiClusterEngineReconciler) dryRun(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine, installer *MultiClusterEngineReconciler) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	manifests, errs := installer.RenderManifests(backplaneConfig, installer.render)
+	docs := []string{}
+	for _, manifest := range manifests {
+		out, err := yaml.Marshal(manifest.Object)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		docs = append(docs, string(out))
+	}
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: dryRunConfigMapName(backplaneConfig), Namespace: utils.OperatorNamespace()},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Data = map[string]string{dryRunManifestsKey: strings.Join(docs, "---\n")}
+		if len(messages) > 0 {
+			cm.Data[dryRunErrorsKey] = strings.Join(messages, "\n")
+		}
+		return ctrl.SetControllerReference(backplaneConfig, cm, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to write the dry run results", "configmap", cm.Name)
+		return ctrl.Result{}, err
+	}
+
+	message := fmt.Sprintf("Dry run rendered %d resources to ConfigMap %s/%s. Nothing was applied.", len(docs), cm.Namespace, cm.Name)
+	if len(messages) > 0 {
+		message = fmt.Sprintf("Dry run rendered %d resources to ConfigMap %s/%s with %d errors. Nothing was applied.", len(docs), cm.Namespace, cm.Name, len(messages))
+	}
+	log.Info(message)
+	r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionFalse, status.DryRunReason, message))
+	return ctrl.Result{}, nil
+}
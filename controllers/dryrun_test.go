@@ -0,0 +1,82 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Dry run", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "mce-uid"},
+			Spec: v1.MultiClusterEngineSpec{
+				TargetNamespace: "multicluster-engine",
+				DryRun:          true,
+				Overrides: &v1.Overrides{
+					Components: []v1.ComponentConfig{{Name: v1.Discovery, Enabled: true}},
+				},
+			},
+		}
+		images := map[string]string{}
+		for _, v := range utils.GetTestImages() {
+			images[v] = "quay.io/test/test:test"
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(mce).Build()
+		reconciler = &MultiClusterEngineReconciler{Client: c, Scheme: s, render: renderer.Options{Images: images}, StatusManager: &status.StatusTracker{Client: c}}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("POD_NAMESPACE")).To(Succeed())
+	})
+
+	It("should write the rendered manifests to a ConfigMap without applying them", func() {
+		_, err := reconciler.dryRun(ctx, mce, reconciler)
+		Expect(err).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "multiclusterengine-dry-run", Namespace: "default"}, cm)).To(Succeed())
+		Expect(metav1.IsControlledBy(cm, mce)).To(BeTrue())
+		Expect(cm.Data).NotTo(HaveKey(dryRunErrorsKey))
+		Expect(cm.Data[dryRunManifestsKey]).To(ContainSubstring("name: discovery-operator"))
+		Expect(strings.Count(cm.Data[dryRunManifestsKey], "---\n")).To(BeNumerically(">", 0))
+
+		namespaces := &corev1.NamespaceList{}
+		Expect(c.List(ctx, namespaces)).To(Succeed())
+		Expect(namespaces.Items).To(BeEmpty())
+
+		Expect(reconciler.StatusManager.Conditions).To(ContainElement(HaveField("Reason", status.DryRunReason)))
+	})
+})
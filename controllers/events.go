@@ -0,0 +1,57 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/audit"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the Events recorded on the MultiClusterEngine
+const (
+	ApplyFailedEvent       = "ApplyFailed"
+	ComponentDegradedEvent = "ComponentDegraded"
+	InstallCompleteEvent   = "InstallComplete"
+	UninstallBlockedEvent  = "UninstallBlocked"
+)
+
+// recordEvent records an Event on the MultiClusterEngine if the reconciler has a recorder, and forwards it to the
+// audit sink if there is one
+func (r *MultiClusterEngineReconciler) recordEvent(mce *backplanev1.MultiClusterEngine, eventType, reason, message string) {
+	if r.AuditSink != nil {
+		r.AuditSink.Record(audit.NewEvent(mce.Name, eventType, reason, message))
+	}
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(mce, eventType, reason, message)
+}
+
+// recordTransitionEvents records an Event when the MultiClusterEngine becomes available or degraded
+func (r *MultiClusterEngineReconciler) recordTransitionEvents(mce *backplanev1.MultiClusterEngine, previous []backplanev1.MultiClusterEngineCondition) {
+	if becameTrue(backplanev1.MultiClusterEngineAvailable, previous, mce.Status.Conditions) != nil {
+		r.recordEvent(mce, corev1.EventTypeNormal, InstallCompleteEvent, "All components are available")
+	}
+	if c := becameTrue(backplanev1.MultiClusterEngineDegraded, previous, mce.Status.Conditions); c != nil {
+		r.recordEvent(mce, corev1.EventTypeWarning, ComponentDegradedEvent, c.Message)
+	}
+}
+
+// becameTrue returns the condition of the given type if it is true in current but was not in previous
+func becameTrue(condType backplanev1.MultiClusterEngineConditionType, previous, current []backplanev1.MultiClusterEngineCondition) *backplanev1.MultiClusterEngineCondition {
+	for i, c := range current {
+		if c.Type != condType || c.Status != metav1.ConditionTrue {
+			continue
+		}
+		for _, p := range previous {
+			if p.Type == condType && p.Status == metav1.ConditionTrue {
+				return nil
+			}
+		}
+		return &current[i]
+	}
+	return nil
+}
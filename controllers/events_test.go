@@ -0,0 +1,79 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/audit"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("MultiClusterEngine events", func() {
+	var (
+		recorder   *record.FakeRecorder
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+	)
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MultiClusterEngineReconciler{Recorder: recorder}
+		mce = &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"}}
+	})
+
+	It("should be recorded when the install completes or a component degrades", func() {
+		previous := []v1.MultiClusterEngineCondition{
+			status.NewCondition(v1.MultiClusterEngineAvailable, metav1.ConditionFalse, status.ComponentsUnavailableReason, ""),
+		}
+		mce.Status.Conditions = []v1.MultiClusterEngineCondition{
+			status.NewCondition(v1.MultiClusterEngineAvailable, metav1.ConditionTrue, status.ComponentsAvailableReason, ""),
+			status.NewCondition(v1.MultiClusterEngineDegraded, metav1.ConditionTrue, status.ComponentsDegradedReason, "hive-operator failed"),
+		}
+		reconciler.recordTransitionEvents(mce, previous)
+		Expect(recorder.Events).To(Receive(Equal("Normal InstallComplete All components are available")))
+		Expect(recorder.Events).To(Receive(Equal("Warning ComponentDegraded hive-operator failed")))
+	})
+
+	It("should be forwarded to the audit sink", func() {
+		received := make(chan audit.Event, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			e := audit.Event{}
+			Expect(json.NewDecoder(r.Body).Decode(&e)).To(Succeed())
+			received <- e
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reconciler.AuditSink = audit.NewSink(server.URL)
+		go reconciler.AuditSink.Start(ctx)
+
+		reconciler.recordEvent(mce, "Warning", ApplyFailedEvent, "webhook timed out")
+		Eventually(received).Should(Receive(And(
+			HaveField("Name", "multiclusterengine"),
+			HaveField("Type", "Warning"),
+			HaveField("Reason", ApplyFailedEvent),
+			HaveField("Message", "webhook timed out"),
+		)))
+		Expect(recorder.Events).To(Receive(Equal("Warning ApplyFailed webhook timed out")))
+	})
+
+	It("should not be repeated while a condition stays true", func() {
+		mce.Status.Conditions = []v1.MultiClusterEngineCondition{
+			status.NewCondition(v1.MultiClusterEngineAvailable, metav1.ConditionTrue, status.ComponentsAvailableReason, ""),
+		}
+		reconciler.recordTransitionEvents(mce, mce.Status.Conditions)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// failingReader fails every read, as the API server does when it can't be reached
+type failingReader struct{}
+
+func (failingReader) Get(context.Context, client.ObjectKey, client.Object) error {
+	return errors.New("connection refused")
+}
+
+func (failingReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return errors.New("connection refused")
+}
+
+var _ = Describe("FIPS mode", func() {
+	var (
+		ctx context.Context
+		s   *runtime.Scheme
+	)
+
+	installConfig := func(data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "kube-system"},
+			Data:       map[string]string{"install-config": data},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+	})
+
+	It("should read FIPS mode from the install config past the cache", func() {
+		reader := fake.NewClientBuilder().WithScheme(s).WithObjects(installConfig("fips: true\n")).Build()
+		r := &MultiClusterEngineReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build(), APIReader: reader}
+		Expect(r.fipsMode(ctx)).To(BeTrue())
+	})
+
+	It("should leave FIPS mode disabled off OpenShift", func() {
+		r := &MultiClusterEngineReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+		Expect(r.fipsMode(ctx)).To(BeFalse())
+	})
+
+	It("should enable FIPS mode by flag", func() {
+		r := &MultiClusterEngineReconciler{APIReader: failingReader{}, FIPSMode: true}
+		Expect(r.fipsMode(ctx)).To(BeTrue())
+	})
+
+	It("should return an error rather than assume FIPS mode is disabled", func() {
+		r := &MultiClusterEngineReconciler{APIReader: failingReader{}}
+		_, err := r.fipsMode(ctx)
+		Expect(err).To(MatchError("connection refused"))
+
+		r.APIReader = fake.NewClientBuilder().WithScheme(s).WithObjects(installConfig("fips: [")).Build()
+		_, err = r.fipsMode(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
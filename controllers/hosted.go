@@ -0,0 +1,77 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// hostedKubeconfigKey is the key of the hosted cluster's kubeconfig in the hosted kubeconfig secret
+const hostedKubeconfigKey = "kubeconfig"
+
+// componentReconciler returns the reconciler that installs the components of the MultiClusterEngine. In the
+// Hosted deployment mode it works against the hosted cluster named by the kubeconfig secret, otherwise it is r.
+func (r *MultiClusterEngineReconciler) componentReconciler(ctx context.Context, mce *backplanev1.MultiClusterEngine) (*MultiClusterEngineReconciler, error) {
+	if !mce.IsHosted() {
+		r.StatusManager.HostedClient = nil
+		return r, nil
+	}
+
+	hostedClient, err := r.hostedClient(ctx, mce)
+	if err != nil {
+		return nil, err
+	}
+	r.StatusManager.HostedClient = hostedClient
+
+	// The hosted reconciler keeps the settings of r
+	installer := *r
+	installer.Client = hostedClient
+	installer.APIReader = hostedClient
+	installer.hosted = true
+	return &installer, nil
+}
+
+// hostedClient returns a client for the hosted cluster from the kubeconfig secret in the operator's namespace
+func (r *MultiClusterEngineReconciler) hostedClient(ctx context.Context, mce *backplanev1.MultiClusterEngine) (client.Client, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: mce.Spec.HostedKubeconfigSecret, Namespace: os.Getenv("POD_NAMESPACE")}
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get hosted kubeconfig secret %s: %w", key.Name, err)
+	}
+	kubeconfig, ok := secret.Data[hostedKubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("hosted kubeconfig secret %s has no %s key", key.Name, hostedKubeconfigKey)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig in secret %s: %w", key.Name, err)
+	}
+	// Discovery is deferred to the first request, so an unreachable cluster is reported by the requests made
+	mapper, err := apiutil.NewDynamicRESTMapper(config, apiutil.WithLazyDiscovery)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: r.Scheme, Mapper: mapper})
+}
+
+// setOwner makes the backplaneConfig the controller of obj. Resources on a hosted cluster get no owner
+// reference, as the backplaneConfig does not exist there and the garbage collector would remove them.
+func (r *MultiClusterEngineReconciler) setOwner(backplaneConfig *backplanev1.MultiClusterEngine, obj metav1.Object) error {
+	if r.hosted {
+		return nil
+	}
+	return ctrl.SetControllerReference(backplaneConfig, obj, r.Scheme)
+}
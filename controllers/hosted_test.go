@@ -0,0 +1,133 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/audit"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const hostedKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: hosted
+  cluster:
+    server: https://api.hosted.example.com:6443
+contexts:
+- name: hosted
+  context:
+    cluster: hosted
+    user: admin
+current-context: hosted
+users:
+- name: admin
+  user:
+    token: hosted-token
+`
+
+var _ = Describe("Hosted deployment mode", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "mce-uid"},
+			Spec: v1.MultiClusterEngineSpec{
+				TargetNamespace:        "multicluster-engine",
+				DeploymentMode:         v1.ModeHosted,
+				HostedKubeconfigSecret: "hosted-kubeconfig",
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(mce).Build()
+		reconciler = &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("POD_NAMESPACE")).To(Succeed())
+	})
+
+	It("should install on the local cluster by default", func() {
+		mce.Spec.DeploymentMode = v1.ModeDefault
+		installer, err := reconciler.componentReconciler(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(installer).To(BeIdenticalTo(reconciler))
+		Expect(reconciler.StatusManager.HostedClient).To(BeNil())
+	})
+
+	It("should fail without the kubeconfig secret", func() {
+		_, err := reconciler.componentReconciler(ctx, mce)
+		Expect(err).To(MatchError(ContainSubstring("failed to get hosted kubeconfig secret hosted-kubeconfig")))
+	})
+
+	It("should install on the hosted cluster without owner references", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "hosted-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{hostedKubeconfigKey: []byte(hostedKubeconfig)},
+		}
+		Expect(c.Create(ctx, secret)).To(Succeed())
+
+		reconciler.ApplyWorkers = 3
+		reconciler.Recorder = record.NewFakeRecorder(10)
+		reconciler.AuditSink = &audit.Sink{}
+		installer, err := reconciler.componentReconciler(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(installer.hosted).To(BeTrue())
+		Expect(installer.ApplyWorkers).To(Equal(3))
+		Expect(installer.Recorder).To(BeIdenticalTo(reconciler.Recorder))
+		Expect(installer.AuditSink).To(BeIdenticalTo(reconciler.AuditSink))
+		Expect(installer.Client).NotTo(BeIdenticalTo(reconciler.Client))
+		Expect(reconciler.StatusManager.HostedClient).To(BeIdenticalTo(installer.Client))
+
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "multicluster-engine"}}
+		Expect(installer.setOwner(mce, ns)).To(Succeed())
+		Expect(ns.GetOwnerReferences()).To(BeEmpty())
+		Expect(reconciler.setOwner(mce, ns)).To(Succeed())
+		Expect(metav1.IsControlledBy(ns, mce)).To(BeTrue())
+	})
+
+	It("should keep the hosted cluster of one MultiClusterEngine from the status of another", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "hosted-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{hostedKubeconfigKey: []byte(hostedKubeconfig)},
+		}
+		Expect(c.Create(ctx, secret)).To(Succeed())
+
+		hosted := reconciler.forInstance("mce-uid")
+		hosted.render.Images = map[string]string{"registration": "quay.io/hosted/registration:latest"}
+		_, err := hosted.componentReconciler(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(hosted.StatusManager.HostedClient).NotTo(BeNil())
+
+		local := reconciler.forInstance("other-uid")
+		Expect(local.StatusManager).NotTo(BeIdenticalTo(hosted.StatusManager))
+		Expect(local.StatusManager.HostedClient).To(BeNil())
+		Expect(local.render.Images).To(BeEmpty())
+		Expect(reconciler.forInstance("mce-uid").StatusManager).To(BeIdenticalTo(hosted.StatusManager))
+	})
+})
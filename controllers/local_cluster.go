@@ -0,0 +1,95 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/foundation"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var managedClusterGVK = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"}
+
+// ensureLocalCluster imports the hub as the local-cluster ManagedCluster when spec.localClusterEnabled is set, and
+// detaches it otherwise. The ManagedCluster is created if it is missing but not updated afterwards, as the labels
+// detected for the imported cluster replace the ones it is created with.
+func (r *MultiClusterEngineReconciler) ensureLocalCluster(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	if !backplaneConfig.Spec.LocalClusterEnabled {
+		removed, err := r.removeLocalCluster(ctx, backplaneConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !removed {
+			r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineProgressing, metav1.ConditionTrue, status.WaitingForResourceReason,
+				fmt.Sprintf("Waiting for %s to be detached", foundation.LocalClusterName)))
+			return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !foundation.CanManageClusters(ctx, r.Client) {
+		log.Info("ManagedCluster API is not installed. Waiting to import the local cluster.")
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(managedClusterGVK)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: foundation.LocalClusterName}, existing)
+	if err == nil {
+		return ctrl.Result{}, nil
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	localCluster, err := foundation.LocalCluster(backplaneConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setOwner(backplaneConfig, localCluster); err != nil {
+		return ctrl.Result{}, pkgerrors.Wrapf(err, "Error setting controller reference on resource %s", localCluster.GetName())
+	}
+	log.Info(fmt.Sprintf("Importing the hub as %s", foundation.LocalClusterName))
+	if err := r.Client.Create(ctx, localCluster); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, pkgerrors.Wrapf(err, "error creating ManagedCluster %s", localCluster.GetName())
+	}
+	return ctrl.Result{}, nil
+}
+
+// removeLocalCluster deletes the local-cluster ManagedCluster created by the operator and returns true once it is
+// gone. The registration and import controllers detach the cluster before its finalizers are removed, so they
+// must still be running. A local-cluster that was imported by other means is left alone.
+func (r *MultiClusterEngineReconciler) removeLocalCluster(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (bool, error) {
+	localCluster := &unstructured.Unstructured{}
+	localCluster.SetGroupVersionKind(managedClusterGVK)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: foundation.LocalClusterName}, localCluster)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if localCluster.GetLabels()["backplaneconfig.name"] != backplaneConfig.GetName() {
+		return true, nil
+	}
+	if localCluster.GetDeletionTimestamp() == nil {
+		log.FromContext(ctx).Info(fmt.Sprintf("Detaching %s", foundation.LocalClusterName))
+		if err := r.Client.Delete(ctx, localCluster); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,118 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/foundation"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Local cluster", func() {
+	var (
+		ctx               context.Context
+		s                 *runtime.Scheme
+		mce               *v1.MultiClusterEngine
+		managedClusterCRD *apixv1.CustomResourceDefinition
+	)
+
+	reconcilerWith := func(objs ...client.Object) *MultiClusterEngineReconciler {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+		return &MultiClusterEngineReconciler{Client: c, Scheme: s}
+	}
+
+	getLocalCluster := func(r *MultiClusterEngineReconciler) (*unstructured.Unstructured, error) {
+		mc := &unstructured.Unstructured{}
+		mc.SetGroupVersionKind(managedClusterGVK)
+		err := r.Client.Get(ctx, types.NamespacedName{Name: foundation.LocalClusterName}, mc)
+		return mc, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		Expect(apixv1.AddToScheme(s)).To(Succeed())
+
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "engine", UID: "engine-uid"},
+			Spec:       v1.MultiClusterEngineSpec{LocalClusterEnabled: true},
+		}
+		managedClusterCRD = &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "managedclusters.cluster.open-cluster-management.io"}}
+	})
+
+	It("should wait for the ManagedCluster API before importing the hub", func() {
+		r := reconcilerWith(mce)
+		result, err := r.ensureLocalCluster(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(result.RequeueAfter).To(Equal(requeuePeriod))
+	})
+
+	It("should import the hub as local-cluster", func() {
+		r := reconcilerWith(mce, managedClusterCRD)
+		_, err := r.ensureLocalCluster(ctx, mce)
+		Expect(err).To(Succeed())
+
+		mc, err := getLocalCluster(r)
+		Expect(err).To(Succeed())
+		Expect(mc.GetLabels()).To(HaveKeyWithValue(foundation.LocalClusterLabel, "true"))
+		Expect(mc.GetLabels()).To(HaveKeyWithValue("backplaneconfig.name", "engine"))
+		accepts, _, _ := unstructured.NestedBool(mc.Object, "spec", "hubAcceptsClient")
+		Expect(accepts).To(BeTrue())
+	})
+
+	It("should leave an existing local-cluster unchanged", func() {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(managedClusterGVK)
+		existing.SetName(foundation.LocalClusterName)
+		existing.SetLabels(map[string]string{"vendor": "OpenShift"})
+		r := reconcilerWith(mce, managedClusterCRD, existing)
+
+		_, err := r.ensureLocalCluster(ctx, mce)
+		Expect(err).To(Succeed())
+
+		mc, err := getLocalCluster(r)
+		Expect(err).To(Succeed())
+		Expect(mc.GetLabels()).To(Equal(map[string]string{"vendor": "OpenShift"}))
+	})
+
+	It("should detach the local-cluster created by the operator", func() {
+		r := reconcilerWith(mce, managedClusterCRD)
+		_, err := r.ensureLocalCluster(ctx, mce)
+		Expect(err).To(Succeed())
+
+		Eventually(func() bool {
+			removed, err := r.removeLocalCluster(ctx, mce)
+			Expect(err).To(Succeed())
+			return removed
+		}).Should(BeTrue())
+		_, err = getLocalCluster(r)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not detach a local-cluster imported by other means", func() {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(managedClusterGVK)
+		existing.SetName(foundation.LocalClusterName)
+		r := reconcilerWith(mce, managedClusterCRD, existing)
+
+		Expect(r.removeLocalCluster(ctx, mce)).To(BeTrue())
+		_, err := getLocalCluster(r)
+		Expect(err).To(Succeed())
+	})
+})
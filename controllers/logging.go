@@ -0,0 +1,32 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// withReconcileID returns a context whose logger tags every line with a new reconcile ID, so the lines of
+// concurrent reconciles can be told apart
+func withReconcileID(ctx context.Context) context.Context {
+	return log.IntoContext(ctx, log.FromContext(ctx).WithValues("reconcileID", string(uuid.NewUUID())))
+}
+
+// withResource returns a context whose logger tags every line with the component, kind and name of the resource
+// being applied
+func withResource(ctx context.Context, obj *unstructured.Unstructured) context.Context {
+	values := []interface{}{"gvk", obj.GroupVersionKind().String(), "resource", obj.GetName()}
+	if ns := obj.GetNamespace(); ns != "" {
+		values[3] = ns + "/" + obj.GetName()
+	}
+	if component := obj.GetLabels()[renderer.ComponentLabel]; component != "" {
+		values = append(values, "component", component)
+	}
+	return log.IntoContext(ctx, log.FromContext(ctx).WithValues(values...))
+}
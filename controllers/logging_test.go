@@ -0,0 +1,130 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var _ = Describe("Reconcile logging", func() {
+	It("should tag log lines with the reconcile and the resource being applied", func() {
+		buf := &bytes.Buffer{}
+		ctx := log.IntoContext(context.Background(), zap.New(zap.WriteTo(buf)))
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("apps/v1")
+		obj.SetKind("Deployment")
+		obj.SetName("ocm-controller")
+		obj.SetNamespace("multicluster-engine")
+		obj.SetLabels(map[string]string{renderer.ComponentLabel: "server-foundation"})
+
+		reconcileID := regexp.MustCompile(`"reconcileID":"([^"]+)"`)
+		log.FromContext(withResource(withReconcileID(ctx), obj)).Info("applying")
+		Expect(buf.String()).To(MatchRegexp(reconcileID.String()))
+		Expect(buf.String()).To(ContainSubstring(`"gvk":"apps/v1, Kind=Deployment"`))
+		Expect(buf.String()).To(ContainSubstring(`"resource":"multicluster-engine/ocm-controller"`))
+		Expect(buf.String()).To(ContainSubstring(`"component":"server-foundation"`))
+		first := reconcileID.FindStringSubmatch(buf.String())[1]
+
+		buf.Reset()
+		log.FromContext(withReconcileID(ctx)).Info("reconciling")
+		Expect(reconcileID.FindStringSubmatch(buf.String())).To(HaveLen(2))
+		Expect(reconcileID.FindStringSubmatch(buf.String())[1]).NotTo(Equal(first))
+	})
+})
+
+var _ = Describe("Operator log level", func() {
+	BeforeEach(func() {
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("POD_NAMESPACE")).To(Succeed())
+	})
+
+	newReconciler := func(objs ...client.Object) (*MultiClusterEngineReconciler, *uberzap.AtomicLevel) {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+		level := uberzap.NewAtomicLevelAt(zapcore.InfoLevel)
+		return &MultiClusterEngineReconciler{Client: c, LogLevel: &level, DefaultLogLevel: zapcore.InfoLevel}, &level
+	}
+
+	newMCE := func(name, logLevel string) *v1.MultiClusterEngine {
+		return &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.MultiClusterEngineSpec{LogLevel: logLevel}}
+	}
+
+	It("should follow the log level ConfigMap and fall back to the flag", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: utils.LogLevelConfigMap, Namespace: "default"},
+			Data:       map[string]string{utils.LogLevelKey: "debug"},
+		}
+		r, level := newReconciler(cm, newMCE("multiclusterengine", ""))
+
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.DebugLevel))
+
+		Expect(r.Client.Delete(context.Background(), cm)).To(Succeed())
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.InfoLevel))
+	})
+
+	It("should read the ConfigMap from the API server when the cache is label filtered", func() {
+		r, level := newReconciler(newMCE("multiclusterengine", ""))
+		r.LabelFilteredCache = true
+		r.APIReader = fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: utils.LogLevelConfigMap, Namespace: "default"},
+			Data:       map[string]string{utils.LogLevelKey: "debug"},
+		}).Build()
+
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.DebugLevel))
+	})
+
+	It("should prefer the MultiClusterEngine over the ConfigMap", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: utils.LogLevelConfigMap, Namespace: "default"},
+			Data:       map[string]string{utils.LogLevelKey: "debug"},
+		}
+		r, level := newReconciler(cm, newMCE("multiclusterengine", "error"))
+
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.ErrorLevel))
+	})
+
+	It("should use the most verbose level of all MultiClusterEngines", func() {
+		r, level := newReconciler(newMCE("local", "error"), newMCE("hosted", "debug"), newMCE("other", ""))
+
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.DebugLevel))
+
+		hosted := &v1.MultiClusterEngine{}
+		Expect(r.Client.Get(context.Background(), client.ObjectKey{Name: "hosted"}, hosted)).To(Succeed())
+		Expect(r.Client.Delete(context.Background(), hosted)).To(Succeed())
+		r.updateLogLevel(context.Background())
+		Expect(level.Level()).To(Equal(zapcore.ErrorLevel))
+	})
+})
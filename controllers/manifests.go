@@ -0,0 +1,109 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"sort"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/foundation"
+	"github.com/stolostron/backplane-operator/pkg/hive"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/toggle"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// componentCharts maps each toggleable component to the chart that deploys it
+var componentCharts = map[string]string{
+	backplanev1.ManagedServiceAccount: toggle.ManagedServiceAccountChartDir,
+	backplanev1.HyperShift:            toggle.HyperShiftChartDir,
+	backplanev1.ConsoleMCE:            toggle.ConsoleMCEChartsDir,
+	backplanev1.Discovery:             toggle.DiscoveryChartDir,
+	backplanev1.Hive:                  toggle.HiveChartDir,
+	backplanev1.AssistedService:       toggle.AssistedServiceChartDir,
+	backplanev1.ClusterLifecycle:      toggle.ClusterLifecycleChartDir,
+	backplanev1.ClusterManager:        toggle.ClusterManagerChartDir,
+	backplanev1.ServerFoundation:      toggle.ServerFoundationChartDir,
+	backplanev1.ClusterBackup:         toggle.ClusterBackupChartDir,
+	backplanev1.ClusterProxyAddon:     toggle.ClusterProxyAddonChartDir,
+}
+
+// componentNamespace returns the namespace the component is installed in
+func componentNamespace(backplaneConfig *backplanev1.MultiClusterEngine, component string) string {
+	if component == backplanev1.AssistedService && backplaneConfig.Spec.Overrides != nil &&
+		backplaneConfig.Spec.Overrides.InfrastructureCustomNamespace != "" {
+		return backplaneConfig.Spec.Overrides.InfrastructureCustomNamespace
+	}
+	return backplaneConfig.Spec.TargetNamespace
+}
+
+// RenderManifests renders the resources installed for the backplaneConfig without applying them: the operand
+// CRDs, the charts of the components that are always installed and of the enabled components, and the custom
+// resources created for them. The manifest overlay and patches are applied as they are on install. Resources
+// that fail to render are left out and their errors returned.
+func (r *MultiClusterEngineReconciler) RenderManifests(backplaneConfig *backplanev1.MultiClusterEngine, opts renderer.Options) ([]*unstructured.Unstructured, []error) {
+	manifests := []*unstructured.Unstructured{}
+	errs := []error{}
+	add := func(templates []*unstructured.Unstructured, renderErrs []error) {
+		manifests = append(manifests, templates...)
+		errs = append(errs, renderErrs...)
+	}
+
+	add(renderer.RenderCRDs(operandCRDsDir))
+	if backplaneConfig.Enabled(backplanev1.ManagedServiceAccount) {
+		add(renderer.RenderCRDs(toggle.ManagedServiceAccountCRDPath))
+	}
+	if backplaneConfig.Enabled(backplanev1.ClusterProxyAddon) {
+		add(renderer.RenderCRDs(toggle.ClusterProxyAddonCRDPath))
+	}
+	add(renderer.RenderCharts(renderer.AlwaysChartsDir, backplaneConfig, opts))
+
+	addons, err := foundation.GetAddons()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, addon := range addons {
+		addon.SetNamespace(backplaneConfig.Spec.TargetNamespace)
+		manifests = append(manifests, addon)
+	}
+
+	components := []string{}
+	for name := range componentCharts {
+		if backplaneConfig.Enabled(name) {
+			components = append(components, name)
+		}
+	}
+	sort.Strings(components)
+	for _, name := range components {
+		add(renderer.RenderChartWithNamespace(componentCharts[name], backplaneConfig, opts, componentNamespace(backplaneConfig, name)))
+		switch name {
+		case backplanev1.Hive:
+			manifests = append(manifests, hive.HiveConfig(backplaneConfig))
+		case backplanev1.ClusterManager:
+			manifests = append(manifests, foundation.ClusterManager(backplaneConfig, opts.Images))
+		}
+	}
+
+	patched := []*unstructured.Unstructured{}
+	for _, manifest := range manifests {
+		if err := renderer.ApplyArchitectureAffinity(manifest, r.imageArchitectures); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if r.Overlay != nil {
+			if err := r.Overlay.Apply(manifest); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if r.manifestPatches != nil {
+			if err := r.manifestPatches.Apply(manifest); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		patched = append(patched, manifest)
+	}
+	return patched, errs
+}
@@ -0,0 +1,28 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// reconcileTotal counts the reconciles of the multiclusterengine by outcome, so failing reconciles can be alerted
+// on without reading the logs
+var reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "mce_reconcile_total",
+	Help: "Number of MultiClusterEngine reconciles by result (success or error)",
+}, []string{"result"})
+
+func init() {
+	metrics.Registry.MustRegister(reconcileTotal)
+}
+
+// recordReconcile counts a finished reconcile
+func recordReconcile(err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileTotal.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,258 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultMetricsProxyImage is the kube-rbac-proxy image used when none is set
+	DefaultMetricsProxyImage = "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0"
+	// MetricsProxyUpstream is the address the metrics endpoint binds to when it is protected by the proxy, so it
+	// is only reachable from within the pod
+	MetricsProxyUpstream = "127.0.0.1:8080"
+
+	operatorDeploymentName   = "multicluster-engine-operator"
+	metricsProxyContainer    = "kube-rbac-proxy"
+	metricsProxyPort         = 8443
+	metricsServiceName       = "multicluster-engine-operator-metrics"
+	metricsTLSSecretName     = "multicluster-engine-operator-metrics-tls"
+	metricsReaderRoleName    = "multicluster-engine-operator-metrics-reader"
+	metricsMonitorNamespace  = "openshift-monitoring"
+	metricsMonitorPrometheus = "prometheus-k8s"
+)
+
+// MetricsProxy protects the metrics endpoint of the operator with kube-rbac-proxy, which only serves requests
+// authorized to get /metrics. It adds the proxy as a sidecar of the operator deployment and creates the service,
+// the ServiceMonitor scraping it and the role granting the cluster monitoring stack access. It is meant for
+// installs not managed by OLM, which would revert the change to the deployment.
+type MetricsProxy struct {
+	Client client.Client
+	// APIReader reads the operator deployment, which a label filtered cache does not hold. Defaults to Client.
+	APIReader client.Reader
+	// Namespace is the namespace of the operator deployment
+	Namespace string
+	// Image is the kube-rbac-proxy image
+	Image string
+}
+
+// Start applies the proxy resources, retrying until they are in place. It implements manager.Runnable.
+func (p *MetricsProxy) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("metrics-proxy")
+	backoff := wait.Backoff{Duration: time.Second, Factor: 2, Steps: 10, Cap: time.Minute}
+	for {
+		err := p.ensure(ctx)
+		if err == nil {
+			log.Info("Metrics endpoint protected by kube-rbac-proxy", "service", metricsServiceName)
+			return nil
+		}
+		log.Error(err, "Failed to set up the metrics proxy")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// NeedLeaderElection makes only the leader apply the proxy resources
+func (p *MetricsProxy) NeedLeaderElection() bool {
+	return true
+}
+
+// ensure creates or updates the proxy resources
+func (p *MetricsProxy) ensure(ctx context.Context) error {
+	deployment := &appsv1.Deployment{}
+	if err := p.apiReader().Get(ctx, types.NamespacedName{Name: operatorDeploymentName, Namespace: p.Namespace}, deployment); err != nil {
+		return fmt.Errorf("failed to get the operator deployment: %w", err)
+	}
+
+	objects := []client.Object{p.service(deployment), p.readerRole(), p.readerRoleBinding()}
+	for _, obj := range objects {
+		if err := p.apply(ctx, obj); err != nil {
+			return err
+		}
+	}
+	if err := p.apply(ctx, p.serviceMonitor(deployment)); meta.IsNoMatchError(err) {
+		log.FromContext(ctx).Info("ServiceMonitor CRD not found. Metrics are not scraped.")
+	} else if err != nil {
+		return err
+	}
+
+	// On OpenShift the service CA creates the serving certificate once the service exists. Until it does, the proxy
+	// serves a self-signed certificate. The sidecar is updated to serve the issued one when the operator restarts.
+	secret := &corev1.Secret{}
+	err := p.Client.Get(ctx, types.NamespacedName{Name: metricsTLSSecretName, Namespace: p.Namespace}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return p.ensureSidecar(ctx, deployment, err == nil)
+}
+
+// apply creates obj, or replaces the labels and spec of the existing resource
+func (p *MetricsProxy) apply(ctx context.Context, obj client.Object) error {
+	existing := obj.DeepCopyObject().(client.Object)
+	err := p.Client.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if apierrors.IsNotFound(err) {
+		return p.Client.Create(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return p.Client.Update(ctx, obj)
+}
+
+// apiReader returns APIReader, or Client if it is not set
+func (p *MetricsProxy) apiReader() client.Reader {
+	if p.APIReader != nil {
+		return p.APIReader
+	}
+	return p.Client
+}
+
+// ensureSidecar adds the proxy container to the operator deployment, or updates it. The deployment is only
+// updated when the container changes, as every update rolls out the operator.
+func (p *MetricsProxy) ensureSidecar(ctx context.Context, deployment *appsv1.Deployment, servingCert bool) error {
+	desired := p.container(servingCert)
+	containers := deployment.Spec.Template.Spec.Containers
+	found := false
+	for i := range containers {
+		if containers[i].Name != metricsProxyContainer {
+			continue
+		}
+		if equality.Semantic.DeepEqual(containers[i], desired) {
+			return nil
+		}
+		containers[i] = desired
+		found = true
+	}
+	if !found {
+		deployment.Spec.Template.Spec.Containers = append(containers, desired)
+	}
+
+	hasVolume := false
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		if v.Name == metricsTLSSecretName {
+			hasVolume = true
+		}
+	}
+	if !hasVolume {
+		optional := true
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: metricsTLSSecretName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: metricsTLSSecretName, Optional: &optional},
+			},
+		})
+	}
+	return p.Client.Update(ctx, deployment)
+}
+
+// container returns the proxy sidecar. It serves the certificate issued by the OpenShift service CA if there is
+// one, and a self-signed certificate otherwise.
+func (p *MetricsProxy) container(servingCert bool) corev1.Container {
+	allowPrivilegeEscalation := false
+	args := []string{
+		fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", metricsProxyPort),
+		fmt.Sprintf("--upstream=http://%s/", MetricsProxyUpstream),
+		"--logtostderr=true",
+	}
+	if servingCert {
+		args = append(args, "--tls-cert-file=/etc/tls/private/tls.crt", "--tls-private-key-file=/etc/tls/private/tls.key")
+	}
+	return corev1.Container{
+		Name:  metricsProxyContainer,
+		Image: p.Image,
+		Args:  args,
+		Ports: []corev1.ContainerPort{{Name: "https", ContainerPort: metricsProxyPort, Protocol: corev1.ProtocolTCP}},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: metricsTLSSecretName, MountPath: "/etc/tls/private", ReadOnly: true},
+		},
+		SecurityContext:          &corev1.SecurityContext{AllowPrivilegeEscalation: &allowPrivilegeEscalation},
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+	}
+}
+
+// service returns the service exposing the proxy. On OpenShift the service CA issues its serving certificate.
+func (p *MetricsProxy) service(deployment *appsv1.Deployment) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        metricsServiceName,
+			Namespace:   p.Namespace,
+			Labels:      deployment.Spec.Selector.MatchLabels,
+			Annotations: map[string]string{"service.beta.openshift.io/serving-cert-secret-name": metricsTLSSecretName},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: deployment.Spec.Selector.MatchLabels,
+			Ports: []corev1.ServicePort{{
+				Name:       "https",
+				Port:       metricsProxyPort,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromString("https"),
+			}},
+		},
+	}
+}
+
+// serviceMonitor returns the ServiceMonitor scraping the proxy with the token of the monitoring stack
+func (p *MetricsProxy) serviceMonitor(deployment *appsv1.Deployment) *monitorv1.ServiceMonitor {
+	return &monitorv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: metricsServiceName, Namespace: p.Namespace},
+		Spec: monitorv1.ServiceMonitorSpec{
+			Selector: metav1.LabelSelector{MatchLabels: deployment.Spec.Selector.MatchLabels},
+			Endpoints: []monitorv1.Endpoint{{
+				Port:            "https",
+				Path:            "/metrics",
+				Scheme:          "https",
+				BearerTokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+				TLSConfig: &monitorv1.TLSConfig{
+					CAFile: "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt",
+					SafeTLSConfig: monitorv1.SafeTLSConfig{
+						ServerName: fmt.Sprintf("%s.%s.svc", metricsServiceName, p.Namespace),
+					},
+				},
+			}},
+		},
+	}
+}
+
+// readerRole returns the role allowed to read the metrics through the proxy
+func (p *MetricsProxy) readerRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: metricsReaderRoleName},
+		Rules: []rbacv1.PolicyRule{
+			{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+		},
+	}
+}
+
+// readerRoleBinding grants the cluster monitoring stack the metrics reader role
+func (p *MetricsProxy) readerRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: metricsReaderRoleName},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: metricsReaderRoleName},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Name: metricsMonitorPrometheus, Namespace: metricsMonitorNamespace},
+		},
+	}
+}
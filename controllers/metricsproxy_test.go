@@ -0,0 +1,87 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metrics proxy", func() {
+	var (
+		ctx   context.Context
+		c     client.Client
+		proxy *MetricsProxy
+		key   types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(monitorv1.AddToScheme(s)).To(Succeed())
+
+		labels := map[string]string{"control-plane": "backplane-operator"}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: operatorDeploymentName, Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "backplane-operator", Image: "operator"}}},
+				},
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(deployment).Build()
+		proxy = &MetricsProxy{Client: c, Namespace: "default", Image: DefaultMetricsProxyImage}
+		key = types.NamespacedName{Name: operatorDeploymentName, Namespace: "default"}
+	})
+
+	It("should add the proxy sidecar and the resources exposing it", func() {
+		Expect(proxy.ensure(ctx)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(2))
+		Expect(deployment.Spec.Template.Spec.Containers[1].Name).To(Equal(metricsProxyContainer))
+		Expect(deployment.Spec.Template.Spec.Containers[1].Args).NotTo(ContainElement(HavePrefix("--tls-cert-file")))
+
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: metricsServiceName, Namespace: "default"}, service)).To(Succeed())
+		Expect(service.Spec.Selector).To(Equal(deployment.Spec.Selector.MatchLabels))
+		Expect(c.Get(ctx, types.NamespacedName{Name: metricsServiceName, Namespace: "default"}, &monitorv1.ServiceMonitor{})).To(Succeed())
+		Expect(c.Get(ctx, types.NamespacedName{Name: metricsReaderRoleName}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+		// The deployment is left alone once the sidecar is in place
+		resourceVersion := deployment.ResourceVersion
+		Expect(proxy.ensure(ctx)).To(Succeed())
+		Expect(c.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should serve the certificate issued by the service CA", func() {
+		Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: metricsTLSSecretName, Namespace: "default"},
+		})).To(Succeed())
+		Expect(proxy.ensure(ctx)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers[1].Args).To(ContainElement("--tls-cert-file=/etc/tls/private/tls.crt"))
+		Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", metricsTLSSecretName)))
+	})
+})
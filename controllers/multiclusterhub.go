@@ -0,0 +1,76 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// The MultiClusterHub labels the resources it installs, including the MultiClusterEngine, with its name and
+	// namespace
+	installerNameLabel      = "installer.name"
+	installerNamespaceLabel = "installer.namespace"
+)
+
+var multiClusterHubGVK = schema.GroupVersionKind{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "MultiClusterHub"}
+
+// findMultiClusterHub returns the MultiClusterHub the backplaneConfig shares the cluster with, as namespace/name,
+// or an empty string if there is none. The MultiClusterHub that installed the backplaneConfig is preferred over
+// one that was installed alongside a standalone MultiClusterEngine.
+func (r *MultiClusterEngineReconciler) findMultiClusterHub(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (string, error) {
+	labels := backplaneConfig.GetLabels()
+	if labels[installerNameLabel] != "" && labels[installerNamespaceLabel] != "" {
+		return labels[installerNamespaceLabel] + "/" + labels[installerNameLabel], nil
+	}
+
+	hubList := &unstructured.UnstructuredList{}
+	hubList.SetGroupVersionKind(multiClusterHubGVK.GroupVersion().WithKind(multiClusterHubGVK.Kind + "List"))
+	err := r.Client.List(ctx, hubList)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	for _, hub := range hubList.Items {
+		if hub.GetDeletionTimestamp() == nil {
+			return hub.GetNamespace() + "/" + hub.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// hubRunsComponent returns true if the deployment of the component was installed by the MultiClusterHub rather
+// than the backplaneConfig, as it is on a hub that was installed before the MultiClusterEngine. Such a component
+// is left to the MultiClusterHub until the deployment is annotated to let the operator adopt it.
+func (r *MultiClusterEngineReconciler) hubRunsComponent(ctx context.Context, component toggleableComponent) (bool, error) {
+	deployment := &appsv1.Deployment{}
+	err := r.unlabeledReader().Get(ctx, component.deployment, deployment)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if strings.EqualFold(deployment.GetAnnotations()[utils.AnnotationAdopt], "true") {
+		return false, nil
+	}
+	if _, ok := deployment.GetLabels()["backplaneconfig.name"]; ok {
+		return false, nil
+	}
+	if owner := metav1.GetControllerOf(deployment); owner != nil && owner.Kind == multiClusterHubGVK.Kind {
+		return true, nil
+	}
+	_, ok := deployment.GetLabels()[installerNameLabel]
+	return ok, nil
+}
@@ -0,0 +1,142 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("MultiClusterHub coexistence", func() {
+	var (
+		ctx       context.Context
+		s         *runtime.Scheme
+		mce       *v1.MultiClusterEngine
+		component toggleableComponent
+	)
+
+	reconcilerWith := func(objs ...client.Object) *MultiClusterEngineReconciler {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+		return &MultiClusterEngineReconciler{Client: c, Scheme: s}
+	}
+
+	hubDeployment := func(labels, annotations map[string]string, owners ...metav1.OwnerReference) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:            component.deployment.Name,
+			Namespace:       component.deployment.Namespace,
+			Labels:          labels,
+			Annotations:     annotations,
+			OwnerReferences: owners,
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce = &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "engine"}}
+		component = toggleableComponent{deployment: types.NamespacedName{Name: "hive-operator", Namespace: "open-cluster-management"}}
+	})
+
+	Context("when finding the MultiClusterHub", func() {
+		It("should prefer the MultiClusterHub that installed the MultiClusterEngine", func() {
+			mce.SetLabels(map[string]string{installerNameLabel: "multiclusterhub", installerNamespaceLabel: "open-cluster-management"})
+			r := reconcilerWith(mce)
+			Expect(r.findMultiClusterHub(ctx, mce)).To(Equal("open-cluster-management/multiclusterhub"))
+		})
+
+		It("should find a MultiClusterHub installed alongside the MultiClusterEngine", func() {
+			hub := &unstructured.Unstructured{}
+			hub.SetGroupVersionKind(multiClusterHubGVK)
+			hub.SetName("hub")
+			hub.SetNamespace("acm")
+			r := reconcilerWith(mce, hub)
+			Expect(r.findMultiClusterHub(ctx, mce)).To(Equal("acm/hub"))
+		})
+
+		It("should find nothing on a standalone MultiClusterEngine", func() {
+			r := reconcilerWith(mce)
+			Expect(r.findMultiClusterHub(ctx, mce)).To(BeEmpty())
+		})
+	})
+
+	Context("when the webhook is disabled", func() {
+		BeforeEach(func() {
+			mce.SetCreationTimestamp(metav1.Now())
+		})
+
+		It("should not install a second MultiClusterEngine", func() {
+			older := &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{
+				Name:              "older-engine",
+				CreationTimestamp: metav1.NewTime(mce.GetCreationTimestamp().Add(-time.Hour)),
+			}}
+			r := reconcilerWith(mce, older)
+			r.WebhookDisabled = true
+			Expect(r.olderBackplaneConfig(ctx, mce)).To(Equal("older-engine"))
+		})
+
+		It("should install alongside a MultiClusterHub and leave it the components it runs", func() {
+			hub := &unstructured.Unstructured{}
+			hub.SetGroupVersionKind(multiClusterHubGVK)
+			hub.SetName("hub")
+			hub.SetNamespace("acm")
+			r := reconcilerWith(mce, hub, hubDeployment(map[string]string{installerNameLabel: "hub"}, nil))
+			r.WebhookDisabled = true
+			Expect(r.olderBackplaneConfig(ctx, mce)).To(BeEmpty())
+			Expect(r.findMultiClusterHub(ctx, mce)).To(Equal("acm/hub"))
+			Expect(r.hubRunsComponent(ctx, component)).To(BeTrue())
+		})
+	})
+
+	Context("when checking who runs a component", func() {
+		hubOwner := metav1.OwnerReference{
+			APIVersion: "operator.open-cluster-management.io/v1",
+			Kind:       "MultiClusterHub",
+			Name:       "multiclusterhub",
+			UID:        "hub-uid",
+			Controller: func() *bool { b := true; return &b }(),
+		}
+
+		It("should leave a component controlled by the MultiClusterHub to it", func() {
+			r := reconcilerWith(hubDeployment(nil, nil, hubOwner))
+			Expect(r.hubRunsComponent(ctx, component)).To(BeTrue())
+		})
+
+		It("should leave a component labeled by the MultiClusterHub to it", func() {
+			r := reconcilerWith(hubDeployment(map[string]string{installerNameLabel: "multiclusterhub"}, nil))
+			Expect(r.hubRunsComponent(ctx, component)).To(BeTrue())
+		})
+
+		It("should run a component annotated for adoption", func() {
+			r := reconcilerWith(hubDeployment(nil, map[string]string{utils.AnnotationAdopt: "true"}, hubOwner))
+			Expect(r.hubRunsComponent(ctx, component)).To(BeFalse())
+		})
+
+		It("should run a component installed by the MultiClusterEngine", func() {
+			r := reconcilerWith(hubDeployment(map[string]string{"backplaneconfig.name": "engine", installerNameLabel: "multiclusterhub"}, nil))
+			Expect(r.hubRunsComponent(ctx, component)).To(BeFalse())
+		})
+
+		It("should run a component that is not installed", func() {
+			r := reconcilerWith()
+			Expect(r.hubRunsComponent(ctx, component)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,150 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	semver "github.com/Masterminds/semver"
+	configv1 "github.com/openshift/api/config/v1"
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/version"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// requiredClusterOperators must be available before the components are installed. The webhooks and API services
+// of the components depend on them.
+var requiredClusterOperators = []string{
+	"kube-apiserver",
+	"openshift-apiserver",
+	"service-ca",
+}
+
+// requiredAPIs must be served before the components are installed. The components register webhooks and API
+// services, and the operand CRDs are applied on install.
+var requiredAPIs = []schema.GroupVersionKind{
+	{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"},
+	{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
+	{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"},
+}
+
+// hubMinorVersionOffset is the difference between the minor version of a MultiClusterHub and the minor version of
+// the multiclusterengine it installs. The multiclusterengine was split out of Advanced Cluster Management 2.5 as
+// release 2.0, and every hub release since pairs with the multiclusterengine release 5 minors below it, e.g.
+// MultiClusterHub 2.6 installs multiclusterengine 2.1. A hub more than this many minors ahead is a newer release
+// than the one that installs the running operator.
+const hubMinorVersionOffset = 5
+
+// checkPrerequisites verifies the cluster meets the requirements to install or upgrade the multiclusterengine: a
+// supported OpenShift version, available cluster operators and APIs, and a MultiClusterHub, if any, that installs
+// this release. It returns a description of the first requirement that is not met, or an empty string if all are
+// met. errClusterVersionUnknown is returned while the cluster has yet to report its version.
+func (r *MultiClusterEngineReconciler) checkPrerequisites(ctx context.Context, mce *backplanev1.MultiClusterEngine) (string, error) {
+	// The checks read the cluster the operator runs on, while a hosted cluster is neither where the components
+	// are installed nor shared with a MultiClusterHub
+	if mce.IsHosted() {
+		return "", nil
+	}
+
+	clusterVersion, err := r.getClusterVersion(ctx, mce)
+	if err != nil {
+		return "", err
+	}
+	current, err := semver.NewVersion(clusterVersion)
+	if err != nil {
+		return fmt.Sprintf("Unable to parse OpenShift version %s", clusterVersion), nil
+	}
+	constraint, err := semver.NewConstraint(">= " + version.MinimumOCPVersion())
+	if err != nil {
+		return "", err
+	}
+	if !constraint.Check(current) {
+		return fmt.Sprintf("OpenShift %s is not supported. The minimum version is %s.", clusterVersion, version.MinimumOCPVersion()), nil
+	}
+
+	if val, ok := os.LookupEnv("UNIT_TEST"); ok && val == "true" {
+		// Cluster operators don't exist in unit tests
+		return "", nil
+	}
+
+	for _, name := range requiredClusterOperators {
+		co := &configv1.ClusterOperator{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: name}, co)
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("Cluster operator %s is not installed", name), nil
+		} else if err != nil {
+			return "", err
+		}
+		if !clusterOperatorAvailable(co) {
+			return fmt.Sprintf("Cluster operator %s is not available", name), nil
+		}
+	}
+
+	for _, gvk := range requiredAPIs {
+		_, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			return fmt.Sprintf("API %s %s is not available", gvk.GroupVersion(), gvk.Kind), nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	return r.checkHubVersion(ctx)
+}
+
+// checkHubVersion verifies a MultiClusterHub on the cluster is a release that installs this multiclusterengine. It
+// returns a description of the skew, or an empty string if there is none.
+func (r *MultiClusterEngineReconciler) checkHubVersion(ctx context.Context) (string, error) {
+	hubList := &unstructured.UnstructuredList{}
+	hubList.SetGroupVersionKind(schema.GroupVersionKind{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "MultiClusterHubList"})
+	err := r.Client.List(ctx, hubList)
+	if meta.IsNoMatchError(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	running := version.Get().GitVersion
+	for _, hub := range hubList.Items {
+		hubVersion, _, _ := unstructured.NestedString(hub.Object, "status", "currentVersion")
+		if hubVersionSkewed(hubVersion, running) {
+			return fmt.Sprintf("MultiClusterHub %s/%s version %s does not support multiclusterengine %s", hub.GetNamespace(), hub.GetName(), hubVersion, running), nil
+		}
+	}
+	return "", nil
+}
+
+// hubVersionSkewed returns true if a MultiClusterHub at hubVersion does not install the running multiclusterengine.
+// The hub may be one minor version behind, as it reports its previous version until its own upgrade completes.
+// Versions that cannot be parsed, such as those of development builds or a hub that is still installing, are never
+// considered skewed.
+func hubVersionSkewed(hubVersion, running string) bool {
+	hub, err := semver.NewVersion(hubVersion)
+	if err != nil {
+		return false
+	}
+	mce, err := semver.NewVersion(running)
+	if err != nil || mce.Major() == 0 {
+		return false
+	}
+
+	paired := mce.Minor() + hubMinorVersionOffset
+	return hub.Major() != mce.Major() || hub.Minor() > paired || hub.Minor()+1 < paired
+}
+
+// clusterOperatorAvailable returns true if the cluster operator reports the Available condition
+func clusterOperatorAvailable(co *configv1.ClusterOperator) bool {
+	for _, c := range co.Status.Conditions {
+		if c.Type == configv1.OperatorAvailable {
+			return c.Status == configv1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Cluster prerequisites", func() {
+	var (
+		ctx context.Context
+		s   *runtime.Scheme
+		mce *v1.MultiClusterEngine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(configv1.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		hubGV := schema.GroupVersion{Group: "operator.open-cluster-management.io", Version: "v1"}
+		s.AddKnownTypeWithName(hubGV.WithKind("MultiClusterHub"), &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(hubGV.WithKind("MultiClusterHubList"), &unstructured.UnstructuredList{})
+		mce = &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"}}
+	})
+
+	clusterVersion := func(version string) *configv1.ClusterVersion {
+		return &configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status: configv1.ClusterVersionStatus{
+				History: []configv1.UpdateHistory{{Version: version}},
+			},
+		}
+	}
+
+	clusterOperator := func(name string, available configv1.ConditionStatus) *configv1.ClusterOperator {
+		return &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorAvailable, Status: available},
+				},
+			},
+		}
+	}
+
+	// servedAPIs returns a RESTMapper serving the required APIs, except those skipped
+	servedAPIs := func(skip ...string) meta.RESTMapper {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		for _, gvk := range requiredAPIs {
+			skipped := false
+			for _, kind := range skip {
+				skipped = skipped || gvk.Kind == kind
+			}
+			if !skipped {
+				mapper.Add(gvk, meta.RESTScopeRoot)
+			}
+		}
+		return mapper
+	}
+
+	reconcilerWithAPIs := func(mapper meta.RESTMapper, objs ...client.Object) *MultiClusterEngineReconciler {
+		c := fake.NewClientBuilder().WithScheme(s).WithRESTMapper(mapper).WithObjects(objs...).Build()
+		return &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+	}
+
+	reconcilerWith := func(objs ...client.Object) *MultiClusterEngineReconciler {
+		return reconcilerWithAPIs(servedAPIs(), objs...)
+	}
+
+	availableClusterOperators := func() []client.Object {
+		return []client.Object{
+			clusterOperator("kube-apiserver", configv1.ConditionTrue),
+			clusterOperator("openshift-apiserver", configv1.ConditionTrue),
+			clusterOperator("service-ca", configv1.ConditionTrue),
+		}
+	}
+
+	It("should be met on a supported cluster", func() {
+		reconciler := reconcilerWith(append(availableClusterOperators(), clusterVersion("4.10.3"))...)
+		unmet, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(unmet).To(BeEmpty())
+	})
+
+	It("should name an API that is not served", func() {
+		reconciler := reconcilerWithAPIs(servedAPIs("APIService"), append(availableClusterOperators(), clusterVersion("4.10.3"))...)
+		unmet, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(unmet).To(Equal("API apiregistration.k8s.io/v1 APIService is not available"))
+	})
+
+	It("should name an OpenShift version that is too old", func() {
+		reconciler := reconcilerWith(clusterVersion("4.7.0"))
+		unmet, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(unmet).To(ContainSubstring("OpenShift 4.7.0 is not supported"))
+	})
+
+	It("should name a cluster operator that is not available", func() {
+		reconciler := reconcilerWith(
+			clusterVersion("4.10.3"),
+			clusterOperator("kube-apiserver", configv1.ConditionTrue),
+			clusterOperator("openshift-apiserver", configv1.ConditionFalse),
+			clusterOperator("service-ca", configv1.ConditionTrue),
+		)
+		unmet, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(unmet).To(Equal("Cluster operator openshift-apiserver is not available"))
+	})
+
+	It("should wait for the OpenShift version to be known", func() {
+		reconciler := reconcilerWith(append(availableClusterOperators(), &configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{Name: "version"}})...)
+		_, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(MatchError(errClusterVersionUnknown))
+	})
+
+	It("should not check the local cluster for a hosted MultiClusterEngine", func() {
+		mce.Spec.DeploymentMode = v1.ModeHosted
+		reconciler := reconcilerWithAPIs(servedAPIs("APIService"), clusterVersion("4.7.0"))
+		unmet, err := reconciler.checkPrerequisites(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(unmet).To(BeEmpty())
+	})
+
+	It("should hold back an upgrade while prerequisites are not met", func() {
+		mce.Status.CurrentVersion = "v0.0.0"
+		reconciler := reconcilerWith(mce, clusterVersion("4.7.0"))
+
+		result, err := reconciler.reconcileInstance(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(result.RequeueAfter).To(Equal(requeuePeriod))
+
+		updated := &v1.MultiClusterEngine{}
+		Expect(reconciler.Client.Get(ctx, client.ObjectKeyFromObject(mce), updated)).To(Succeed())
+		Expect(updated.Status.Phase).To(Equal(v1.MultiClusterEnginePhaseFailed))
+		Expect(updated.Status.CurrentVersion).To(Equal("v0.0.0"))
+		Expect(updated.Spec.TargetNamespace).To(BeEmpty(), "defaults are not applied while the upgrade is held back")
+	})
+
+	DescribeTable("MultiClusterHub version skew",
+		func(hubVersion, running string, skewed bool) {
+			Expect(hubVersionSkewed(hubVersion, running)).To(Equal(skewed))
+		},
+		Entry("paired release", "2.5.0", "2.0.0", false),
+		Entry("hub still upgrading", "2.4.3", "2.0.0", false),
+		Entry("hub too old", "2.3.0", "2.0.0", true),
+		Entry("hub too new", "2.6.0", "2.0.0", true),
+		Entry("hub installing", "", "2.0.0", false),
+		Entry("development build", "2.3.0", "v0.0.1-alpha.0", false),
+		Entry("hub 5 minors ahead", "2.6.0", "2.1.0", false),
+		Entry("hub 6 minors ahead", "2.7.0", "2.1.0", true),
+	)
+})
@@ -0,0 +1,52 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterProxyName is the name of the cluster-wide Proxy resource
+const clusterProxyName = "cluster"
+
+// clusterProxy returns the settings of the cluster-wide Proxy resource for rendering. They are only used when
+// the operator itself runs without proxy variables. On a cluster without the Proxy resource they are empty.
+func (r *MultiClusterEngineReconciler) clusterProxy(ctx context.Context) (map[string]string, error) {
+	proxy := &configv1.Proxy{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: clusterProxyName}, proxy)
+	if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	return map[string]string{
+		"HTTP_PROXY":  proxy.Status.HTTPProxy,
+		"HTTPS_PROXY": proxy.Status.HTTPSProxy,
+		"NO_PROXY":    proxy.Status.NoProxy,
+	}, nil
+}
+
+// clusterProxyRequests returns a request for every MultiClusterEngine when the cluster-wide Proxy resource
+// changes, so the new settings are rolled out to the components
+func (r *MultiClusterEngineReconciler) clusterProxyRequests(obj client.Object) []reconcile.Request {
+	if obj.GetName() != clusterProxyName {
+		return nil
+	}
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(context.TODO(), mceList); err != nil {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, mce := range mceList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: mce.GetName()}})
+	}
+	return requests
+}
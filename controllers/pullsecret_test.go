@@ -0,0 +1,91 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Image pull secret", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+		copyKey    = types.NamespacedName{Name: "pull-secret", Namespace: "multicluster-engine"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "mce-uid"},
+			Spec: v1.MultiClusterEngineSpec{
+				TargetNamespace: "multicluster-engine",
+				ImagePullSecret: "pull-secret",
+			},
+		}
+		source := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "default"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(mce, source).Build()
+		reconciler = &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("POD_NAMESPACE")).To(Succeed())
+	})
+
+	It("should be copied to the target namespace and kept in sync", func() {
+		Expect(reconciler.ensurePullSecret(ctx, mce)).To(Succeed())
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, copyKey, secret)).To(Succeed())
+		Expect(secret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+		Expect(metav1.IsControlledBy(secret, mce)).To(BeTrue())
+
+		By("updating the source secret")
+		source := &corev1.Secret{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "pull-secret", Namespace: "default"}, source)).To(Succeed())
+		source.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{}}`)
+		Expect(c.Update(ctx, source)).To(Succeed())
+		Expect(reconciler.ensurePullSecret(ctx, mce)).To(Succeed())
+
+		Expect(c.Get(ctx, copyKey, secret)).To(Succeed())
+		Expect(secret.Data[corev1.DockerConfigJsonKey]).To(Equal([]byte(`{"auths":{}}`)))
+	})
+
+	It("should not overwrite a secret created in the target namespace", func() {
+		own := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: copyKey.Name, Namespace: copyKey.Namespace},
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("mine")},
+		}
+		Expect(c.Create(ctx, own)).To(Succeed())
+		Expect(reconciler.ensurePullSecret(ctx, mce)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, copyKey, secret)).To(Succeed())
+		Expect(secret.Data[corev1.DockerConfigJsonKey]).To(Equal([]byte("mine")))
+	})
+})
@@ -16,13 +16,14 @@ import (
 	"github.com/stolostron/backplane-operator/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -33,7 +34,7 @@ func (r *MultiClusterEngineReconciler) ensureConsoleMCE(ctx context.Context, bac
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.ConsoleMCEChartsDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ConsoleMCEChartsDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -76,7 +77,7 @@ func (r *MultiClusterEngineReconciler) ensureNoConsoleMCE(ctx context.Context, b
 	}
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.ConsoleMCEChartsDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ConsoleMCEChartsDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -125,7 +126,7 @@ func (r *MultiClusterEngineReconciler) ensureManagedServiceAccount(ctx context.C
 
 		// Renders all templates from charts
 		chartPath := toggle.ManagedServiceAccountChartDir
-		templates, errs := renderer.RenderChart(chartPath, backplaneConfig, r.Images)
+		templates, errs := renderer.RenderChart(chartPath, backplaneConfig, r.render)
 		if len(errs) > 0 {
 			for _, err := range errs {
 				log.Info(err.Error())
@@ -149,7 +150,7 @@ func (r *MultiClusterEngineReconciler) ensureNoManagedServiceAccount(ctx context
 
 	// Renders all templates from charts
 	chartPath := toggle.ManagedServiceAccountChartDir
-	templates, errs := renderer.RenderChart(chartPath, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(chartPath, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -194,6 +195,94 @@ func (r *MultiClusterEngineReconciler) ensureNoManagedServiceAccount(ctx context
 	return ctrl.Result{}, nil
 }
 
+func (r *MultiClusterEngineReconciler) ensureClusterProxyAddon(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	namespacedName := types.NamespacedName{Name: "cluster-proxy-addon-manager", Namespace: backplaneConfig.Spec.TargetNamespace}
+	r.StatusManager.RemoveComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
+	r.StatusManager.AddComponent(toggle.EnabledStatus(namespacedName))
+
+	log := log.FromContext(ctx)
+
+	// The addon manager registers a ClusterManagementAddOn, so it waits on the addon APIs of the cluster manager
+	if !foundation.CanInstallAddons(ctx, r.Client) {
+		log.Info("Addon APIs are not yet available. Waiting to install the cluster proxy addon")
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	// Apply the CRDs first, as the chart creates the ManagedProxyConfiguration
+	crds, errs := renderer.RenderCRDs(toggle.ClusterProxyAddonCRDPath)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+	for _, crd := range crds {
+		result, err := r.applyTemplate(ctx, backplaneConfig, crd)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	templates, errs := renderer.RenderChart(toggle.ClusterProxyAddonChartDir, backplaneConfig, r.render)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	// Applies all templates
+	for _, template := range templates {
+		result, err := r.applyTemplate(ctx, backplaneConfig, template)
+		if err != nil {
+			return result, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *MultiClusterEngineReconciler) ensureNoClusterProxyAddon(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	namespacedName := types.NamespacedName{Name: "cluster-proxy-addon-manager", Namespace: backplaneConfig.Spec.TargetNamespace}
+
+	// Renders all templates from charts
+	templates, errs := renderer.RenderChart(toggle.ClusterProxyAddonChartDir, backplaneConfig, r.render)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	r.StatusManager.RemoveComponent(toggle.EnabledStatus(namespacedName))
+	r.StatusManager.AddComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
+
+	// Deletes all templates, including the ManagedProxyConfiguration before its CRD
+	for _, template := range templates {
+		result, err := r.deleteTemplate(ctx, backplaneConfig, template)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete cluster proxy addon template: %s", template.GetName()))
+			return result, err
+		}
+	}
+
+	crds, errs := renderer.RenderCRDs(toggle.ClusterProxyAddonCRDPath)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+	for _, crd := range crds {
+		result, err := r.deleteTemplate(ctx, backplaneConfig, crd)
+		if err != nil {
+			log.Error(err, "Failed to delete CRD")
+			return result, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
 // addPluginToConsoleResource ...
 func (r *MultiClusterEngineReconciler) addPluginToConsoleResource(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
@@ -237,6 +326,10 @@ func (r *MultiClusterEngineReconciler) removePluginFromConsoleResource(ctx conte
 	// If trying to check this resource from the CLI run - `oc get consoles.operator.openshift.io cluster`.
 	// The default `console` is not the correct resource
 	err := r.Client.Get(ctx, types.NamespacedName{Name: "cluster"}, console)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		// Without a console there is no plugin to remove
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		log.Info("Failed to find console: cluster")
 		return ctrl.Result{Requeue: true}, err
@@ -262,6 +355,28 @@ func (r *MultiClusterEngineReconciler) removePluginFromConsoleResource(ctx conte
 	return ctrl.Result{}, nil
 }
 
+// consoleSupported returns true if the OpenShift console is installed, so the MCE console plugin can be
+// registered with it. The console is an optional capability that may be disabled on the cluster, in which case
+// neither the ConsolePlugin API nor the console configuration exist.
+func (r *MultiClusterEngineReconciler) consoleSupported(ctx context.Context) (bool, error) {
+	crd := &apixv1.CustomResourceDefinition{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: consolePluginCRDName}, crd)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	console := &operatorv1.Console{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: "cluster"}, console)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (r *MultiClusterEngineReconciler) ensureDiscovery(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
 	namespacedName := types.NamespacedName{Name: "discovery-operator", Namespace: backplaneConfig.Spec.TargetNamespace}
 	r.StatusManager.RemoveComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
@@ -269,7 +384,7 @@ func (r *MultiClusterEngineReconciler) ensureDiscovery(ctx context.Context, back
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.DiscoveryChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.DiscoveryChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -293,7 +408,7 @@ func (r *MultiClusterEngineReconciler) ensureNoDiscovery(ctx context.Context, ba
 	namespacedName := types.NamespacedName{Name: "discovery-operator", Namespace: backplaneConfig.Spec.TargetNamespace}
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.DiscoveryChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.DiscoveryChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -322,7 +437,7 @@ func (r *MultiClusterEngineReconciler) ensureHive(ctx context.Context, backplane
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.HiveChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.HiveChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -339,7 +454,7 @@ func (r *MultiClusterEngineReconciler) ensureHive(ctx context.Context, backplane
 	}
 
 	hiveTemplate := hive.HiveConfig(backplaneConfig)
-	if err := ctrl.SetControllerReference(backplaneConfig, hiveTemplate, r.Scheme); err != nil {
+	if err := r.setOwner(backplaneConfig, hiveTemplate); err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "Error setting controller reference on resource %s", hiveTemplate.GetName())
 	}
 
@@ -356,7 +471,7 @@ func (r *MultiClusterEngineReconciler) ensureNoHive(ctx context.Context, backpla
 	namespacedName := types.NamespacedName{Name: "hive-operator", Namespace: backplaneConfig.Spec.TargetNamespace}
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.HiveChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.HiveChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -375,7 +490,7 @@ func (r *MultiClusterEngineReconciler) ensureNoHive(ctx context.Context, backpla
 		if err != nil {
 			return ctrl.Result{RequeueAfter: requeuePeriod}, err
 		}
-	} else if err != nil && !apierrors.IsNotFound(err) {
+	} else if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
 	}
 
@@ -402,7 +517,7 @@ func (r *MultiClusterEngineReconciler) ensureAssistedService(ctx context.Context
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChartWithNamespace(toggle.AssistedServiceChartDir, backplaneConfig, r.Images, targetNamespace)
+	templates, errs := renderer.RenderChartWithNamespace(toggle.AssistedServiceChartDir, backplaneConfig, r.render, targetNamespace)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -431,7 +546,7 @@ func (r *MultiClusterEngineReconciler) ensureNoAssistedService(ctx context.Conte
 	log := log.FromContext(ctx)
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChartWithNamespace(toggle.AssistedServiceChartDir, backplaneConfig, r.Images, targetNamespace)
+	templates, errs := renderer.RenderChartWithNamespace(toggle.AssistedServiceChartDir, backplaneConfig, r.render, targetNamespace)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -466,7 +581,7 @@ func (r *MultiClusterEngineReconciler) ensureServerFoundation(ctx context.Contex
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.ServerFoundationChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ServerFoundationChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -489,7 +604,7 @@ func (r *MultiClusterEngineReconciler) ensureNoServerFoundation(ctx context.Cont
 	log := log.FromContext(ctx)
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.ServerFoundationChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ServerFoundationChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -534,7 +649,7 @@ func (r *MultiClusterEngineReconciler) ensureClusterLifecycle(ctx context.Contex
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.ClusterLifecycleChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ClusterLifecycleChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -557,7 +672,7 @@ func (r *MultiClusterEngineReconciler) ensureNoClusterLifecycle(ctx context.Cont
 	log := log.FromContext(ctx)
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.ClusterLifecycleChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ClusterLifecycleChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -596,7 +711,7 @@ func (r *MultiClusterEngineReconciler) ensureClusterManager(ctx context.Context,
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.ClusterManagerChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ClusterManagerChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -613,12 +728,11 @@ func (r *MultiClusterEngineReconciler) ensureClusterManager(ctx context.Context,
 	}
 
 	// Apply clustermanager
-	cmTemplate := foundation.ClusterManager(backplaneConfig, r.Images)
-	if err := ctrl.SetControllerReference(backplaneConfig, cmTemplate, r.Scheme); err != nil {
+	cmTemplate := foundation.ClusterManager(backplaneConfig, r.render.Images)
+	if err := r.setOwner(backplaneConfig, cmTemplate); err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "Error setting controller reference on resource %s", cmTemplate.GetName())
 	}
-	force := true
-	err := r.Client.Patch(ctx, cmTemplate, client.Apply, &client.PatchOptions{Force: &force, FieldManager: "backplane-operator"})
+	err := r.applyResource(ctx, backplaneConfig, cmTemplate)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "error applying object Name: %s Kind: %s", cmTemplate.GetName(), cmTemplate.GetKind())
 	}
@@ -631,7 +745,7 @@ func (r *MultiClusterEngineReconciler) ensureNoClusterManager(ctx context.Contex
 	namespacedName := types.NamespacedName{Name: "cluster-manager", Namespace: backplaneConfig.Spec.TargetNamespace}
 
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.ClusterManagerChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.ClusterManagerChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -660,7 +774,7 @@ func (r *MultiClusterEngineReconciler) ensureNoClusterManager(ctx context.Contex
 		if err != nil {
 			return ctrl.Result{RequeueAfter: requeuePeriod}, err
 		}
-	} else if err != nil && !apierrors.IsNotFound(err) { // Return error, if error is not not found error
+	} else if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) { // Return error, if error is not not found error
 		return ctrl.Result{RequeueAfter: requeuePeriod}, err
 	}
 
@@ -695,7 +809,7 @@ func (r *MultiClusterEngineReconciler) ensureHyperShift(ctx context.Context, bac
 
 	log := log.FromContext(ctx)
 
-	templates, errs := renderer.RenderChart(toggle.HyperShiftChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.HyperShiftChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -703,14 +817,23 @@ func (r *MultiClusterEngineReconciler) ensureHyperShift(ctx context.Context, bac
 		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
 	}
 
-	// Applies all templates
+	// Applies all templates. The ClusterManagementAddOn registering the addon waits on the addon APIs of the
+	// cluster manager, while the rest of the component is installed right away.
+	canInstallAddons := foundation.CanInstallAddons(ctx, r.Client)
 	for _, template := range templates {
+		if template.GetKind() == foundation.ClusterManagementAddonKind && !canInstallAddons {
+			continue
+		}
 		result, err := r.applyTemplate(ctx, backplaneConfig, template)
 		if err != nil {
 			return result, err
 		}
 	}
 
+	if !canInstallAddons {
+		log.Info("Addon APIs are not yet available. Waiting to register the hypershift addon")
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -723,7 +846,57 @@ func (r *MultiClusterEngineReconciler) ensureNoHyperShift(ctx context.Context, b
 	r.StatusManager.RemoveComponent(toggle.EnabledStatus(namespacedName))
 	r.StatusManager.AddComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
 	// Renders all templates from charts
-	templates, errs := renderer.RenderChart(toggle.HyperShiftChartDir, backplaneConfig, r.Images)
+	templates, errs := renderer.RenderChart(toggle.HyperShiftChartDir, backplaneConfig, r.render)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	// Deletes all templates
+	for _, template := range templates {
+		result, err := r.deleteTemplate(ctx, backplaneConfig, template)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete template: %s", template.GetName()))
+			return result, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *MultiClusterEngineReconciler) ensureClusterBackup(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	namespacedName := types.NamespacedName{Name: "cluster-backup-controller", Namespace: backplaneConfig.Spec.TargetNamespace}
+	r.StatusManager.RemoveComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
+	r.StatusManager.AddComponent(toggle.EnabledStatus(namespacedName))
+
+	log := log.FromContext(ctx)
+
+	templates, errs := renderer.RenderChart(toggle.ClusterBackupChartDir, backplaneConfig, r.render)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			log.Info(err.Error())
+		}
+		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
+	}
+
+	// Applies all templates
+	for _, template := range templates {
+		result, err := r.applyTemplate(ctx, backplaneConfig, template)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *MultiClusterEngineReconciler) ensureNoClusterBackup(ctx context.Context, backplaneConfig *backplanev1.MultiClusterEngine) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	namespacedName := types.NamespacedName{Name: "cluster-backup-controller", Namespace: backplaneConfig.Spec.TargetNamespace}
+
+	// Renders all templates from charts
+	templates, errs := renderer.RenderChart(toggle.ClusterBackupChartDir, backplaneConfig, r.render)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -731,6 +904,9 @@ func (r *MultiClusterEngineReconciler) ensureNoHyperShift(ctx context.Context, b
 		return ctrl.Result{RequeueAfter: requeuePeriod}, nil
 	}
 
+	r.StatusManager.RemoveComponent(toggle.EnabledStatus(namespacedName))
+	r.StatusManager.AddComponent(toggle.DisabledStatus(namespacedName, []*unstructured.Unstructured{}))
+
 	// Deletes all templates
 	for _, template := range templates {
 		result, err := r.deleteTemplate(ctx, backplaneConfig, template)
@@ -0,0 +1,120 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Uninstalling a MultiClusterEngine", func() {
+	It("should remove its resources and list those still present", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce := &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"}}
+		labels := map[string]string{"backplaneconfig.name": "multiclusterengine"}
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "discovery-operator", Namespace: "multicluster-engine", Labels: labels}}
+		clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "discovery-operator", Labels: labels}}
+		unrelated := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "multicluster-engine"}}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(deployment, clusterRole, unrelated).Build()
+
+		reconciler := &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+
+		By("deleting the labeled resources")
+		remaining, err := reconciler.removeOwnedResources(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(remaining).To(ConsistOf(
+			v1.BlockingResource{Kind: "Deployment", Namespace: "multicluster-engine", Name: "discovery-operator"},
+			v1.BlockingResource{Kind: "ClusterRole", Name: "discovery-operator"},
+		))
+
+		By("reporting nothing once they are gone")
+		remaining, err = reconciler.removeOwnedResources(ctx, mce)
+		Expect(err).To(Succeed())
+		Expect(remaining).To(BeEmpty())
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: "unrelated", Namespace: "multicluster-engine"}, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should remove the operand CRDs under the Delete uninstall policy", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(apixv1.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		operandCRD := &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "clusterclaims.hive.openshift.io"}}
+		unrelatedCRD := &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"}}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(operandCRD, unrelatedCRD).Build()
+
+		reconciler := &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+
+		By("deleting the operand CRDs")
+		remaining, err := reconciler.removeOperandCRDs(ctx)
+		Expect(err).To(Succeed())
+		Expect(remaining).To(ConsistOf(
+			v1.BlockingResource{Kind: "CustomResourceDefinition", Name: "clusterclaims.hive.openshift.io"},
+		))
+
+		By("reporting nothing once they are gone")
+		remaining, err = reconciler.removeOperandCRDs(ctx)
+		Expect(err).To(Succeed())
+		Expect(remaining).To(BeEmpty())
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: "widgets.example.com"}, &apixv1.CustomResourceDefinition{})).To(Succeed())
+	})
+})
+
+var _ = Describe("Removing stale component resources", func() {
+	It("should remove the resources of disabled and removed components", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		mce := &v1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"}}
+		deployment := func(name, component string) *appsv1.Deployment {
+			labels := map[string]string{"backplaneconfig.name": "multiclusterengine"}
+			if component != "" {
+				labels[renderer.ComponentLabel] = component
+			}
+			return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "multicluster-engine", Labels: labels}}
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			deployment("hive-operator", v1.Hive),
+			deployment("discovery-operator", v1.Discovery),
+			deployment("retired-operator", "retired-component"),
+			deployment("unlabeled", ""),
+		).Build()
+
+		reconciler := &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+		Expect(reconciler.removeStaleResources(ctx, mce, []string{v1.Hive})).To(Succeed())
+
+		exists := func(name string) error {
+			return c.Get(ctx, types.NamespacedName{Name: name, Namespace: "multicluster-engine"}, &appsv1.Deployment{})
+		}
+		Expect(exists("hive-operator")).To(Succeed())
+		Expect(exists("unlabeled")).To(Succeed())
+		Expect(apierrors.IsNotFound(exists("discovery-operator"))).To(BeTrue())
+		Expect(apierrors.IsNotFound(exists("retired-operator"))).To(BeTrue())
+	})
+})
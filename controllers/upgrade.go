@@ -0,0 +1,15 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/version"
+)
+
+// isUpgrading returns true if the backplaneConfig was installed by another version of the operator and has not
+// yet been brought to the Available phase by the running one
+func isUpgrading(backplaneConfig *backplanev1.MultiClusterEngine) bool {
+	current := backplaneConfig.Status.CurrentVersion
+	return current != "" && current != version.Get().GitVersion
+}
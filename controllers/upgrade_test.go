@@ -0,0 +1,122 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/version"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Component upgrade", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *MultiClusterEngineReconciler
+		mce        *v1.MultiClusterEngine
+		ensured    []string
+		components map[string]toggleableComponent
+	)
+
+	deployment := func(name string, available corev1.ConditionStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "multicluster-engine"},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: available}},
+			},
+		}
+	}
+
+	component := func(name, deploymentName string) toggleableComponent {
+		return toggleableComponent{
+			ensure: func(context.Context, *v1.MultiClusterEngine) (ctrl.Result, error) {
+				ensured = append(ensured, name)
+				return ctrl.Result{}, nil
+			},
+			ensureNo: func(context.Context, *v1.MultiClusterEngine) (ctrl.Result, error) {
+				return ctrl.Result{}, nil
+			},
+			deployment: types.NamespacedName{Name: deploymentName, Namespace: "multicluster-engine"},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ensured = []string{}
+		mce = &v1.MultiClusterEngine{
+			ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine"},
+			Spec: v1.MultiClusterEngineSpec{
+				TargetNamespace: "multicluster-engine",
+				Overrides: &v1.Overrides{
+					Components: []v1.ComponentConfig{
+						{Name: v1.Hive, Enabled: true},
+						{Name: v1.AssistedService, Enabled: true},
+					},
+				},
+			},
+			Status: v1.MultiClusterEngineStatus{CurrentVersion: "v0.0.0"},
+		}
+		components = map[string]toggleableComponent{
+			v1.Hive:            component(v1.Hive, "hive-operator"),
+			v1.AssistedService: component(v1.AssistedService, "infrastructure-operator"),
+		}
+	})
+
+	newReconciler := func(objs ...client.Object) {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+		reconciler = &MultiClusterEngineReconciler{Client: c, Scheme: s, StatusManager: &status.StatusTracker{Client: c}}
+	}
+
+	It("should upgrade the next component only once the previous one is available", func() {
+		newReconciler(deployment("hive-operator", corev1.ConditionFalse), deployment("infrastructure-operator", corev1.ConditionTrue))
+		result, err := reconciler.ensureComponentsInOrder(ctx, mce, components)
+		Expect(err).To(Succeed())
+		Expect(result.RequeueAfter).To(Equal(requeuePeriod))
+		Expect(ensured).To(Equal([]string{v1.Hive}))
+		Expect(reconciler.StatusManager.Conditions).To(ContainElement(And(
+			HaveField("Type", v1.MultiClusterEngineComponentsUpgrading),
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Message", ContainSubstring("Waiting for hive")),
+		)))
+	})
+
+	It("should report the upgrade complete once every component is available", func() {
+		newReconciler(deployment("hive-operator", corev1.ConditionTrue), deployment("infrastructure-operator", corev1.ConditionTrue))
+		result, err := reconciler.ensureComponentsInOrder(ctx, mce, components)
+		Expect(err).To(Succeed())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(ensured).To(Equal([]string{v1.Hive, v1.AssistedService}))
+		Expect(reconciler.StatusManager.Conditions).To(ContainElement(And(
+			HaveField("Type", v1.MultiClusterEngineComponentsUpgrading),
+			HaveField("Status", metav1.ConditionFalse),
+			HaveField("Reason", status.ComponentsUpgradedReason),
+		)))
+	})
+
+	It("should not gate components outside of an upgrade", func() {
+		mce.Status.CurrentVersion = version.Get().GitVersion
+		newReconciler(deployment("hive-operator", corev1.ConditionTrue), deployment("infrastructure-operator", corev1.ConditionFalse))
+		_, err := reconciler.ensureComponentsInOrder(ctx, mce, components)
+		Expect(err).To(Succeed())
+		Expect(ensured).To(Equal([]string{v1.Hive, v1.AssistedService}))
+		Expect(reconciler.StatusManager.Conditions).NotTo(ContainElement(HaveField("Type", v1.MultiClusterEngineComponentsUpgrading)))
+	})
+})
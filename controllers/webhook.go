@@ -0,0 +1,157 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// mceCRDName is the CustomResourceDefinition that owns the validating webhook configuration
+	mceCRDName = "multiclusterengines.multicluster.openshift.io"
+	// injectCABundleAnnotation asks the OpenShift service CA operator to inject its CA bundle
+	injectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+	// serviceCAConfigMap holds the OpenShift service CA bundle in every namespace
+	serviceCAConfigMap = "openshift-service-ca.crt"
+	serviceCAKey       = "service-ca.crt"
+)
+
+// ensureValidatingWebhook recreates the validating webhook configuration if it was deleted and restores its
+// webhooks and CA bundle if they were changed, so that the protections of the webhook cannot silently stop
+// working. A repair is reported with the WebhookRepaired condition.
+func (r *MultiClusterEngineReconciler) ensureValidatingWebhook(ctx context.Context) error {
+	if r.ValidatingWebhook == nil {
+		return nil
+	}
+	log := log.FromContext(ctx)
+
+	existing := &admissionregistration.ValidatingWebhookConfiguration{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: r.ValidatingWebhook.GetName()}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if apierrors.IsNotFound(err) {
+		webhook := r.ValidatingWebhook.DeepCopy()
+		crd := &apixv1.CustomResourceDefinition{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: mceCRDName}, crd); err != nil {
+			return err
+		}
+		webhook.SetOwnerReferences([]metav1.OwnerReference{
+			{
+				APIVersion: "apiextensions.k8s.io/v1",
+				Kind:       "CustomResourceDefinition",
+				Name:       crd.GetName(),
+				UID:        crd.GetUID(),
+			},
+		})
+		webhook.Webhooks = r.desiredWebhooks(ctx, nil)
+		log.Info("Validating webhook configuration is missing. Recreating it.", "name", webhook.GetName())
+		if err := r.Client.Create(ctx, webhook); err != nil {
+			return err
+		}
+		r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineWebhookRepaired, metav1.ConditionTrue, status.WebhookRecreatedReason,
+			fmt.Sprintf("ValidatingWebhookConfiguration %s was deleted and has been recreated", webhook.GetName())))
+		return nil
+	}
+
+	webhooks := r.desiredWebhooks(ctx, existing.Webhooks)
+	annotated := existing.GetAnnotations()[injectCABundleAnnotation] == r.ValidatingWebhook.GetAnnotations()[injectCABundleAnnotation]
+	if annotated && equality.Semantic.DeepEqual(existing.Webhooks, webhooks) {
+		return nil
+	}
+
+	log.Info("Validating webhook configuration was changed. Restoring it.", "name", existing.GetName())
+	annotations := existing.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range r.ValidatingWebhook.GetAnnotations() {
+		annotations[key] = value
+	}
+	existing.SetAnnotations(annotations)
+	existing.Webhooks = webhooks
+	if err := r.Client.Update(ctx, existing); err != nil {
+		return err
+	}
+	r.StatusManager.AddCondition(status.NewCondition(backplanev1.MultiClusterEngineWebhookRepaired, metav1.ConditionTrue, status.WebhookRestoredReason,
+		fmt.Sprintf("ValidatingWebhookConfiguration %s was changed and has been restored", existing.GetName())))
+	return nil
+}
+
+// desiredWebhooks returns the webhooks the operator manages. Fields the API server defaults are taken from the
+// existing webhooks so they don't count as a change. The CA bundle is kept from the existing webhooks, or read
+// from the service CA ConfigMap if it was cleared.
+func (r *MultiClusterEngineReconciler) desiredWebhooks(ctx context.Context, existing []admissionregistration.ValidatingWebhook) []admissionregistration.ValidatingWebhook {
+	webhooks := []admissionregistration.ValidatingWebhook{}
+	for _, desired := range r.ValidatingWebhook.DeepCopy().Webhooks {
+		for _, e := range existing {
+			if e.Name != desired.Name {
+				continue
+			}
+			desired.MatchPolicy = e.MatchPolicy
+			desired.NamespaceSelector = e.NamespaceSelector
+			desired.ObjectSelector = e.ObjectSelector
+			if desired.ClientConfig.Service != nil && e.ClientConfig.Service != nil {
+				desired.ClientConfig.Service.Port = e.ClientConfig.Service.Port
+			}
+			for i := range desired.Rules {
+				if i < len(e.Rules) {
+					desired.Rules[i].Scope = e.Rules[i].Scope
+				}
+			}
+			desired.ClientConfig.CABundle = e.ClientConfig.CABundle
+		}
+		if len(desired.ClientConfig.CABundle) == 0 {
+			desired.ClientConfig.CABundle = r.serviceCABundle(ctx)
+		}
+		webhooks = append(webhooks, desired)
+	}
+	return webhooks
+}
+
+// serviceCABundle returns the OpenShift service CA bundle, or nil if it is not available
+func (r *MultiClusterEngineReconciler) serviceCABundle(ctx context.Context) []byte {
+	cm := &corev1.ConfigMap{}
+	err := r.unlabeledReader().Get(ctx, types.NamespacedName{Name: serviceCAConfigMap, Namespace: utils.OperatorNamespace()}, cm)
+	if err != nil {
+		return nil
+	}
+	if bundle := cm.Data[serviceCAKey]; bundle != "" {
+		return []byte(bundle)
+	}
+	return nil
+}
+
+// validatingWebhookRequests returns a request for every MultiClusterEngine when the validating webhook
+// configuration managed by the operator changes
+func (r *MultiClusterEngineReconciler) validatingWebhookRequests(obj client.Object) []reconcile.Request {
+	if r.ValidatingWebhook == nil || obj.GetName() != r.ValidatingWebhook.GetName() {
+		return nil
+	}
+	mceList := &backplanev1.MultiClusterEngineList{}
+	if err := r.Client.List(context.TODO(), mceList); err != nil {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, mce := range mceList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: mce.GetName()}})
+	}
+	return requests
+}
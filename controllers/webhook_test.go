@@ -0,0 +1,129 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Validating webhook configuration", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *MultiClusterEngineReconciler
+		webhookKey = types.NamespacedName{Name: "multiclusterengines.multicluster.openshift.io"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(apixv1.AddToScheme(s)).To(Succeed())
+		Expect(v1.AddToScheme(s)).To(Succeed())
+
+		crd := &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: mceCRDName}}
+		serviceCA := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceCAConfigMap, Namespace: "default"},
+			Data:       map[string]string{serviceCAKey: "service-ca"},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(crd, serviceCA).Build()
+
+		sideEffects := admissionregistration.SideEffectClassNone
+		path := "/validate-multicluster-openshift-io-v1-multiclusterengine"
+		reconciler = &MultiClusterEngineReconciler{
+			Client:        c,
+			Scheme:        s,
+			StatusManager: &status.StatusTracker{Client: c},
+			ValidatingWebhook: &admissionregistration.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        webhookKey.Name,
+					Annotations: map[string]string{injectCABundleAnnotation: "true"},
+				},
+				Webhooks: []admissionregistration.ValidatingWebhook{{
+					Name:                    webhookKey.Name,
+					AdmissionReviewVersions: []string{"v1"},
+					SideEffects:             &sideEffects,
+					ClientConfig: admissionregistration.WebhookClientConfig{
+						Service: &admissionregistration.ServiceReference{
+							Name:      "multicluster-engine-operator-webhook-service",
+							Namespace: "default",
+							Path:      &path,
+						},
+					},
+				}},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("POD_NAMESPACE")).To(Succeed())
+	})
+
+	It("should be recreated when deleted", func() {
+		Expect(reconciler.ensureValidatingWebhook(ctx)).To(Succeed())
+		webhook := &admissionregistration.ValidatingWebhookConfiguration{}
+		Expect(c.Get(ctx, webhookKey, webhook)).To(Succeed())
+
+		By("deleting the webhook configuration")
+		Expect(c.Delete(ctx, webhook)).To(Succeed())
+		Expect(reconciler.ensureValidatingWebhook(ctx)).To(Succeed())
+
+		By("ensuring it is restored with its CA bundle")
+		webhook = &admissionregistration.ValidatingWebhookConfiguration{}
+		Expect(c.Get(ctx, webhookKey, webhook)).To(Succeed())
+		Expect(webhook.Webhooks).To(HaveLen(1))
+		Expect(webhook.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("service-ca")))
+		Expect(webhook.GetOwnerReferences()).To(HaveLen(1))
+
+		repaired := getTrackedCondition(reconciler.StatusManager, v1.MultiClusterEngineWebhookRepaired)
+		Expect(repaired).NotTo(BeNil())
+		Expect(repaired.Reason).To(Equal(status.WebhookRecreatedReason))
+	})
+
+	It("should be restored when its CA bundle is cleared", func() {
+		Expect(reconciler.ensureValidatingWebhook(ctx)).To(Succeed())
+		webhook := &admissionregistration.ValidatingWebhookConfiguration{}
+		Expect(c.Get(ctx, webhookKey, webhook)).To(Succeed())
+
+		By("clearing the CA bundle")
+		webhook.Webhooks[0].ClientConfig.CABundle = nil
+		Expect(c.Update(ctx, webhook)).To(Succeed())
+		Expect(reconciler.ensureValidatingWebhook(ctx)).To(Succeed())
+
+		webhook = &admissionregistration.ValidatingWebhookConfiguration{}
+		Expect(c.Get(ctx, webhookKey, webhook)).To(Succeed())
+		Expect(webhook.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("service-ca")))
+
+		repaired := getTrackedCondition(reconciler.StatusManager, v1.MultiClusterEngineWebhookRepaired)
+		Expect(repaired).NotTo(BeNil())
+		Expect(repaired.Reason).To(Equal(status.WebhookRestoredReason))
+	})
+})
+
+func getTrackedCondition(sm *status.StatusTracker, condType v1.MultiClusterEngineConditionType) *v1.MultiClusterEngineCondition {
+	for i := range sm.Conditions {
+		if sm.Conditions[i].Type == condType {
+			return &sm.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -23,18 +23,23 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"time"
 
+	"github.com/stolostron/backplane-operator/pkg/audit"
+	"github.com/stolostron/backplane-operator/pkg/certrotation"
+	"github.com/stolostron/backplane-operator/pkg/images"
+	"github.com/stolostron/backplane-operator/pkg/overlay"
 	renderer "github.com/stolostron/backplane-operator/pkg/rendering"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
 	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"sigs.k8s.io/yaml"
@@ -42,7 +47,9 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	hiveconfig "github.com/openshift/hive/apis/hive/v1"
 	"github.com/stolostron/backplane-operator/pkg/status"
+	"github.com/stolostron/backplane-operator/pkg/utils"
 	"github.com/stolostron/backplane-operator/pkg/version"
+	uberzap "go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,6 +58,8 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -64,6 +73,13 @@ import (
 const (
 	crdName = "multiclusterengines.multicluster.openshift.io"
 	crdsDir = "pkg/templates/crds"
+	// defaultLeaderElectionID is the lease name used for leader election. Changing it lets a new operator
+	// acquire leadership while an operator using the old lease is still running.
+	defaultLeaderElectionID = "797f9276.open-cluster-management.io"
+	// rotatedCertSecret holds the webhook serving certificate issued with --webhook-cert-rotation. It differs from
+	// the secret the OpenShift service CA issues, so the two don't overwrite each other.
+	rotatedCertSecret = "multicluster-engine-operator-webhook-cert"
+	rotatedCertDir    = "/tmp/k8s-webhook-server/rotated-certs"
 )
 
 var (
@@ -96,68 +112,283 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := runRender(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
+	var leaderElectionNamespace string
 	var probeAddr string
+	var maxRequeueBackoff time.Duration
+	var auditSinkURL string
+	var defaultPriorityClassName string
+	var logLevel string
+	var disableWebhook bool
+	var webhookCertRotation bool
+	var watchNamespace string
+	var labelFilteredCache bool
+	var webhookFailurePolicy string
+	var webhookTimeout int
+	var overlayDir string
+	var imageManifestFile string
+	var fipsMode bool
+	var componentReadyTimeout time.Duration
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var maxConcurrentReconciles int
+	var applyWorkers int
+	var syncPeriod time.Duration
+	var metricsAuthProxy bool
+	var metricsProxyImage string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&metricsAuthProxy, "metrics-auth-proxy", false,
+		"Protect the metric endpoint with a kube-rbac-proxy sidecar and a ServiceMonitor managed by the operator. "+
+			"The metric endpoint then binds to "+controllers.MetricsProxyUpstream+". Not supported on installs managed by OLM.")
+	flag.StringVar(&metricsProxyImage, "metrics-proxy-image", controllers.DefaultMetricsProxyImage,
+		"The kube-rbac-proxy image used to protect the metric endpoint.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", defaultLeaderElectionID,
+		"The name of the lease used for leader election. Operators sharing a lease name and namespace elect a single leader.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"The namespace of the lease used for leader election. Defaults to the namespace the operator runs in.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"How long non-leader candidates wait before forcing acquisition of the leader election lease.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"How long the leader retries refreshing the lease before giving up leadership. Must be less than the lease duration.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"How long candidates wait between attempts to acquire or renew the lease.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of reconciles each controller runs in parallel.")
+	flag.IntVar(&applyWorkers, "apply-workers", controllers.DefaultApplyWorkers,
+		"The number of components applied in parallel. Only components that don't depend on each other are applied "+
+			"together, and components are upgraded one at a time.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour,
+		"How often the cached resources are resynced, which reconciles every watched object.")
+	flag.DurationVar(&maxRequeueBackoff, "max-requeue-backoff", controllers.DefaultMaxRequeueBackoff,
+		"The maximum delay between retries of a failed reconcile. Retries back off exponentially up to this value.")
+	flag.StringVar(&auditSinkURL, "audit-sink-url", "",
+		"If set, condition transitions and Events of the MultiClusterEngine are posted as JSON to this URL.")
+	flag.StringVar(&defaultPriorityClassName, "default-priority-class", controllers.DefaultPriorityClassName,
+		"The priority class given to component workloads when the MultiClusterEngine does not set one. Set to an empty string to disable.")
+	flag.StringVar(&logLevel, "log-level", "info",
+		"The log level, one of error, info or debug. Can be changed at runtime through the "+utils.LogLevelConfigMap+" ConfigMap or spec.logLevel of the multiclusterengine.")
+	flag.BoolVar(&disableWebhook, "disable-webhook", os.Getenv("ENABLE_WEBHOOKS") == "false",
+		"Run without the validating webhook. The operator then enforces a single MultiClusterEngine itself.")
+	flag.StringVar(&watchNamespace, "watch-namespace", os.Getenv("WATCH_NAMESPACE"),
+		"Comma-separated list of namespaces the operator watches. Defaults to all namespaces.")
+	flag.BoolVar(&labelFilteredCache, "label-filtered-cache", false,
+		"Only cache the Deployments and ConfigMaps carrying the backplaneconfig.name label, which cuts memory use on "+
+			"large clusters. Other Deployments and ConfigMaps are read from the API server, and changes to them are "+
+			"picked up on the next reconcile.")
+	flag.StringVar(&webhookFailurePolicy, "webhook-failure-policy", string(admissionregistration.Fail),
+		"How the API server handles a MultiClusterEngine request when the webhook is unreachable, one of Fail or Ignore. "+
+			"Ignore keeps the API usable while the operator is down but skips validation.")
+	flag.IntVar(&webhookTimeout, "webhook-timeout", 10,
+		"Seconds the API server waits for the webhook to respond, between 1 and 30.")
+	flag.BoolVar(&webhookCertRotation, "webhook-cert-rotation", false,
+		"Issue and rotate a self-signed serving certificate for the webhook and set its CA bundle, for installs "+
+			"without OLM or the OpenShift service CA.")
+	flag.StringVar(&overlayDir, "overlay-dir", "",
+		"If set, patches in this directory are applied to the rendered manifests before they are created. "+
+			"The directory holds strategic merge patches, optionally listed in a kustomization.yaml.")
+	flag.StringVar(&imageManifestFile, "image-manifest-file", "",
+		"If set, the operand images are read from this image manifest file instead of the OPERAND_IMAGE_ "+
+			"environment variables. The file is reloaded when it changes.")
+	flag.DurationVar(&componentReadyTimeout, "component-ready-timeout", 0,
+		"How long a component may stay unavailable before it is reported with reason InstallTimeout. "+
+			"Zero disables the timeout.")
+	flag.BoolVar(&fipsMode, "fips-mode", false,
+		"Enable FIPS crypto in component pods even if FIPS mode is not enabled in the cluster install config.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	defaultLogLevel, err := utils.ParseLogLevel(logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	atomicLevel := uberzap.NewAtomicLevelAt(defaultLogLevel)
+	opts.Level = atomicLevel
+
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := validateControllerTuning(leaseDuration, renewDeadline, retryPeriod, maxConcurrentReconciles); err != nil {
+		setupLog.Error(err, "invalid controller settings")
+		os.Exit(1)
+	}
+
+	if metricsAuthProxy {
+		metricsAddr = controllers.MetricsProxyUpstream
+	}
+
 	ctrl.Log.WithName("Backplane Operator version").Info(fmt.Sprintf("%#v", version.Get()))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "797f9276.open-cluster-management.io",
-		// LeaderElectionNamespace: "backplane-operator-system", // Ensure this is commented out. Uncomment only for running operator locally.
-	})
+	mgrOptions := ctrl.Options{
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		Port:                    9443,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaseDuration:           &leaseDuration,
+		RenewDeadline:           &renewDeadline,
+		RetryPeriod:             &retryPeriod,
+		SyncPeriod:              &syncPeriod,
+		// Pods are only read to diagnose unavailable components and secrets only to copy the image pull
+		// secret, which doesn't warrant caching every pod and secret on the cluster
+		ClientDisableCacheFor: []client.Object{&corev1.Pod{}, &corev1.Secret{}},
+	}
+	if webhookCertRotation {
+		// The mounted serving certificate is read-only, so the rotated one is written elsewhere
+		mgrOptions.CertDir = rotatedCertDir
+	}
+
+	// Scope the cache to the watched namespaces. Cluster-scoped resources are always watched cluster-wide.
+	switch namespaces := utils.ParseWatchNamespaces(watchNamespace); len(namespaces) {
+	case 0:
+	case 1:
+		setupLog.Info("Watching a single namespace", "namespace", namespaces[0])
+		mgrOptions.Namespace = namespaces[0]
+	default:
+		setupLog.Info("Watching multiple namespaces", "namespaces", namespaces)
+		mgrOptions.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+
+	if labelFilteredCache {
+		setupLog.Info("Only caching Deployments and ConfigMaps managed by the operator")
+		mgrOptions.NewCache, err = utils.LabelFilteredCache(mgrOptions.NewCache)
+		if err != nil {
+			setupLog.Error(err, "unable to set up the label filtered cache")
+			os.Exit(1)
+		}
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), mgrOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.MultiClusterEngineReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		StatusManager: &status.StatusTracker{Client: mgr.GetClient()},
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MultiClusterEngine")
-		os.Exit(1)
+	var auditSink *audit.Sink
+	if auditSinkURL != "" {
+		auditSink = audit.NewSink(auditSinkURL)
+		if err := mgr.Add(auditSink); err != nil {
+			setupLog.Error(err, "unable to set up audit sink")
+			os.Exit(1)
+		}
 	}
 
-	// Render CRD templates
-	crdsDir := crdsDir
-	crds, errs := renderer.RenderCRDs(crdsDir)
-	if len(errs) > 0 {
-		for _, err := range errs {
-			setupLog.Info(err.Error())
+	if metricsAuthProxy {
+		if err := mgr.Add(&controllers.MetricsProxy{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Namespace: utils.OperatorNamespace(),
+			Image:     metricsProxyImage,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up metrics proxy")
+			os.Exit(1)
+		}
+	}
+
+	var manifestOverlay *overlay.Overlay
+	if overlayDir != "" {
+		manifestOverlay, err = overlay.Load(overlayDir)
+		if err != nil {
+			setupLog.Error(err, "unable to load manifest overlay", "directory", overlayDir)
+			os.Exit(1)
+		}
+	}
+
+	var imageManifest *images.ManifestFile
+	if imageManifestFile != "" {
+		imageManifest = &images.ManifestFile{Path: imageManifestFile}
+		if err := imageManifest.Load(); err != nil {
+			setupLog.Error(err, "unable to load image manifest", "file", imageManifestFile)
+			os.Exit(1)
+		}
+		if err := mgr.Add(imageManifest); err != nil {
+			setupLog.Error(err, "unable to set up image manifest reload")
+			os.Exit(1)
 		}
-		os.Exit(1)
 	}
 
-	for _, crd := range crds {
-		err := ensureCRD(mgr, crd)
+	var validatingWebhook *admissionregistration.ValidatingWebhookConfiguration
+	var mutatingWebhook *admissionregistration.MutatingWebhookConfiguration
+	if !disableWebhook {
+		validatingWebhook, err = loadValidatingWebhook(webhookFailurePolicy, webhookTimeout)
+		if err != nil {
+			setupLog.Error(err, "unable to load webhook", "webhook", "MultiClusterEngine")
+			os.Exit(1)
+		}
+		mutatingWebhook, err = loadMutatingWebhook(webhookFailurePolicy, webhookTimeout)
 		if err != nil {
-			setupLog.Info(err.Error())
+			setupLog.Error(err, "unable to load defaulting webhook", "webhook", "MultiClusterEngine")
 			os.Exit(1)
 		}
+		if webhookCertRotation {
+			if err := setupCertRotation(mgr, validatingWebhook, mutatingWebhook); err != nil {
+				setupLog.Error(err, "unable to set up webhook certificate rotation")
+				os.Exit(1)
+			}
+		}
+	}
+
+	statusTracker := &status.StatusTracker{Client: mgr.GetClient(), ReadyTimeout: componentReadyTimeout}
+	reconciler := &controllers.MultiClusterEngineReconciler{
+		Client:                   mgr.GetClient(),
+		APIReader:                mgr.GetAPIReader(),
+		LabelFilteredCache:       labelFilteredCache,
+		Scheme:                   mgr.GetScheme(),
+		StatusManager:            statusTracker,
+		MaxRequeueBackoff:        maxRequeueBackoff,
+		MaxConcurrentReconciles:  maxConcurrentReconciles,
+		ApplyWorkers:             applyWorkers,
+		AuditSink:                auditSink,
+		Recorder:                 mgr.GetEventRecorderFor("multicluster-engine-operator"),
+		DefaultPriorityClassName: defaultPriorityClassName,
+		LogLevel:                 &atomicLevel,
+		DefaultLogLevel:          defaultLogLevel,
+		WebhookDisabled:          disableWebhook,
+		ImageManifest:            imageManifest,
+		Overlay:                  manifestOverlay,
+		ValidatingWebhook:        validatingWebhook,
+		FIPSMode:                 fipsMode,
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MultiClusterEngine")
+		os.Exit(1)
 	}
 
-	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+	operandCRDs, err := controllers.RenderOperandCRDs(crdsDir)
+	if err != nil {
+		setupLog.Error(err, "unable to render operand CRDs")
+		os.Exit(1)
+	}
+	if err = (&controllers.OperandCRDReconciler{
+		Client:                  mgr.GetClient(),
+		CRDs:                    operandCRDs,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OperandCRD")
+		os.Exit(1)
+	}
+
+	if !disableWebhook {
 		// https://book.kubebuilder.io/cronjob-tutorial/running.html#running-webhooks-locally, https://book.kubebuilder.io/multiversion-tutorial/webhooks.html#and-maingo
-		if err = ensureWebhooks(mgr); err != nil {
+		if err = ensureWebhooks(mgr, validatingWebhook, mutatingWebhook); err != nil {
 			setupLog.Error(err, "unable to ensure webhook", "webhook", "MultiClusterEngine")
 			os.Exit(1)
 		}
@@ -169,87 +400,139 @@ func main() {
 	}
 	//+kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
+	// The health probes are served here rather than by the manager, whose /readyz would include every check
+	probes := &healthProbes{
+		healthz: healthz.Handler{Checks: map[string]healthz.Checker{"healthz": healthz.Ping}},
+		// Every replica serves the webhook, leader or not, so the operator's readiness only depends on the process
+		readyz: healthz.Handler{Checks: map[string]healthz.Checker{"cache-sync": cacheSynced(mgr)}},
+		// Whether the MultiClusterEngine has been reconciled with all components available is only served at
+		// /readyz/multiclusterengine, for external monitoring
+		separate: healthz.Handler{Checks: map[string]healthz.Checker{"multiclusterengine": statusTracker.ReadyzCheck}},
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+	if !disableWebhook {
+		probes.readyz.Checks["webhook"] = mgr.GetWebhookServer().StartedChecker()
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	if probeAddr != "" && probeAddr != "0" {
+		go func() {
+			if err := probes.serve(ctx, probeAddr); err != nil {
+				setupLog.Error(err, "problem serving health probes")
+				os.Exit(1)
+			}
+		}()
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
-func ensureCRD(mgr ctrl.Manager, crd *unstructured.Unstructured) error {
-	ctx := context.Background()
-	maxAttempts := 5
-	go func() {
-		for i := 0; i < maxAttempts; i++ {
-			setupLog.Info(fmt.Sprintf("Ensuring '%s' CRD exists", crd.GetName()))
-			existingCRD := &unstructured.Unstructured{}
-			existingCRD.SetGroupVersionKind(crd.GroupVersionKind())
-			err := mgr.GetClient().Get(ctx, types.NamespacedName{Name: crd.GetName()}, existingCRD)
-			if err != nil && errors.IsNotFound(err) {
-				// CRD not found. Create and return
-				err = mgr.GetClient().Create(ctx, crd)
-				if err != nil {
-					setupLog.Error(err, fmt.Sprintf("Error creating '%s' CRD", crd.GetName()))
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				return
-			} else if err != nil {
-				setupLog.Error(err, fmt.Sprintf("Error getting '%s' CRD", crd.GetName()))
-			} else if err == nil {
-				// CRD already exists. Update and return
-				setupLog.Info(fmt.Sprintf("'%s' CRD already exists. Updating.", crd.GetName()))
-				crd.SetResourceVersion(existingCRD.GetResourceVersion())
-				err = mgr.GetClient().Update(ctx, crd)
-				if err != nil {
-					setupLog.Error(err, fmt.Sprintf("Error updating '%s' CRD", crd.GetName()))
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				return
-			}
-			time.Sleep(5 * time.Second)
+// cacheSynced reports ready once the informers the reconcilers read from have synced
+func cacheSynced(mgr ctrl.Manager) healthz.Checker {
+	return func(req *http.Request) error {
+		if !mgr.GetCache().WaitForCacheSync(req.Context()) {
+			return fmt.Errorf("informer cache has not synced")
 		}
+		return nil
+	}
+}
 
-		setupLog.Info(fmt.Sprintf("Unable to ensure '%s' CRD exists in allotted time. Failing.", crd.GetName()))
-		os.Exit(1)
-	}()
+// validateControllerTuning checks the leader election and concurrency flags. The leader must be able to retry
+// renewing its lease before the renew deadline, and give it up before other candidates take it over.
+func validateControllerTuning(leaseDuration, renewDeadline, retryPeriod time.Duration, maxConcurrentReconciles int) error {
+	if retryPeriod <= 0 || renewDeadline <= retryPeriod {
+		return fmt.Errorf("invalid leader election renew deadline %s. Must be greater than the retry period %s", renewDeadline, retryPeriod)
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("invalid leader election lease duration %s. Must be greater than the renew deadline %s", leaseDuration, renewDeadline)
+	}
+	if maxConcurrentReconciles < 1 {
+		return fmt.Errorf("invalid max concurrent reconciles %d. Must be at least 1", maxConcurrentReconciles)
+	}
 	return nil
 }
 
-func ensureWebhooks(mgr ctrl.Manager) error {
-	ctx := context.Background()
+// webhookSettings checks the webhook failure policy and timeout flags and returns them with the namespace of the
+// webhook service
+func webhookSettings(failurePolicy string, timeoutSeconds int) (admissionregistration.FailurePolicyType, int32, string, error) {
+	policy := admissionregistration.FailurePolicyType(failurePolicy)
+	if policy != admissionregistration.Fail && policy != admissionregistration.Ignore {
+		return "", 0, "", fmt.Errorf("invalid webhook failure policy %q. Must be one of Fail or Ignore", failurePolicy)
+	}
+	if timeoutSeconds < 1 || timeoutSeconds > 30 {
+		return "", 0, "", fmt.Errorf("invalid webhook timeout %d. Must be between 1 and 30 seconds", timeoutSeconds)
+	}
 
 	deploymentNamespace, ok := os.LookupEnv("POD_NAMESPACE")
 	if !ok {
-		setupLog.Info("Failing due to being unable to locate webhook service namespace")
-		os.Exit(1)
+		return "", 0, "", fmt.Errorf("unable to locate the webhook service namespace. POD_NAMESPACE is not set")
+	}
+	return policy, int32(timeoutSeconds), deploymentNamespace, nil
+}
+
+// loadValidatingWebhook reads the validating webhook configuration and points it at the webhook service in the
+// operator's namespace
+func loadValidatingWebhook(failurePolicy string, timeoutSeconds int) (*admissionregistration.ValidatingWebhookConfiguration, error) {
+	policy, timeout, deploymentNamespace, err := webhookSettings(failurePolicy, timeoutSeconds)
+	if err != nil {
+		return nil, err
 	}
 
 	validatingWebhookPath := "pkg/templates/core/validatingwebhook.yaml"
 	bytesFile, err := ioutil.ReadFile(validatingWebhookPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	validatingWebhook := &admissionregistration.ValidatingWebhookConfiguration{}
 	if err = yaml.Unmarshal(bytesFile, validatingWebhook); err != nil {
-		return err
+		return nil, err
 	}
 	// Override all webhook service namespace definitions to be the same as the pod namespace.
 	for i := 0; i < len(validatingWebhook.Webhooks); i++ {
 		validatingWebhook.Webhooks[i].ClientConfig.Service.Namespace = deploymentNamespace
+		validatingWebhook.Webhooks[i].FailurePolicy = &policy
+		validatingWebhook.Webhooks[i].TimeoutSeconds = &timeout
+	}
+	return validatingWebhook, nil
+}
+
+// loadMutatingWebhook reads the defaulting webhook configuration and points it at the webhook service in the
+// operator's namespace
+func loadMutatingWebhook(failurePolicy string, timeoutSeconds int) (*admissionregistration.MutatingWebhookConfiguration, error) {
+	policy, timeout, deploymentNamespace, err := webhookSettings(failurePolicy, timeoutSeconds)
+	if err != nil {
+		return nil, err
 	}
 
+	bytesFile, err := ioutil.ReadFile("pkg/templates/core/mutatingwebhook.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	mutatingWebhook := &admissionregistration.MutatingWebhookConfiguration{}
+	if err = yaml.Unmarshal(bytesFile, mutatingWebhook); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(mutatingWebhook.Webhooks); i++ {
+		mutatingWebhook.Webhooks[i].ClientConfig.Service.Namespace = deploymentNamespace
+		mutatingWebhook.Webhooks[i].FailurePolicy = &policy
+		mutatingWebhook.Webhooks[i].TimeoutSeconds = &timeout
+	}
+	return mutatingWebhook, nil
+}
+
+// ensureWebhooks creates the validating and mutating webhook configurations, or updates them if they exist, once
+// the manager has started. The reconciler keeps the validating webhook in place afterwards.
+func ensureWebhooks(mgr ctrl.Manager, webhook *admissionregistration.ValidatingWebhookConfiguration,
+	defaulting *admissionregistration.MutatingWebhookConfiguration) error {
+	ctx := context.Background()
+	validatingWebhook := webhook.DeepCopy()
+	mutatingWebhook := defaulting.DeepCopy()
+
 	// Wait for manager cache to start and create webhook
 	maxAttempts := 10
 	go func() {
@@ -272,13 +555,20 @@ func ensureWebhooks(mgr ctrl.Manager) error {
 				},
 			})
 
+			mutatingWebhook.SetOwnerReferences(validatingWebhook.GetOwnerReferences())
+			if err := ensureMutatingWebhook(ctx, mgr.GetClient(), mutatingWebhook); err != nil {
+				setupLog.Error(err, "Error ensuring mutatingwebhookconfiguration")
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
 			existingWebhook := &admissionregistration.ValidatingWebhookConfiguration{}
 			existingWebhook.SetGroupVersionKind(schema.GroupVersionKind{
 				Group:   "admissionregistration.k8s.io",
 				Version: "v1",
 				Kind:    "ValidatingWebhookConfiguration",
 			})
-			err = mgr.GetClient().Get(ctx, types.NamespacedName{Name: validatingWebhook.GetName()}, existingWebhook)
+			err := mgr.GetClient().Get(ctx, types.NamespacedName{Name: validatingWebhook.GetName()}, existingWebhook)
 			if err != nil && errors.IsNotFound(err) {
 				// Webhook not found. Create and return
 				err = mgr.GetClient().Create(ctx, validatingWebhook)
@@ -311,3 +601,129 @@ func ensureWebhooks(mgr ctrl.Manager) error {
 
 	return nil
 }
+
+// setupCertRotation issues the webhook serving certificate before the webhook server starts, points the webhook
+// configurations at its CA instead of the OpenShift service CA and keeps it rotated while the manager runs
+func setupCertRotation(mgr ctrl.Manager, validating *admissionregistration.ValidatingWebhookConfiguration,
+	mutating *admissionregistration.MutatingWebhookConfiguration) error {
+	rotator := &certrotation.Rotator{
+		// Secrets are not cached, so they can be read before the manager starts
+		Client:            mgr.GetClient(),
+		Namespace:         utils.OperatorNamespace(),
+		SecretName:        rotatedCertSecret,
+		ServiceName:       validating.Webhooks[0].ClientConfig.Service.Name,
+		CertDir:           rotatedCertDir,
+		ValidatingWebhook: validating.GetName(),
+		MutatingWebhook:   mutating.GetName(),
+	}
+	ctx := context.Background()
+	if err := rotator.EnsureCert(ctx); err != nil {
+		return err
+	}
+	bundle, err := rotator.CABundle(ctx)
+	if err != nil {
+		return err
+	}
+
+	delete(validating.Annotations, "service.beta.openshift.io/inject-cabundle")
+	for i := range validating.Webhooks {
+		validating.Webhooks[i].ClientConfig.CABundle = bundle
+	}
+	delete(mutating.Annotations, "service.beta.openshift.io/inject-cabundle")
+	for i := range mutating.Webhooks {
+		mutating.Webhooks[i].ClientConfig.CABundle = bundle
+	}
+	return mgr.Add(rotator)
+}
+
+// ensureMutatingWebhook creates the mutating webhook configuration, or updates its webhooks if it exists
+func ensureMutatingWebhook(ctx context.Context, c client.Client, webhook *admissionregistration.MutatingWebhookConfiguration) error {
+	existing := &admissionregistration.MutatingWebhookConfiguration{}
+	err := c.Get(ctx, types.NamespacedName{Name: webhook.GetName()}, existing)
+	if errors.IsNotFound(err) {
+		return c.Create(ctx, webhook.DeepCopy())
+	} else if err != nil {
+		return err
+	}
+	existing.Webhooks = webhook.Webhooks
+	existing.SetOwnerReferences(webhook.GetOwnerReferences())
+	return c.Update(ctx, existing)
+}
+
+// runRender implements the render subcommand. It prints the manifests the operator would apply for a
+// MultiClusterEngine to stdout without contacting a cluster. Images are taken from the image manifest file if
+// one is given, and from the OPERAND_IMAGE_ variables as in the operator deployment otherwise.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	file := fs.String("f", "-", "The MultiClusterEngine YAML to render, or - to read it from stdin.")
+	overlayDir := fs.String("overlay-dir", "", "If set, patches in this directory are applied to the rendered manifests.")
+	imageManifestFile := fs.String("image-manifest-file", "", "If set, the operand images are read from this image manifest file.")
+	fipsMode := fs.Bool("fips-mode", false, "Render the manifests as on a cluster in FIPS mode.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+	mce := &backplanev1.MultiClusterEngine{}
+	if err := yaml.Unmarshal(data, mce); err != nil {
+		return fmt.Errorf("invalid MultiClusterEngine: %w", err)
+	}
+	mce.Default()
+
+	if cmName := utils.GetImageOverridesConfigmap(mce); cmName != "" {
+		return fmt.Errorf("the image overrides ConfigMap %s can only be read on a cluster", cmName)
+	}
+	if mce.Spec.Overrides != nil && mce.Spec.Overrides.ResolveImageDigests {
+		return fmt.Errorf("image digests can only be resolved on a cluster. Unset spec.overrides.resolveImageDigests to render")
+	}
+	imgs := images.GetImages()
+	if *imageManifestFile != "" {
+		manifest := &images.ManifestFile{Path: *imageManifestFile}
+		if err := manifest.Load(); err != nil {
+			return err
+		}
+		if imgs, err = manifest.Images(); err != nil {
+			return err
+		}
+	}
+	imgs, err = images.OverrideImages(nil, mce, imgs)
+	if err != nil {
+		return err
+	}
+	if len(imgs) == 0 {
+		return fmt.Errorf("no image references defined. Set the OPERAND_IMAGE_ environment variables")
+	}
+
+	var manifestOverlay *overlay.Overlay
+	if *overlayDir != "" {
+		manifestOverlay, err = overlay.Load(*overlayDir)
+		if err != nil {
+			return err
+		}
+	}
+	r := &controllers.MultiClusterEngineReconciler{Overlay: manifestOverlay}
+	manifests, errs := r.RenderManifests(mce, renderer.Options{Images: imgs, FIPS: *fipsMode})
+	for _, manifest := range manifests {
+		out, err := yaml.Marshal(manifest.Object)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", out)
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d manifests failed to render", len(errs))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+func Test_runRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		mce     string
+		wantErr string
+	}{
+		{
+			name: "resolve image digests",
+			mce: `apiVersion: multicluster.openshift.io/v1
+kind: MultiClusterEngine
+metadata:
+  name: multiclusterengine
+spec:
+  overrides:
+    resolveImageDigests: true
+`,
+			wantErr: "spec.overrides.resolveImageDigests",
+		},
+		{
+			name: "no images",
+			mce: `apiVersion: multicluster.openshift.io/v1
+kind: MultiClusterEngine
+metadata:
+  name: multiclusterengine
+`,
+			wantErr: "no image references defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "multiclusterengine.yaml")
+			if err := os.WriteFile(file, []byte(tt.mce), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := runRender([]string{"-f", file})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runRender() error = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_healthProbes(t *testing.T) {
+	notReady := func(*http.Request) error { return errors.New("not ready") }
+	probes := &healthProbes{
+		healthz:  healthz.Handler{Checks: map[string]healthz.Checker{"healthz": healthz.Ping}},
+		readyz:   healthz.Handler{Checks: map[string]healthz.Checker{"cache-sync": healthz.Ping}},
+		separate: healthz.Handler{Checks: map[string]healthz.Checker{"multiclusterengine": notReady}},
+	}
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/healthz", want: http.StatusOK},
+		{path: "/readyz", want: http.StatusOK},
+		{path: "/readyz/cache-sync", want: http.StatusOK},
+		{path: "/readyz/multiclusterengine", want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			probes.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func Test_webhookSettings(t *testing.T) {
+	// Setenv restores the variable once the test ends
+	t.Setenv("POD_NAMESPACE", "")
+	os.Unsetenv("POD_NAMESPACE")
+	if _, _, _, err := webhookSettings("Fail", 10); err == nil || !strings.Contains(err.Error(), "POD_NAMESPACE") {
+		t.Errorf("webhookSettings() error = %v, want an error naming POD_NAMESPACE", err)
+	}
+
+	t.Setenv("POD_NAMESPACE", "multicluster-engine")
+	_, _, namespace, err := webhookSettings("Fail", 10)
+	if err != nil {
+		t.Fatalf("webhookSettings() error = %v", err)
+	}
+	if namespace != "multicluster-engine" {
+		t.Errorf("webhookSettings() namespace = %q, want multicluster-engine", namespace)
+	}
+}
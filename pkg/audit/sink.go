@@ -0,0 +1,145 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// bufferSize is the number of events held while waiting to be forwarded
+	bufferSize = 100
+	// maxAttempts is the number of times delivery of an event is attempted before it is dropped
+	maxAttempts = 5
+	// defaultRetryInterval is the base delay between delivery attempts
+	defaultRetryInterval = 2 * time.Second
+)
+
+var log = logf.Log.WithName("audit-sink")
+
+// Event is the payload posted to the audit sink
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Name of the MultiClusterEngine the event relates to
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// NewEvent returns the audit event for an Event recorded on the MultiClusterEngine with the given name
+func NewEvent(name, eventType, reason, message string) Event {
+	return Event{Timestamp: time.Now(), Name: name, Type: eventType, Reason: reason, Message: message}
+}
+
+// Sink buffers audit events and forwards them to an HTTP endpoint. Delivery happens in the
+// background so a slow or unavailable endpoint never blocks the caller.
+type Sink struct {
+	url           string
+	client        *http.Client
+	queue         chan Event
+	retryInterval time.Duration
+}
+
+// NewSink returns a sink that posts events to url
+func NewSink(url string) *Sink {
+	return &Sink{
+		url:           url,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		queue:         make(chan Event, bufferSize),
+		retryInterval: defaultRetryInterval,
+	}
+}
+
+// Record queues an event for delivery. If the buffer is full the event is dropped.
+func (s *Sink) Record(e Event) {
+	select {
+	case s.queue <- e:
+	default:
+		log.Info("Audit buffer is full. Dropping event", "name", e.Name, "reason", e.Reason)
+	}
+}
+
+// Start forwards queued events until the context is cancelled. It implements manager.Runnable.
+func (s *Sink) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-s.queue:
+			s.send(ctx, e)
+		}
+	}
+}
+
+// send posts an event, retrying with a linear backoff until it succeeds or attempts are exhausted
+func (s *Sink) send(ctx context.Context, e Event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Error(err, "Failed to marshal audit event")
+		return
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = s.post(ctx, body); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(attempt) * s.retryInterval):
+		}
+	}
+	log.Error(err, "Failed to forward audit event", "name", e.Name, "reason", e.Reason)
+}
+
+func (s *Sink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ConditionTransitions returns an event for every condition whose status changed between old and new
+func ConditionTransitions(name string, old, new []bpv1.MultiClusterEngineCondition) []Event {
+	events := []Event{}
+	for _, c := range new {
+		changed := true
+		for _, o := range old {
+			if o.Type == c.Type && o.Status == c.Status && o.Reason == c.Reason {
+				changed = false
+				break
+			}
+		}
+		if !changed {
+			continue
+		}
+		events = append(events, Event{
+			Timestamp: c.LastUpdateTime.Time,
+			Name:      name,
+			Type:      string(c.Type),
+			Reason:    c.Reason,
+			Message:   fmt.Sprintf("Condition %s is %s: %s", c.Type, c.Status, c.Message),
+		})
+	}
+	return events
+}
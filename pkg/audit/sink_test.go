@@ -0,0 +1,74 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SinkForwardsEvents(t *testing.T) {
+	received := make(chan Event, 2)
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first request to exercise the retry path
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		e := Event{}
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("Failed to decode audit event: %v", err)
+		}
+		received <- e
+	}))
+	defer server.Close()
+
+	sink := NewSink(server.URL)
+	sink.retryInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sink.Start(ctx)
+
+	sink.Record(Event{Name: "multiclusterengine", Type: "Available", Reason: "ComponentsAvailable"})
+	sink.Record(NewEvent("multiclusterengine", "Warning", "ApplyFailed", "webhook timed out"))
+
+	for _, reason := range []string{"ComponentsAvailable", "ApplyFailed"} {
+		select {
+		case e := <-received:
+			if e.Name != "multiclusterengine" || e.Reason != reason {
+				t.Errorf("Unexpected event posted to sink: %+v", e)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Event %s was not posted to sink", reason)
+		}
+	}
+}
+
+func Test_ConditionTransitions(t *testing.T) {
+	old := []bpv1.MultiClusterEngineCondition{
+		{Type: bpv1.MultiClusterEngineAvailable, Status: metav1.ConditionFalse, Reason: "ComponentsUnavailable"},
+		{Type: bpv1.MultiClusterEngineProgressing, Status: metav1.ConditionTrue, Reason: "ComponentsDeployed"},
+	}
+	new := []bpv1.MultiClusterEngineCondition{
+		{Type: bpv1.MultiClusterEngineAvailable, Status: metav1.ConditionTrue, Reason: "ComponentsAvailable"},
+		{Type: bpv1.MultiClusterEngineProgressing, Status: metav1.ConditionTrue, Reason: "ComponentsDeployed"},
+	}
+
+	events := ConditionTransitions("multiclusterengine", old, new)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 transition. Got %d", len(events))
+	}
+	if events[0].Type != string(bpv1.MultiClusterEngineAvailable) {
+		t.Errorf("Expected transition of %s. Got %s", bpv1.MultiClusterEngineAvailable, events[0].Type)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package certrotation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// caValidity is how long the generated CA is valid
+	caValidity = 10 * 365 * 24 * time.Hour
+	// certValidity is how long a generated serving certificate is valid
+	certValidity = 365 * 24 * time.Hour
+	// rotationThreshold is the remaining validity below which a certificate is replaced
+	rotationThreshold = 30 * 24 * time.Hour
+)
+
+// keyPair is a PEM encoded certificate and its private key
+type keyPair struct {
+	cert []byte
+	key  []byte
+}
+
+// newCA generates a self-signed CA
+func newCA(now time.Time) (keyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return keyPair{}, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return keyPair{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("multicluster-engine-webhook-ca@%d", now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return keyPair{}, err
+	}
+	return encode(der, key), nil
+}
+
+// newServingCert generates a serving certificate for the DNS names of the service, signed by the CA
+func newServingCert(ca keyPair, service, namespace string, now time.Time) (keyPair, error) {
+	caCert, caKey, err := decode(ca)
+	if err != nil {
+		return keyPair{}, err
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return keyPair{}, err
+	}
+	notAfter := now.Add(certValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return keyPair{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("%s.%s.svc", service, namespace)},
+		DNSNames: []string{
+			service,
+			fmt.Sprintf("%s.%s", service, namespace),
+			fmt.Sprintf("%s.%s.svc", service, namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return keyPair{}, err
+	}
+	return encode(der, key), nil
+}
+
+// validFor returns true if the certificate is signed by the CA, serves the DNS name and stays valid for longer
+// than the rotation threshold
+func validFor(cert, caCert []byte, dnsName string, now time.Time) bool {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caCert) {
+		return false
+	}
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return false
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	if now.Add(rotationThreshold).After(parsed.NotAfter) {
+		return false
+	}
+	_, err = parsed.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots, CurrentTime: now})
+	return err == nil
+}
+
+// caValid returns true if the CA can be used to sign serving certificates for longer than the rotation threshold
+func caValid(ca keyPair, now time.Time) bool {
+	caCert, _, err := decode(ca)
+	if err != nil {
+		return false
+	}
+	return caCert.IsCA && now.Add(rotationThreshold).Before(caCert.NotAfter)
+}
+
+func encode(der []byte, key *rsa.PrivateKey) keyPair {
+	return keyPair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}
+}
+
+func decode(kp keyPair) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(kp.cert)
+	keyBlock, _ := pem.Decode(kp.key)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, errors.New("invalid PEM encoded key pair")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pub, ok := cert.PublicKey.(*rsa.PublicKey); !ok || !bytes.Equal(pub.N.Bytes(), key.PublicKey.N.Bytes()) {
+		return nil, nil, errors.New("certificate does not match its private key")
+	}
+	return cert, key, nil
+}
+
+// serialNumber returns a random 128 bit serial number
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
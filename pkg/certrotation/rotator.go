@@ -0,0 +1,254 @@
+// Copyright Contributors to the Open Cluster Management project
+
+// Package certrotation issues and rotates the serving certificate of the operator's webhooks, for installs where
+// neither OLM nor the OpenShift service CA provide one.
+package certrotation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// caCertKey and caPrivateKeyKey hold the CA in the certificate Secret, next to the serving certificate in the
+	// standard tls.crt and tls.key
+	caCertKey       = "ca.crt"
+	caPrivateKeyKey = "ca.key"
+
+	// checkInterval is how often the certificate and the CA bundles of the webhooks are checked
+	checkInterval = time.Minute
+	// injectCABundleAnnotation asks the OpenShift service CA operator to inject its CA bundle, which would
+	// replace the one set by the rotator
+	injectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+)
+
+var log = logf.Log.WithName("cert-rotation")
+
+// Rotator keeps a self-signed serving certificate for the webhook service in a Secret, writes it to the
+// directory the webhook server reads it from, and sets its CA as the CA bundle of the webhook configurations.
+// Every replica of the operator runs a Rotator, since each serves the webhook. Replicas share the certificate
+// through the Secret.
+type Rotator struct {
+	// Client must read Secrets directly from the API server, as the Secret is read before the cache starts
+	Client client.Client
+	// Namespace of the webhook service and the certificate Secret
+	Namespace string
+	// SecretName is the Secret holding the certificate
+	SecretName string
+	// ServiceName is the webhook service the certificate is issued for
+	ServiceName string
+	// CertDir is the directory the webhook server reads tls.crt and tls.key from
+	CertDir string
+	// ValidatingWebhook is the name of the validating webhook configuration whose CA bundle is set
+	ValidatingWebhook string
+	// MutatingWebhook is the name of the mutating webhook configuration whose CA bundle is set
+	MutatingWebhook string
+
+	now func() time.Time
+}
+
+// Start keeps the certificate and the CA bundles up to date until the context is done. It implements
+// manager.Runnable.
+func (r *Rotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.EnsureCert(ctx); err != nil {
+			log.Error(err, "Failed to rotate the webhook certificate")
+		} else if err := r.ensureCABundles(ctx); err != nil {
+			log.Error(err, "Failed to set the CA bundle of the webhooks")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica run the Rotator, so each has the certificate to serve the webhook
+func (r *Rotator) NeedLeaderElection() bool {
+	return false
+}
+
+// EnsureCert creates the certificate Secret, renews the certificate before it expires and writes it to the
+// certificate directory. The webhook server fails to start without a certificate, so this is called once before
+// the manager starts.
+func (r *Rotator) EnsureCert(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: r.SecretName, Namespace: r.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: r.SecretName, Namespace: r.Namespace},
+			Type:       corev1.SecretTypeTLS,
+		}
+		if err := r.issue(secret); err != nil {
+			return err
+		}
+		log.Info("Creating the webhook certificate", "secret", r.SecretName)
+		err := r.Client.Create(ctx, secret)
+		if apierrors.IsAlreadyExists(err) {
+			// Another replica created it first. Use theirs.
+			err = r.Client.Get(ctx, types.NamespacedName{Name: r.SecretName, Namespace: r.Namespace}, secret)
+		}
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if !r.valid(secret) {
+		if err := r.issue(secret); err != nil {
+			return err
+		}
+		log.Info("Rotating the webhook certificate", "secret", r.SecretName)
+		if err := r.Client.Update(ctx, secret); err != nil {
+			return err
+		}
+	}
+	return r.writeCert(secret)
+}
+
+// valid returns true if the certificate in the secret is issued for the service by its CA and is not about to
+// expire
+func (r *Rotator) valid(secret *corev1.Secret) bool {
+	ca := keyPair{cert: secret.Data[caCertKey], key: secret.Data[caPrivateKeyKey]}
+	if !caValid(ca, r.clock()) {
+		return false
+	}
+	dnsName := fmt.Sprintf("%s.%s.svc", r.ServiceName, r.Namespace)
+	return validFor(secret.Data[corev1.TLSCertKey], ca.cert, dnsName, r.clock())
+}
+
+// issue sets a new serving certificate in the secret. The CA is kept while it is valid, so the CA bundle of the
+// webhooks doesn't change and replicas still serving the previous certificate are trusted.
+func (r *Rotator) issue(secret *corev1.Secret) error {
+	now := r.clock()
+	ca := keyPair{cert: secret.Data[caCertKey], key: secret.Data[caPrivateKeyKey]}
+	if !caValid(ca, now) {
+		var err error
+		if ca, err = newCA(now); err != nil {
+			return err
+		}
+	}
+	serving, err := newServingCert(ca, r.ServiceName, r.Namespace, now)
+	if err != nil {
+		return err
+	}
+	secret.Data = map[string][]byte{
+		caCertKey:               ca.cert,
+		caPrivateKeyKey:         ca.key,
+		corev1.TLSCertKey:       serving.cert,
+		corev1.TLSPrivateKeyKey: serving.key,
+	}
+	return nil
+}
+
+// writeCert writes the serving certificate to the certificate directory if it changed. The webhook server
+// reloads it from there.
+func (r *Rotator) writeCert(secret *corev1.Secret) error {
+	if err := os.MkdirAll(r.CertDir, 0700); err != nil {
+		return err
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		path := filepath.Join(r.CertDir, key)
+		if existing, err := ioutil.ReadFile(path); err == nil && bytes.Equal(existing, secret.Data[key]) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, secret.Data[key], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CABundle returns the CA of the certificate, to be set as the CA bundle of the webhook configurations
+func (r *Rotator) CABundle(ctx context.Context) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: r.SecretName, Namespace: r.Namespace}, secret); err != nil {
+		return nil, err
+	}
+	return secret.Data[caCertKey], nil
+}
+
+// ensureCABundles sets the CA of the certificate as the CA bundle of the webhook configurations
+func (r *Rotator) ensureCABundles(ctx context.Context) error {
+	bundle, err := r.CABundle(ctx)
+	if err != nil {
+		return err
+	}
+
+	if r.ValidatingWebhook != "" {
+		webhook := &admissionregistration.ValidatingWebhookConfiguration{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: r.ValidatingWebhook}, webhook)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		} else if err == nil {
+			changed := removeInjectAnnotation(webhook)
+			for i := range webhook.Webhooks {
+				if !bytes.Equal(webhook.Webhooks[i].ClientConfig.CABundle, bundle) {
+					webhook.Webhooks[i].ClientConfig.CABundle = bundle
+					changed = true
+				}
+			}
+			if changed {
+				if err := r.Client.Update(ctx, webhook); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if r.MutatingWebhook != "" {
+		webhook := &admissionregistration.MutatingWebhookConfiguration{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: r.MutatingWebhook}, webhook)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		} else if err == nil {
+			changed := removeInjectAnnotation(webhook)
+			for i := range webhook.Webhooks {
+				if !bytes.Equal(webhook.Webhooks[i].ClientConfig.CABundle, bundle) {
+					webhook.Webhooks[i].ClientConfig.CABundle = bundle
+					changed = true
+				}
+			}
+			if changed {
+				if err := r.Client.Update(ctx, webhook); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// removeInjectAnnotation removes the annotation asking the OpenShift service CA operator to inject its CA
+// bundle, and returns true if it was set
+func removeInjectAnnotation(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[injectCABundleAnnotation]; !ok {
+		return false
+	}
+	delete(annotations, injectCABundleAnnotation)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+func (r *Rotator) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
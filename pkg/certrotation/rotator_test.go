@@ -0,0 +1,115 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package certrotation
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testRotator(t *testing.T, c client.Client) *Rotator {
+	return &Rotator{
+		Client:            c,
+		Namespace:         "mce",
+		SecretName:        "webhook-cert",
+		ServiceName:       "webhook-service",
+		CertDir:           t.TempDir(),
+		ValidatingWebhook: "mce-webhook",
+	}
+}
+
+func Test_EnsureCert(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().Build()
+	r := testRotator(t, c)
+
+	if err := r.EnsureCert(ctx); err != nil {
+		t.Fatalf("Failed to issue the certificate: %v", err)
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "webhook-cert", Namespace: "mce"}, secret); err != nil {
+		t.Fatalf("Expected the certificate secret to be created: %v", err)
+	}
+	if !validFor(secret.Data[corev1.TLSCertKey], secret.Data[caCertKey], "webhook-service.mce.svc", time.Now()) {
+		t.Errorf("Expected a certificate for the webhook service signed by the CA")
+	}
+	written, err := ioutil.ReadFile(filepath.Join(r.CertDir, corev1.TLSCertKey))
+	if err != nil || !bytes.Equal(written, secret.Data[corev1.TLSCertKey]) {
+		t.Errorf("Expected the certificate to be written to the certificate directory")
+	}
+
+	t.Run("Valid certificate is kept", func(t *testing.T) {
+		if err := r.EnsureCert(ctx); err != nil {
+			t.Fatal(err)
+		}
+		current := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: "webhook-cert", Namespace: "mce"}, current); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(current.Data[corev1.TLSCertKey], secret.Data[corev1.TLSCertKey]) {
+			t.Errorf("Expected the certificate not to be rotated")
+		}
+	})
+
+	t.Run("Expiring certificate is rotated with the same CA", func(t *testing.T) {
+		r.now = func() time.Time { return time.Now().Add(certValidity - rotationThreshold/2) }
+		defer func() { r.now = nil }()
+		if err := r.EnsureCert(ctx); err != nil {
+			t.Fatal(err)
+		}
+		current := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: "webhook-cert", Namespace: "mce"}, current); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(current.Data[corev1.TLSCertKey], secret.Data[corev1.TLSCertKey]) {
+			t.Errorf("Expected the certificate to be rotated")
+		}
+		if !bytes.Equal(current.Data[caCertKey], secret.Data[caCertKey]) {
+			t.Errorf("Expected the CA to be kept")
+		}
+	})
+}
+
+func Test_EnsureCABundles(t *testing.T) {
+	ctx := context.Background()
+	webhook := &admissionregistration.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mce-webhook",
+			Annotations: map[string]string{injectCABundleAnnotation: "true"},
+		},
+		Webhooks: []admissionregistration.ValidatingWebhook{{Name: "mce.example.com"}},
+	}
+	c := fake.NewClientBuilder().WithObjects(webhook).Build()
+	r := testRotator(t, c)
+	if err := r.EnsureCert(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.ensureCABundles(ctx); err != nil {
+		t.Fatalf("Failed to set the CA bundle: %v", err)
+	}
+	bundle, err := r.CABundle(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "mce-webhook"}, webhook); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(webhook.Webhooks[0].ClientConfig.CABundle, bundle) {
+		t.Errorf("Expected the CA bundle of the webhook to be the rotator's CA")
+	}
+	if _, ok := webhook.GetAnnotations()[injectCABundleAnnotation]; ok {
+		t.Errorf("Expected the service CA injection annotation to be removed")
+	}
+}
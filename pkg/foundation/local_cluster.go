@@ -0,0 +1,56 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package foundation
+
+import (
+	"context"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LocalClusterName is the name of the ManagedCluster the hub is imported as
+	LocalClusterName = "local-cluster"
+	// LocalClusterLabel marks the ManagedCluster of the hub. The import controller imports a cluster with this
+	// label on the hub itself, without a separate import step.
+	LocalClusterLabel     = "local-cluster"
+	managedClusterCRDName = "managedclusters.cluster.open-cluster-management.io"
+)
+
+// LocalCluster returns the ManagedCluster the hub is imported as. The cloud and vendor labels are detected once
+// the cluster is imported.
+func LocalCluster(m *v1.MultiClusterEngine) (*unstructured.Unstructured, error) {
+	mc := &clusterv1.ManagedCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "cluster.open-cluster-management.io/v1",
+			Kind:       "ManagedCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: LocalClusterName,
+			Labels: map[string]string{
+				LocalClusterLabel: "true",
+				"cloud":           "auto-detect",
+				"vendor":          "auto-detect",
+			},
+		},
+		Spec: clusterv1.ManagedClusterSpec{
+			HubAcceptsClient: true,
+		},
+	}
+	utils.AddBackplaneConfigLabels(mc, m.GetName())
+	return utils.CoreToUnstructured(mc)
+}
+
+// CanManageClusters returns true if the ManagedCluster API is installed
+func CanManageClusters(ctx context.Context, client client.Client) bool {
+	crd := &apixv1.CustomResourceDefinition{}
+	err := client.Get(ctx, types.NamespacedName{Name: managedClusterCRDName}, crd)
+	return err == nil
+}
@@ -33,9 +33,16 @@ type ManifestImage struct {
 }
 
 // GetImagesWithOverrides gets images from the environment, then updates them based on MCE annotations
-func GetImagesWithOverrides(kubeclient client.Client, mce *backplanev1.MultiClusterEngine) (map[string]string, error) {
-	// Get images from environment
-	images := GetImages()
+func GetImagesWithOverrides(kubeclient client.Reader, mce *backplanev1.MultiClusterEngine) (map[string]string, error) {
+	return OverrideImages(kubeclient, mce, GetImages())
+}
+
+// OverrideImages updates the images based on the MCE spec and annotations
+func OverrideImages(kubeclient client.Reader, mce *backplanev1.MultiClusterEngine, images map[string]string) (map[string]string, error) {
+	// Point images at a mirror registry if one is configured
+	if mce.Spec.Overrides != nil && mce.Spec.Overrides.ImageRegistry != "" {
+		images = OverrideImageRegistry(images, mce.Spec.Overrides.ImageRegistry)
+	}
 
 	// Override image repository if dev annotation present
 	if imageRepo := utils.GetImageRepository(mce); imageRepo != "" {
@@ -56,9 +63,31 @@ func GetImagesWithOverrides(kubeclient client.Client, mce *backplanev1.MultiClus
 		}
 	}
 
+	// Tags are pinned last, so the images overridden above are pinned too
+	if mce.Spec.Overrides != nil && mce.Spec.Overrides.ResolveImageDigests {
+		return DefaultResolver.Resolve(context.TODO(), kubeclient, pullSecret(mce), images)
+	}
+
 	return images, nil
 }
 
+// GetImageArchitectures returns the architectures each image is built for, keyed by image reference, if
+// architecture affinity is enabled on the MCE. It returns nil otherwise.
+func GetImageArchitectures(kubeclient client.Reader, mce *backplanev1.MultiClusterEngine, images map[string]string) (map[string][]string, error) {
+	if mce.Spec.Overrides == nil || !mce.Spec.Overrides.ArchitectureAffinity {
+		return nil, nil
+	}
+	return DefaultResolver.Architectures(context.TODO(), kubeclient, pullSecret(mce), images)
+}
+
+// pullSecret returns the image pull secret of the MCE, or an empty name if it has none
+func pullSecret(mce *backplanev1.MultiClusterEngine) types.NamespacedName {
+	if mce.Spec.ImagePullSecret == "" {
+		return types.NamespacedName{}
+	}
+	return types.NamespacedName{Name: mce.Spec.ImagePullSecret, Namespace: utils.OperatorNamespace()}
+}
+
 // GetImages creates an image map from the environment
 func GetImages() map[string]string {
 	OperandImagePrefix := "OPERAND_IMAGE_"
@@ -100,6 +129,30 @@ func OverrideImageRepository(images map[string]string, imageRepo string) map[str
 	return images
 }
 
+// OverrideImageRegistry replaces the registry of each image, keeping its repository path and tag or digest. An
+// image without a registry is prefixed with the new one.
+func OverrideImageRegistry(images map[string]string, registry string) map[string]string {
+	registry = strings.TrimSuffix(registry, "/")
+	for imageKey, imageRef := range images {
+		images[imageKey] = fmt.Sprintf("%s/%s", registry, imagePath(imageRef))
+	}
+	return images
+}
+
+// imagePath returns the image reference without its registry. The first component of a reference is a registry
+// if it is localhost or contains a '.' or ':', following the Docker reference grammar.
+func imagePath(imageRef string) string {
+	i := strings.Index(imageRef, "/")
+	if i < 0 {
+		return imageRef
+	}
+	first := imageRef[:i]
+	if first == "localhost" || strings.ContainsAny(first, ".:") {
+		return imageRef[i+1:]
+	}
+	return imageRef
+}
+
 // OverrideImagesWithConfigmap updates an image map with images defined in configmap
 func OverrideImagesWithConfigmap(images map[string]string, configmap *corev1.ConfigMap) (map[string]string, error) {
 	if len(configmap.Data) != 1 {
@@ -107,19 +160,28 @@ func OverrideImagesWithConfigmap(images map[string]string, configmap *corev1.Con
 	}
 
 	for _, v := range configmap.Data {
-		var manifestImages []ManifestImage
-		err := json.Unmarshal([]byte(v), &manifestImages)
+		var err error
+		images, err = parseManifest([]byte(v), images)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return images, nil
+}
 
-		for _, manifestImage := range manifestImages {
-			if manifestImage.ImageDigest != "" {
-				images[manifestImage.ImageKey] = fmt.Sprintf("%s/%s@%s", manifestImage.ImageRemote, manifestImage.ImageName, manifestImage.ImageDigest)
-			} else if manifestImage.ImageTag != "" {
-				images[manifestImage.ImageKey] = fmt.Sprintf("%s/%s:%s", manifestImage.ImageRemote, manifestImage.ImageName, manifestImage.ImageTag)
-			}
+// parseManifest adds the images of a JSON list of ManifestImages to the image map
+func parseManifest(data []byte, images map[string]string) (map[string]string, error) {
+	var manifestImages []ManifestImage
+	err := json.Unmarshal(data, &manifestImages)
+	if err != nil {
+		return nil, err
+	}
 
+	for _, manifestImage := range manifestImages {
+		if manifestImage.ImageDigest != "" {
+			images[manifestImage.ImageKey] = fmt.Sprintf("%s/%s@%s", manifestImage.ImageRemote, manifestImage.ImageName, manifestImage.ImageDigest)
+		} else if manifestImage.ImageTag != "" {
+			images[manifestImage.ImageKey] = fmt.Sprintf("%s/%s:%s", manifestImage.ImageRemote, manifestImage.ImageName, manifestImage.ImageTag)
 		}
 	}
 	return images, nil
@@ -6,8 +6,11 @@ import (
 	"reflect"
 	"testing"
 
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_GetImages(t *testing.T) {
@@ -64,6 +67,53 @@ func TestOverrideImageRepository(t *testing.T) {
 	}
 }
 
+func TestOverrideImageRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		images   map[string]string
+		registry string
+		want     map[string]string
+	}{
+		{
+			name: "Replace registry and keep tag",
+			images: map[string]string{
+				"discovery_operator": "registry.redhat.io/multicluster-engine/discovery-rhel8:v2.0.0",
+			},
+			registry: "mirror.example.com",
+			want: map[string]string{
+				"discovery_operator": "mirror.example.com/multicluster-engine/discovery-rhel8:v2.0.0",
+			},
+		},
+		{
+			name: "Replace registry with port and keep digest",
+			images: map[string]string{
+				"hive": "localhost:5000/openshift-hive/hive@sha256:9dc4d072dcd06eda3fda19a15f4b84677fbbbde2a476b4817272cde4724f02cc",
+			},
+			registry: "mirror.example.com:8443/",
+			want: map[string]string{
+				"hive": "mirror.example.com:8443/openshift-hive/hive@sha256:9dc4d072dcd06eda3fda19a15f4b84677fbbbde2a476b4817272cde4724f02cc",
+			},
+		},
+		{
+			name: "Prefix image without registry",
+			images: map[string]string{
+				"console_mce": "stolostron/console-mce:latest",
+			},
+			registry: "mirror.example.com",
+			want: map[string]string{
+				"console_mce": "mirror.example.com/stolostron/console-mce:latest",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverrideImageRegistry(tt.images, tt.registry); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OverrideImageRegistry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestOverrideImagesWithConfigmap(t *testing.T) {
 	testCM := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -147,3 +197,36 @@ func TestOverrideImagesWithConfigmap(t *testing.T) {
 		})
 	}
 }
+
+func TestGetImagesWithOverrides(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "test")
+	t.Setenv("OPERAND_IMAGE_DISCOVERY_OPERATOR", "registry.redhat.io/multicluster-engine/discovery-rhel8:v2.0.0")
+	t.Setenv("OPERAND_IMAGE_HIVE", "registry.redhat.io/multicluster-engine/hive-rhel8:v2.0.0")
+
+	overrides := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-overrides", Namespace: "test"},
+		Data: map[string]string{
+			"overrides.json": `[{"image-key": "discovery_operator", "image-remote": "quay.io/stolostron", "image-name": "discovery-operator", "image-tag": "latest"}]`,
+		},
+	}
+	mce := &backplanev1.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{utils.AnnotationImageOverridesCM: "image-overrides"},
+		},
+		Spec: backplanev1.MultiClusterEngineSpec{
+			Overrides: &backplanev1.Overrides{ImageRegistry: "mirror.example.com"},
+		},
+	}
+
+	got, err := GetImagesWithOverrides(fake.NewClientBuilder().WithObjects(overrides).Build(), mce)
+	if err != nil {
+		t.Fatalf("GetImagesWithOverrides() error = %v", err)
+	}
+	want := map[string]string{
+		"discovery_operator": "quay.io/stolostron/discovery-operator:latest",
+		"hive":               "mirror.example.com/multicluster-engine/hive-rhel8:v2.0.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetImagesWithOverrides() = %v, want %v", got, want)
+	}
+}
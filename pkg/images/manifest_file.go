@@ -0,0 +1,120 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// manifestCheckInterval is how often the image manifest file is checked for changes. The kubelet takes up to a
+// minute to update a mounted ConfigMap, so checking more often gains little.
+const manifestCheckInterval = 10 * time.Second
+
+var log = logf.Log.WithName("image-manifest")
+
+// ManifestFile reads the operand images from an image manifest file, as an alternative to the OPERAND_IMAGE_
+// environment variables. The file holds the same JSON list of images as the image overrides ConfigMap, and is
+// typically a mounted ConfigMap. The file is reloaded when it changes, so the images can be updated without
+// restarting the operator.
+type ManifestFile struct {
+	Path string
+	// OnChange is called after the file changed and was reloaded, whether or not it could be read
+	OnChange func()
+
+	mu     sync.Mutex
+	images map[string]string
+	sum    [sha256.Size]byte
+	err    error
+}
+
+// Load reads the image manifest file. It fails if the file can't be read or holds no images.
+func (m *ManifestFile) Load() error {
+	data, err := ioutil.ReadFile(m.Path)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.load(data)
+	return m.err
+}
+
+func (m *ManifestFile) load(data []byte) {
+	m.sum = sha256.Sum256(data)
+	images, err := parseManifest(data, map[string]string{})
+	if err == nil && len(images) == 0 {
+		err = fmt.Errorf("no images in image manifest %s", m.Path)
+	}
+	if err != nil {
+		m.err = fmt.Errorf("failed to read image manifest %s: %w", m.Path, err)
+		return
+	}
+	m.images, m.err = images, nil
+}
+
+// Images returns a copy of the images in the manifest. If the file could not be read when it last changed, the
+// error is returned, so the images of the broken manifest are not silently replaced by older ones.
+func (m *ManifestFile) Images() (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	images := make(map[string]string, len(m.images))
+	for k, v := range m.images {
+		images[k] = v
+	}
+	return images, nil
+}
+
+// Start reloads the image manifest file whenever its content changes, until the context is done. It implements
+// manager.Runnable.
+func (m *ManifestFile) Start(ctx context.Context) error {
+	ticker := time.NewTicker(manifestCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if m.reload() && m.OnChange != nil {
+			m.OnChange()
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica keep its images up to date, so a new leader starts with the current ones
+func (m *ManifestFile) NeedLeaderElection() bool {
+	return false
+}
+
+// reload reads the file again if its content changed, returning true if it did
+func (m *ManifestFile) reload() bool {
+	data, err := ioutil.ReadFile(m.Path)
+	if err != nil {
+		// A mounted ConfigMap is briefly missing while the kubelet swaps it, so the file is read again on the
+		// next check rather than reported as broken
+		log.Error(err, "Failed to read image manifest", "path", m.Path)
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sha256.Sum256(data) == m.sum {
+		return false
+	}
+	m.load(data)
+	if m.err != nil {
+		log.Error(m.err, "Image manifest changed but can't be used")
+	} else {
+		log.Info("Image manifest reloaded", "path", m.Path, "images", len(m.images))
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package images
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`[{"image-key": "hive", "image-remote": "quay.io/stolostron", "image-name": "hive", "image-tag": "2.1"}]`)
+
+	m := &ManifestFile{Path: path}
+	if err := m.Load(); err != nil {
+		t.Fatalf("Failed to load the image manifest: %v", err)
+	}
+	images, err := m.Images()
+	if err != nil || images["hive"] != "quay.io/stolostron/hive:2.1" {
+		t.Errorf("Images() = %v, %v, want the hive image from the manifest", images, err)
+	}
+
+	t.Run("Unchanged file is not reloaded", func(t *testing.T) {
+		if m.reload() {
+			t.Errorf("Expected the unchanged manifest not to be reloaded")
+		}
+	})
+
+	t.Run("Changed file is reloaded", func(t *testing.T) {
+		write(`[{"image-key": "hive", "image-remote": "quay.io/stolostron", "image-name": "hive", "image-digest": "sha256:abc"}]`)
+		if !m.reload() {
+			t.Fatalf("Expected the changed manifest to be reloaded")
+		}
+		images, err := m.Images()
+		if err != nil || images["hive"] != "quay.io/stolostron/hive@sha256:abc" {
+			t.Errorf("Images() = %v, %v, want the updated hive image", images, err)
+		}
+	})
+
+	t.Run("Broken file is reported", func(t *testing.T) {
+		write(`not json`)
+		if !m.reload() {
+			t.Fatalf("Expected the changed manifest to be reloaded")
+		}
+		if _, err := m.Images(); err == nil {
+			t.Errorf("Expected an error for a manifest that can't be read")
+		}
+	})
+
+	t.Run("Empty file fails to load", func(t *testing.T) {
+		write(`[]`)
+		if err := (&ManifestFile{Path: path}).Load(); err == nil {
+			t.Errorf("Expected an error for a manifest without images")
+		}
+	})
+}
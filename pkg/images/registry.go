@@ -0,0 +1,293 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// dockerHub is the registry of references that don't name one
+	dockerHub = "docker.io"
+	// dockerHubAPI serves the registry API of Docker Hub
+	dockerHubAPI = "registry-1.docker.io"
+	// maxManifestSize bounds the manifest read when the registry doesn't return its digest
+	maxManifestSize = 4 << 20
+)
+
+// manifestMediaTypes are the manifests accepted from a registry. Manifest lists come first, so a multi-arch
+// image resolves to the digest of the list rather than of one architecture.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// challengeParam matches a parameter of a WWW-Authenticate challenge
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// reference is a parsed image reference
+type reference struct {
+	registry string
+	path     string
+	tag      string
+	digest   string
+}
+
+// parseReference splits an image reference into its registry, repository path and tag or digest. The first
+// component of a reference is a registry if it is localhost or contains a '.' or ':', following the Docker
+// reference grammar. A reference without a tag or digest refers to the latest tag.
+func parseReference(image string) (reference, error) {
+	ref := reference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.digest = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.tag = name[:i], name[i+1:]
+	}
+	if ref.digest == "" && ref.tag == "" {
+		ref.tag = "latest"
+	}
+
+	ref.path = imagePath(name)
+	if ref.path != name {
+		ref.registry = strings.TrimSuffix(name, "/"+ref.path)
+	} else {
+		ref.registry = dockerHub
+		if !strings.Contains(ref.path, "/") {
+			ref.path = "library/" + ref.path
+		}
+	}
+	if ref.path == "" {
+		return reference{}, errors.New("missing repository")
+	}
+	return ref, nil
+}
+
+// repository returns the registry and path of the reference
+func (ref reference) repository() string {
+	return ref.registry + "/" + ref.path
+}
+
+// inRepository returns the reference with its repository replaced, keeping its tag or digest
+func (ref reference) inRepository(repository string) (reference, error) {
+	if ref.digest != "" {
+		return parseReference(repository + "@" + ref.digest)
+	}
+	return parseReference(repository + ":" + ref.tag)
+}
+
+// manifestID returns the digest of the reference if it has one and its tag otherwise
+func (ref reference) manifestID() string {
+	if ref.digest != "" {
+		return ref.digest
+	}
+	return ref.tag
+}
+
+// withDigest returns the reference pinned to the digest
+func (ref reference) withDigest(digest string) string {
+	return ref.repository() + "@" + digest
+}
+
+// apiHost returns the host serving the registry API of the reference
+func (ref reference) apiHost() string {
+	if ref.registry == dockerHub {
+		return dockerHubAPI
+	}
+	return ref.registry
+}
+
+// manifestDigest returns the digest of the manifest the tag of the reference points to
+func (r *Resolver) manifestDigest(ctx context.Context, ref reference, auth *registryAuth) (string, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.apiHost(), ref.path, ref.tag)
+	resp, err := r.requestManifest(ctx, http.MethodHead, manifestURL, ref, auth)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// Not every registry returns the digest, in which case it is computed from the manifest
+	resp, err = r.requestManifest(ctx, http.MethodGet, manifestURL, ref, auth)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	manifest, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)), nil
+}
+
+// imageManifest holds the fields of an image manifest, or manifest list, that tell the architectures of the image
+type imageManifest struct {
+	Manifests []struct {
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// manifestArchitectures returns the Linux architectures the image of the reference is built for. A manifest list
+// names the platform of each of its images, while an image manifest leaves its architecture to the image config.
+func (r *Resolver) manifestArchitectures(ctx context.Context, ref reference, auth *registryAuth) ([]string, error) {
+	manifest := imageManifest{}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.apiHost(), ref.path, ref.manifestID())
+	if err := r.getJSON(ctx, manifestURL, ref, auth, &manifest); err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Manifests) > 0 {
+		seen := map[string]bool{}
+		architectures := []string{}
+		for _, m := range manifest.Manifests {
+			// Lists also hold attestations, with an unknown platform
+			if m.Platform.OS != "linux" || m.Platform.Architecture == "" || seen[m.Platform.Architecture] {
+				continue
+			}
+			seen[m.Platform.Architecture] = true
+			architectures = append(architectures, m.Platform.Architecture)
+		}
+		sort.Strings(architectures)
+		return architectures, nil
+	}
+
+	if manifest.Config.Digest == "" {
+		return nil, errors.New("manifest has no config")
+	}
+	config := struct {
+		Architecture string `json:"architecture"`
+	}{}
+	configURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.apiHost(), ref.path, manifest.Config.Digest)
+	if err := r.getJSON(ctx, configURL, ref, auth, &config); err != nil {
+		return nil, err
+	}
+	if config.Architecture == "" {
+		return nil, errors.New("image config has no architecture")
+	}
+	return []string{config.Architecture}, nil
+}
+
+// getJSON reads a manifest or blob of the registry into v
+func (r *Resolver) getJSON(ctx context.Context, registryURL string, ref reference, auth *registryAuth, v interface{}) error {
+	resp, err := r.requestManifest(ctx, http.MethodGet, registryURL, ref, auth)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(v)
+}
+
+// requestManifest requests a manifest, or a blob, authenticating as the registry asks if it refuses anonymous access
+func (r *Resolver) requestManifest(ctx context.Context, method, manifestURL string, ref reference, auth *registryAuth) (*http.Response, error) {
+	resp, err := r.send(ctx, method, manifestURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		authorization, err := r.authorize(ctx, resp.Header.Get("WWW-Authenticate"), ref, auth)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = r.send(ctx, method, manifestURL, authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s", resp.Status)
+	}
+	return resp, nil
+}
+
+func (r *Resolver) send(ctx context.Context, method, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return r.HTTPClient.Do(req)
+}
+
+// authorize answers the WWW-Authenticate challenge of a registry, returning the Authorization header to send.
+// A bearer challenge is answered with a token for pulling the repository, requested with the credentials if
+// there are any.
+func (r *Resolver) authorize(ctx context.Context, challenge string, ref reference, auth *registryAuth) (string, error) {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	switch scheme {
+	case "basic":
+		if auth == nil {
+			return "", fmt.Errorf("no credentials for %s", ref.registry)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(auth.username, auth.password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge without realm %q", challenge)
+	}
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.path))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth != nil {
+		req.SetBasicAuth(auth.username, auth.password)
+	}
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned %s", resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
@@ -0,0 +1,330 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package images
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// imageContentSourcePolicies and imageDigestMirrorSets configure the mirrors the cluster pulls images by
+	// digest from. Either API may be missing, depending on the OpenShift version.
+	imageContentSourcePolicies = schema.GroupVersionKind{Group: "operator.openshift.io", Version: "v1alpha1", Kind: "ImageContentSourcePolicyList"}
+	imageDigestMirrorSets      = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageDigestMirrorSetList"}
+
+	// clusterPullSecret holds the credentials the cluster pulls images with
+	clusterPullSecret = types.NamespacedName{Name: "pull-secret", Namespace: "openshift-config"}
+)
+
+// DefaultResolver is the Resolver used when resolving image digests is enabled on the multiclusterengine
+var DefaultResolver = &Resolver{HTTPClient: &http.Client{Timeout: 30 * time.Second}}
+
+// Resolver replaces image tags with the digests they point to, so every component pod runs exactly the image
+// the operator resolved, however the tag moves later. The digest is looked up in the mirrors configured by
+// ImageContentSourcePolicies and ImageDigestMirrorSets before the source registry, so resolution works in a
+// disconnected cluster. The resolved reference keeps the source repository, which the cluster maps to the
+// same mirrors when pulling by digest.
+type Resolver struct {
+	HTTPClient *http.Client
+
+	mu sync.Mutex
+	// digests caches the digest of each resolved tag reference, so tags are resolved once per operator run
+	digests map[string]string
+	// architectures caches the architectures of each image reference
+	architectures map[string][]string
+}
+
+// Resolve returns the images with every tag reference replaced by a digest reference. References already
+// pinned to a digest are kept.
+func (r *Resolver) Resolve(ctx context.Context, kubeclient client.Reader, pullSecret types.NamespacedName, images map[string]string) (map[string]string, error) {
+	var access *registryAccess
+	resolved := make(map[string]string, len(images))
+	for key, image := range images {
+		ref, err := parseReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %s: %w", image, err)
+		}
+		if ref.digest != "" {
+			resolved[key] = image
+			continue
+		}
+		if digest, ok := r.cached(image); ok {
+			resolved[key] = ref.withDigest(digest)
+			continue
+		}
+
+		// Mirrors and credentials are only read once something needs resolving
+		if access == nil {
+			if access, err = readRegistryAccess(ctx, kubeclient, pullSecret); err != nil {
+				return nil, err
+			}
+		}
+
+		var digest string
+		err = access.lookup(ref, func(candidate reference, auth *registryAuth) (err error) {
+			digest, err = r.manifestDigest(ctx, candidate, auth)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the digest of %s: %w", image, err)
+		}
+		r.cache(image, digest)
+		resolved[key] = ref.withDigest(digest)
+	}
+	return resolved, nil
+}
+
+// Architectures returns the Linux architectures each image is built for, keyed by image reference. They are
+// looked up in the mirrors of the image like digests are, and are cached by image reference.
+func (r *Resolver) Architectures(ctx context.Context, kubeclient client.Reader, pullSecret types.NamespacedName, images map[string]string) (map[string][]string, error) {
+	var access *registryAccess
+	result := make(map[string][]string, len(images))
+	for _, image := range images {
+		if _, ok := result[image]; ok {
+			continue
+		}
+		if architectures, ok := r.cachedArchitectures(image); ok {
+			result[image] = architectures
+			continue
+		}
+		ref, err := parseReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %s: %w", image, err)
+		}
+		if access == nil {
+			if access, err = readRegistryAccess(ctx, kubeclient, pullSecret); err != nil {
+				return nil, err
+			}
+		}
+
+		var architectures []string
+		err = access.lookup(ref, func(candidate reference, auth *registryAuth) (err error) {
+			architectures, err = r.manifestArchitectures(ctx, candidate, auth)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the architectures of %s: %w", image, err)
+		}
+		r.cacheArchitectures(image, architectures)
+		result[image] = architectures
+	}
+	return result, nil
+}
+
+// registryAccess holds the mirrors and credentials images are looked up with
+type registryAccess struct {
+	mirrors []digestMirrors
+	creds   map[string]registryAuth
+}
+
+// readRegistryAccess reads the mirrors of the cluster and the credentials of the cluster pull secret and the
+// given pull secret
+func readRegistryAccess(ctx context.Context, kubeclient client.Reader, pullSecret types.NamespacedName) (*registryAccess, error) {
+	mirrors, err := listDigestMirrors(ctx, kubeclient)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := readCredentials(ctx, kubeclient, clusterPullSecret, pullSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &registryAccess{mirrors: mirrors, creds: creds}, nil
+}
+
+// lookup calls fn with the reference in each mirror of its repository in turn and then with the reference itself,
+// until a call succeeds
+func (a *registryAccess) lookup(ref reference, fn func(candidate reference, auth *registryAuth) error) error {
+	errs := []string{}
+	for _, repository := range append(mirrorRepositories(ref.repository(), a.mirrors), ref.repository()) {
+		candidate, err := ref.inRepository(repository)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		err = fn(candidate, credentialsFor(candidate, a.creds))
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", repository, err.Error()))
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+func (r *Resolver) cached(image string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	digest, ok := r.digests[image]
+	return digest, ok
+}
+
+func (r *Resolver) cache(image, digest string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.digests == nil {
+		r.digests = map[string]string{}
+	}
+	r.digests[image] = digest
+}
+
+func (r *Resolver) cachedArchitectures(image string) ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	architectures, ok := r.architectures[image]
+	return architectures, ok
+}
+
+func (r *Resolver) cacheArchitectures(image string, architectures []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.architectures == nil {
+		r.architectures = map[string][]string{}
+	}
+	r.architectures[image] = architectures
+}
+
+// digestMirrors is a source repository and the mirrors holding its images
+type digestMirrors struct {
+	source  string
+	mirrors []string
+}
+
+// listDigestMirrors returns the mirrors configured by the ImageContentSourcePolicies and ImageDigestMirrorSets
+// on the cluster
+func listDigestMirrors(ctx context.Context, kubeclient client.Reader) ([]digestMirrors, error) {
+	result := []digestMirrors{}
+	for _, source := range []struct {
+		gvk   schema.GroupVersionKind
+		field string
+	}{
+		{imageContentSourcePolicies, "repositoryDigestMirrors"},
+		{imageDigestMirrorSets, "imageDigestMirrors"},
+	} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(source.gvk)
+		err := kubeclient.List(ctx, list)
+		if err != nil && meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			entries, _, err := unstructured.NestedSlice(item.Object, "spec", source.field)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				fields, ok := entry.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				sourceRepo, _, _ := unstructured.NestedString(fields, "source")
+				mirrors, _, _ := unstructured.NestedStringSlice(fields, "mirrors")
+				if sourceRepo != "" && len(mirrors) > 0 {
+					result = append(result, digestMirrors{source: sourceRepo, mirrors: mirrors})
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// mirrorRepositories returns the repositories mirroring the given one, in the order configured. A source
+// matches the repository itself or any repository nested under it.
+func mirrorRepositories(repository string, mirrors []digestMirrors) []string {
+	result := []string{}
+	for _, m := range mirrors {
+		if repository != m.source && !strings.HasPrefix(repository, m.source+"/") {
+			continue
+		}
+		for _, mirror := range m.mirrors {
+			result = append(result, mirror+strings.TrimPrefix(repository, m.source))
+		}
+	}
+	return result
+}
+
+// registryAuth holds the credentials for a registry, or a repository in it
+type registryAuth struct {
+	username string
+	password string
+}
+
+// readCredentials returns the registry credentials of the pull secrets that exist, keyed as in a docker config.
+// Those of later secrets take precedence.
+func readCredentials(ctx context.Context, kubeclient client.Reader, secrets ...types.NamespacedName) (map[string]registryAuth, error) {
+	creds := map[string]registryAuth{}
+	for _, key := range secrets {
+		if key.Name == "" {
+			continue
+		}
+		secret := &corev1.Secret{}
+		err := kubeclient.Get(ctx, key, secret)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		config := struct {
+			Auths map[string]struct {
+				Auth     string `json:"auth"`
+				Username string `json:"username"`
+				Password string `json:"password"`
+			} `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return nil, fmt.Errorf("failed to read pull secret %s: %w", key.Name, err)
+		}
+		for registry, auth := range config.Auths {
+			if auth.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read pull secret %s: %w", key.Name, err)
+				}
+				if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+					auth.Username, auth.Password = parts[0], parts[1]
+				}
+			}
+			creds[registry] = registryAuth{username: auth.Username, password: auth.Password}
+		}
+	}
+	return creds, nil
+}
+
+// credentialsFor returns the credentials whose key is the longest prefix of the repository, as the container
+// runtime picks them
+func credentialsFor(ref reference, creds map[string]registryAuth) *registryAuth {
+	var match *registryAuth
+	matched := -1
+	for key, auth := range creds {
+		key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+		if key == "docker.io" || key == "index.docker.io" {
+			key = dockerHub
+		}
+		repository := ref.repository()
+		if key != ref.registry && repository != key && !strings.HasPrefix(repository, key+"/") {
+			continue
+		}
+		if len(key) > matched {
+			auth := auth
+			match, matched = &auth, len(key)
+		}
+	}
+	return match
+}
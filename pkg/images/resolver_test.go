@@ -0,0 +1,174 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// newTestRegistry serves the manifest list of mirror/stolostron/console:2.1 and the manifest of
+// mirror/stolostron/registration:2.1 to clients with a token issued for
+// the given credentials
+func newTestRegistry(t *testing.T, username, password string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+		case r.Header.Get("Authorization") != "Bearer pull-token":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/mirror/stolostron/console/manifests/2.1":
+			w.Header().Set("Docker-Content-Digest", testDigest)
+			_, _ = w.Write([]byte(`{"manifests": [
+				{"platform": {"os": "linux", "architecture": "s390x"}},
+				{"platform": {"os": "linux", "architecture": "amd64"}},
+				{"platform": {"os": "unknown", "architecture": "unknown"}}
+			]}`))
+		case r.URL.Path == "/v2/mirror/stolostron/registration/manifests/2.1":
+			_, _ = w.Write([]byte(`{"config": {"digest": "` + testDigest + `"}}`))
+		case r.URL.Path == "/v2/mirror/stolostron/registration/blobs/"+testDigest:
+			_, _ = w.Write([]byte(`{"architecture": "arm64", "os": "linux"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_ParseReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  reference
+	}{
+		{"quay.io/stolostron/console:2.1", reference{registry: "quay.io", path: "stolostron/console", tag: "2.1"}},
+		{"localhost:5000/console", reference{registry: "localhost:5000", path: "console", tag: "latest"}},
+		{"busybox", reference{registry: dockerHub, path: "library/busybox", tag: "latest"}},
+		{"quay.io/stolostron/console@" + testDigest, reference{registry: "quay.io", path: "stolostron/console", digest: testDigest}},
+	}
+	for _, tt := range tests {
+		got, err := parseReference(tt.image)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tt.image, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseReference(%s) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+}
+
+func Test_MirrorRepositories(t *testing.T) {
+	mirrors := []digestMirrors{
+		{source: "quay.io/stolostron", mirrors: []string{"mirror.example.com/stolostron", "backup.example.com/stolostron"}},
+		{source: "quay.io/stolostron/console", mirrors: []string{"console.example.com/console"}},
+		{source: "quay.io/stolo", mirrors: []string{"unrelated.example.com/stolo"}},
+	}
+	got := mirrorRepositories("quay.io/stolostron/console", mirrors)
+	want := []string{"mirror.example.com/stolostron/console", "backup.example.com/stolostron/console", "console.example.com/console"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("mirrorRepositories() = %v, want %v", got, want)
+	}
+}
+
+func Test_Resolve(t *testing.T) {
+	ctx := context.Background()
+	server := newTestRegistry(t, "puller", "secret")
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	icsp := &unstructured.Unstructured{}
+	icsp.SetGroupVersionKind(imageContentSourcePolicies.GroupVersion().WithKind("ImageContentSourcePolicy"))
+	icsp.SetName("mirrors")
+	_ = unstructured.SetNestedSlice(icsp.Object, []interface{}{
+		map[string]interface{}{"source": "quay.io/stolostron", "mirrors": []interface{}{registry + "/mirror/stolostron"}},
+	}, "spec", "repositoryDigestMirrors")
+	dockerConfig := fmt.Sprintf(`{"auths":{%q:{"username":"puller","password":"secret"}}}`, registry)
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "openshift-config"},
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfig)},
+	}
+	c := fake.NewClientBuilder().WithObjects(icsp, pullSecret).Build()
+	resolver := &Resolver{HTTPClient: server.Client()}
+
+	pinned := "quay.io/stolostron/registration@" + testDigest
+	got, err := resolver.Resolve(ctx, c, types.NamespacedName{}, map[string]string{
+		"console":      "quay.io/stolostron/console:2.1",
+		"registration": pinned,
+	})
+	if err != nil {
+		t.Fatalf("failed to resolve images: %v", err)
+	}
+	if want := "quay.io/stolostron/console@" + testDigest; got["console"] != want {
+		t.Errorf("Expected the tag to be resolved through the mirror to %s, got %s", want, got["console"])
+	}
+	if got["registration"] != pinned {
+		t.Errorf("Expected an image pinned to a digest to be kept, got %s", got["registration"])
+	}
+
+	t.Run("Unresolvable tags fail", func(t *testing.T) {
+		if _, err := resolver.Resolve(ctx, c, types.NamespacedName{}, map[string]string{"console": registry + "/missing/console:2.1"}); err == nil {
+			t.Errorf("Expected an error for a tag that can't be resolved")
+		}
+	})
+
+	t.Run("Resolved tags are cached", func(t *testing.T) {
+		server.Close()
+		if _, err := resolver.Resolve(ctx, c, types.NamespacedName{}, map[string]string{"console": "quay.io/stolostron/console:2.1"}); err != nil {
+			t.Errorf("Expected the cached digest to be used: %v", err)
+		}
+	})
+}
+
+func Test_Architectures(t *testing.T) {
+	ctx := context.Background()
+	server := newTestRegistry(t, "puller", "secret")
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	dockerConfig := fmt.Sprintf(`{"auths":{%q:{"username":"puller","password":"secret"}}}`, registry)
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "openshift-config"},
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfig)},
+	}
+	c := fake.NewClientBuilder().WithObjects(pullSecret).Build()
+	resolver := &Resolver{HTTPClient: server.Client()}
+
+	console := registry + "/mirror/stolostron/console:2.1"
+	registration := registry + "/mirror/stolostron/registration:2.1"
+	got, err := resolver.Architectures(ctx, c, types.NamespacedName{}, map[string]string{
+		"console":      console,
+		"registration": registration,
+	})
+	if err != nil {
+		t.Fatalf("failed to read architectures: %v", err)
+	}
+	if strings.Join(got[console], ",") != "amd64,s390x" {
+		t.Errorf("Expected the Linux architectures of the manifest list, got %v", got[console])
+	}
+	if strings.Join(got[registration], ",") != "arm64" {
+		t.Errorf("Expected the architecture of the image config, got %v", got[registration])
+	}
+
+	t.Run("Unreadable images fail", func(t *testing.T) {
+		if _, err := resolver.Architectures(ctx, c, types.NamespacedName{}, map[string]string{"console": registry + "/missing/console:2.1"}); err == nil {
+			t.Errorf("Expected an error for an image whose manifest can't be read")
+		}
+	})
+}
@@ -0,0 +1,279 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package overlay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizationFile lists the patches of an overlay. If it is missing every YAML file in the overlay is a patch.
+const kustomizationFile = "kustomization.yaml"
+
+// requiredLabels are the labels the operator relies on to find the resources it manages
+var requiredLabels = []string{"backplaneconfig.name"}
+
+// Overlay is a set of patches applied on top of the rendered manifests, in the style of a kustomize overlay
+// using patchesStrategicMerge and patchesJson6902. Each strategic merge patch is matched to a manifest by
+// apiVersion, kind, name and, when set, namespace. Built-in kinds are patched with a strategic merge patch and
+// all other kinds with a JSON merge patch. JSON 6902 patches name their target in the kustomization.
+type Overlay struct {
+	patches     []*unstructured.Unstructured
+	jsonPatches []jsonPatch
+}
+
+type kustomization struct {
+	PatchesStrategicMerge []string            `json:"patchesStrategicMerge"`
+	PatchesJson6902       []json6902Reference `json:"patchesJson6902"`
+}
+
+// json6902Reference names a file holding a JSON 6902 patch and the resource it applies to
+type json6902Reference struct {
+	Target patchTarget `json:"target"`
+	Path   string      `json:"path"`
+}
+
+type patchTarget struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type jsonPatch struct {
+	target patchTarget
+	patch  jsonpatch.Patch
+}
+
+// Load reads the overlay in dir
+func Load(dir string) (*Overlay, error) {
+	kustomization, err := ioutil.ReadFile(filepath.Join(dir, kustomizationFile))
+	if err != nil {
+		kustomization = nil
+	}
+
+	files := []string{}
+	for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			files = append(files, filepath.Base(m))
+		}
+	}
+	sort.Strings(files)
+
+	return parse(kustomization, files, func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, name))
+	})
+}
+
+// FromConfigMap reads an overlay stored in a ConfigMap, with one key per file of the overlay directory
+func FromConfigMap(cm *corev1.ConfigMap) (*Overlay, error) {
+	var kustomization []byte
+	if data, ok := cm.Data[kustomizationFile]; ok {
+		kustomization = []byte(data)
+	}
+
+	files := []string{}
+	for name := range cm.Data {
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+
+	return parse(kustomization, files, func(name string) ([]byte, error) {
+		data, ok := cm.Data[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not in ConfigMap %s", name, cm.Name)
+		}
+		return []byte(data), nil
+	})
+}
+
+// parse reads the patches listed in the kustomization, or every file if there is no kustomization
+func parse(kustomizationData []byte, files []string, read func(name string) ([]byte, error)) (*Overlay, error) {
+	k := &kustomization{PatchesStrategicMerge: files}
+	if kustomizationData != nil {
+		k = &kustomization{}
+		if err := yaml.Unmarshal(kustomizationData, k); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", kustomizationFile, err)
+		}
+	}
+
+	o := &Overlay{}
+	for _, f := range k.PatchesStrategicMerge {
+		data, err := read(f)
+		if err != nil {
+			return nil, err
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			patch := &unstructured.Unstructured{}
+			if err := decoder.Decode(&patch.Object); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to read patch %s: %w", f, err)
+			}
+			if len(patch.Object) == 0 {
+				continue
+			}
+			if patch.GetAPIVersion() == "" || patch.GetKind() == "" || patch.GetName() == "" {
+				return nil, fmt.Errorf("patch in %s must set apiVersion, kind and metadata.name", f)
+			}
+			o.patches = append(o.patches, patch)
+		}
+	}
+
+	for _, ref := range k.PatchesJson6902 {
+		if ref.Target.Version == "" || ref.Target.Kind == "" || ref.Target.Name == "" {
+			return nil, fmt.Errorf("target of patch %s must set version, kind and name", ref.Path)
+		}
+		data, err := read(ref.Path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch %s: %w", ref.Path, err)
+		}
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch %s: %w", ref.Path, err)
+		}
+		o.jsonPatches = append(o.jsonPatches, jsonPatch{target: ref.Target, patch: patch})
+	}
+	return o, nil
+}
+
+// Apply patches u with every patch of the overlay that targets it. An error is returned if a patch fails to
+// apply or removes the labels or owner references the operator requires.
+func (o *Overlay) Apply(u *unstructured.Unstructured) error {
+	for _, patch := range o.patches {
+		if !targets(patch, u) {
+			continue
+		}
+
+		patched, err := applyPatch(u, patch)
+		if err != nil {
+			return fmt.Errorf("failed to apply overlay patch to %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		if err := validate(u, patched); err != nil {
+			return fmt.Errorf("invalid overlay patch for %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		u.Object = patched.Object
+	}
+
+	for _, p := range o.jsonPatches {
+		if !p.target.matches(u) {
+			continue
+		}
+
+		patched, err := applyJSONPatch(u, p.patch)
+		if err != nil {
+			return fmt.Errorf("failed to apply overlay patch to %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		if err := validate(u, patched); err != nil {
+			return fmt.Errorf("invalid overlay patch for %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		u.Object = patched.Object
+	}
+	return nil
+}
+
+func (t patchTarget) matches(u *unstructured.Unstructured) bool {
+	gvk := u.GroupVersionKind()
+	if t.Group != gvk.Group || t.Version != gvk.Version || t.Kind != gvk.Kind || t.Name != u.GetName() {
+		return false
+	}
+	return t.Namespace == "" || t.Namespace == u.GetNamespace()
+}
+
+func applyJSONPatch(u *unstructured.Unstructured, patch jsonpatch.Patch) (*unstructured.Unstructured, error) {
+	original, err := u.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	data, err := patch.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+	patched := &unstructured.Unstructured{}
+	if err := patched.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+func targets(patch, u *unstructured.Unstructured) bool {
+	if patch.GetAPIVersion() != u.GetAPIVersion() || patch.GetKind() != u.GetKind() || patch.GetName() != u.GetName() {
+		return false
+	}
+	return patch.GetNamespace() == "" || patch.GetNamespace() == u.GetNamespace()
+}
+
+func applyPatch(u, patch *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	original := u.DeepCopy().Object
+	if typed, err := scheme.Scheme.New(u.GroupVersionKind()); err == nil {
+		patched, err := strategicpatch.StrategicMergeMapPatch(original, patch.Object, typed)
+		if err != nil {
+			return nil, err
+		}
+		return &unstructured.Unstructured{Object: patched}, nil
+	}
+	return &unstructured.Unstructured{Object: mergePatch(original, patch.Object)}, nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386) to original
+func mergePatch(original, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(original, key)
+			continue
+		}
+		patchMap, patchIsMap := value.(map[string]interface{})
+		originalMap, originalIsMap := original[key].(map[string]interface{})
+		if patchIsMap && originalIsMap {
+			original[key] = mergePatch(originalMap, patchMap)
+		} else if patchIsMap {
+			original[key] = mergePatch(map[string]interface{}{}, patchMap)
+		} else {
+			original[key] = value
+		}
+	}
+	return original
+}
+
+// validate returns an error if patched no longer has the labels and owner references of the original
+func validate(original, patched *unstructured.Unstructured) error {
+	for _, label := range requiredLabels {
+		value, ok := original.GetLabels()[label]
+		if !ok {
+			continue
+		}
+		if patched.GetLabels()[label] != value {
+			return fmt.Errorf("label %s must not be changed", label)
+		}
+	}
+	if !reflect.DeepEqual(original.GetOwnerReferences(), patched.GetOwnerReferences()) {
+		return fmt.Errorf("owner references must not be changed")
+	}
+	return nil
+}
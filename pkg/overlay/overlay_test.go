@@ -0,0 +1,173 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package overlay
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const deploymentPatch = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: discovery-operator
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: quay.io/test/sidecar:latest
+`
+
+const labelPatch = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: discovery-operator
+  labels:
+    backplaneconfig.name: other
+`
+
+const argsPatch = `- op: add
+  path: /spec/template/spec/containers/0/args
+  value: ["--verbose"]
+`
+
+const json6902Kustomization = `patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: discovery-operator
+  path: args.yaml
+`
+
+func testDeployment() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "discovery-operator",
+			"namespace": "multicluster-engine",
+			"labels": map[string]interface{}{
+				"backplaneconfig.name": "multiclusterengine",
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "discovery-operator",
+							"image": "quay.io/test/discovery:latest",
+						},
+					},
+				},
+			},
+		},
+	}}
+	return u
+}
+
+func writeOverlay(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestApply(t *testing.T) {
+	t.Run("Strategic merge patch", func(t *testing.T) {
+		o, err := Load(writeOverlay(t, map[string]string{"sidecar.yaml": deploymentPatch}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		u := testDeployment()
+		if err := o.Apply(u); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+		if len(containers) != 2 {
+			t.Fatalf("Expected the sidecar to be merged with the existing container. Got %v", containers)
+		}
+	})
+
+	t.Run("Patch changing required label", func(t *testing.T) {
+		o, err := Load(writeOverlay(t, map[string]string{"labels.yaml": labelPatch}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		u := testDeployment()
+		if err := o.Apply(u); err == nil {
+			t.Fatalf("Expected an error when a patch changes a required label")
+		}
+		if u.GetLabels()["backplaneconfig.name"] != "multiclusterengine" {
+			t.Errorf("Rejected patch should leave the manifest unchanged")
+		}
+	})
+
+	t.Run("Kustomization selects patches", func(t *testing.T) {
+		o, err := Load(writeOverlay(t, map[string]string{
+			"kustomization.yaml": "patchesStrategicMerge:\n- sidecar.yaml\n",
+			"sidecar.yaml":       deploymentPatch,
+			"labels.yaml":        labelPatch,
+		}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(o.patches) != 1 {
+			t.Errorf("Expected only the patches listed in the kustomization to be loaded. Got %d", len(o.patches))
+		}
+	})
+}
+
+func TestJSON6902(t *testing.T) {
+	o, err := Load(writeOverlay(t, map[string]string{
+		"kustomization.yaml": json6902Kustomization,
+		"args.yaml":          argsPatch,
+	}))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	u := testDeployment()
+	if err := o.Apply(u); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	args, _, _ := unstructured.NestedStringSlice(containers[0].(map[string]interface{}), "args")
+	if len(args) != 1 || args[0] != "--verbose" {
+		t.Errorf("Expected the args to be added. Got %v", args)
+	}
+}
+
+func TestFromConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "manifest-patches"},
+		Data: map[string]string{
+			"sidecar.yaml": deploymentPatch,
+			"args.yaml":    argsPatch,
+			"kustomization.yaml": `patchesStrategicMerge:
+- sidecar.yaml
+` + json6902Kustomization,
+		},
+	}
+	o, err := FromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("FromConfigMap() error = %v", err)
+	}
+	if len(o.patches) != 1 || len(o.jsonPatches) != 1 {
+		t.Errorf("Expected one patch of each type. Got %d and %d", len(o.patches), len(o.jsonPatches))
+	}
+
+	cm.Data["kustomization.yaml"] = "patchesStrategicMerge:\n- missing.yaml\n"
+	if _, err := FromConfigMap(cm); err == nil {
+		t.Errorf("Expected an error for a patch missing from the ConfigMap")
+	}
+}
@@ -0,0 +1,594 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package renderer
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	v1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	trustedCABundleVolume         = "trusted-ca-bundle"
+	trustedCABundleKey            = "ca-bundle.crt"
+	trustedCABundleFile           = "tls-ca-bundle.pem"
+	trustedCABundleMountPath      = "/etc/pki/ca-trust/extracted/pem"
+	trustedCABundleHashAnnotation = "multicluster.openshift.io/trusted-ca-bundle-hash"
+	// sslCertFileEnvVar points the components at the mounted trusted CA bundle
+	sslCertFileEnvVar = "SSL_CERT_FILE"
+	// golangFIPSEnvVar makes the Go runtime of the RHEL toolchain use FIPS validated crypto
+	golangFIPSEnvVar = "GOLANG_FIPS"
+	// logLevelEnvVar passes the log level set on the MultiClusterEngine to the components
+	logLevelEnvVar = "LOG_LEVEL"
+)
+
+// chartComponents maps chart names to the component they deploy, where the two differ
+var chartComponents = map[string]string{
+	"discovery-operator":     v1.Discovery,
+	"hive-operator":          v1.Hive,
+	"managed-serviceaccount": v1.ManagedServiceAccount,
+}
+
+// goComponents lists the components whose workloads are written in Go and so honor GOMAXPROCS
+var goComponents = map[string]bool{
+	v1.AssistedService:       true,
+	v1.ClusterBackup:         true,
+	v1.ClusterLifecycle:      true,
+	v1.ClusterManager:        true,
+	v1.ClusterProxyAddon:     true,
+	v1.Discovery:             true,
+	v1.Hive:                  true,
+	v1.HyperShift:            true,
+	v1.ManagedServiceAccount: true,
+	v1.ServerFoundation:      true,
+}
+
+// componentForChart returns the name of the component deployed by the named chart
+func componentForChart(chartName string) string {
+	if component, ok := chartComponents[chartName]; ok {
+		return component
+	}
+	return chartName
+}
+
+// applyDeploymentOverrides updates a rendered deployment with the configuration set for its component
+// in the MultiClusterEngine spec
+func applyDeploymentOverrides(u *unstructured.Unstructured, backplaneConfig *v1.MultiClusterEngine, component string, opts Options) error {
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+		return err
+	}
+
+	config := backplaneConfig.GetComponentConfig(component)
+	if config != nil && config.ProgressDeadlineSeconds != nil {
+		pds := *config.ProgressDeadlineSeconds
+		deployment.Spec.ProgressDeadlineSeconds = &pds
+	}
+	if config != nil && config.Replicas != nil {
+		replicas := *config.Replicas
+		deployment.Spec.Replicas = &replicas
+	}
+	if config != nil && config.Paused {
+		replicas := int32(0)
+		deployment.Spec.Replicas = &replicas
+		annotations := deployment.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[utils.AnnotationComponentPaused] = "true"
+		deployment.SetAnnotations(annotations)
+	}
+
+	if config != nil && config.LivenessFailurePolicy == v1.LivenessFailureUnready {
+		removeLivenessProbes(&deployment.Spec.Template)
+	}
+
+	if backplaneConfig.Spec.Overrides != nil && backplaneConfig.Spec.Overrides.PriorityClassName != "" {
+		deployment.Spec.Template.Spec.PriorityClassName = backplaneConfig.Spec.Overrides.PriorityClassName
+	} else if opts.DefaultPriorityClassName != "" {
+		deployment.Spec.Template.Spec.PriorityClassName = opts.DefaultPriorityClassName
+	}
+
+	if backplaneConfig.Spec.Overrides != nil && backplaneConfig.Spec.Overrides.SecurityContext != nil {
+		applySecurityContext(&deployment.Spec.Template, backplaneConfig.Spec.Overrides.SecurityContext)
+		if backplaneConfig.Spec.Overrides.SecurityContext.Restricted {
+			applyRestrictedSecurity(&deployment.Spec.Template)
+		}
+	}
+	// The profile is applied before the affinity overrides, so a node affinity set there takes precedence
+	if backplaneConfig.Spec.SchedulingProfile == v1.SchedulingInfra {
+		applyInfraScheduling(&deployment.Spec.Template)
+	}
+	if backplaneConfig.Spec.Overrides != nil {
+		applyAffinity(deployment, backplaneConfig.Spec.Overrides)
+	}
+
+	// Resources are merged first so GOMAXPROCS follows an overridden CPU limit
+	if config != nil && config.Resources != nil {
+		mergeResources(&deployment.Spec.Template, config.Resources)
+	}
+
+	if goComponents[component] {
+		setGoMaxProcs(&deployment.Spec.Template)
+		if opts.FIPS && (config == nil || !config.SkipFIPS) {
+			enableFIPS(&deployment.Spec.Template)
+		}
+	}
+
+	// The bundle can only be mounted by pods in the same namespace as the ConfigMap
+	if caBundle := opts.TrustedCABundle; caBundle.Name != "" && caBundle.Namespace == deployment.Namespace && (config == nil || !config.SkipTrustedCABundle) {
+		injectTrustedCABundle(&deployment.Spec.Template, caBundle.Name, opts.TrustedCABundleHash)
+	}
+
+	if backplaneConfig.Spec.LogLevel != "" {
+		mergeEnv(&deployment.Spec.Template, []corev1.EnvVar{{Name: logLevelEnvVar, Value: backplaneConfig.Spec.LogLevel}})
+	}
+
+	// User supplied variables are set last so they win over the defaults above
+	if config != nil && len(config.Env) > 0 {
+		mergeEnv(&deployment.Spec.Template, config.Env)
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	if err != nil {
+		return err
+	}
+	u.Object = obj
+	return nil
+}
+
+// ApplyMetadataOverrides merges the labels and annotations set in the overrides onto a resource, and onto the pod
+// template of a deployment. Labels and annotations the resource already has are kept, so the operator's own take
+// precedence.
+func ApplyMetadataOverrides(u *unstructured.Unstructured, backplaneConfig *v1.MultiClusterEngine) error {
+	o := backplaneConfig.Spec.Overrides
+	if o == nil || (len(o.Labels) == 0 && len(o.Annotations) == 0) {
+		return nil
+	}
+	u.SetLabels(mergeMissing(u.GetLabels(), o.Labels))
+	u.SetAnnotations(mergeMissing(u.GetAnnotations(), o.Annotations))
+	if u.GetKind() != "Deployment" {
+		return nil
+	}
+
+	for field, values := range map[string]map[string]string{"labels": o.Labels, "annotations": o.Annotations} {
+		fields := []string{"spec", "template", "metadata", field}
+		existing, _, err := unstructured.NestedStringMap(u.Object, fields...)
+		if err != nil {
+			return err
+		}
+		merged := mergeMissing(existing, values)
+		if len(merged) == 0 {
+			continue
+		}
+		if err := unstructured.SetNestedStringMap(u.Object, merged, fields...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeMissing returns existing with the entries of values it doesn't have
+func mergeMissing(existing, values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return existing
+	}
+	merged := map[string]string{}
+	for k, v := range values {
+		merged[k] = v
+	}
+	for k, v := range existing {
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergeResources sets the given requests and limits in every container, replacing the quantities of the same
+// resources
+func mergeResources(template *corev1.PodTemplateSpec, resources *corev1.ResourceRequirements) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		if len(resources.Requests) > 0 && c.Resources.Requests == nil {
+			c.Resources.Requests = corev1.ResourceList{}
+		}
+		for name, quantity := range resources.Requests {
+			c.Resources.Requests[name] = quantity.DeepCopy()
+		}
+		if len(resources.Limits) > 0 && c.Resources.Limits == nil {
+			c.Resources.Limits = corev1.ResourceList{}
+		}
+		for name, quantity := range resources.Limits {
+			c.Resources.Limits[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// mergeEnv sets the given environment variables in every container, replacing variables of the same name
+func mergeEnv(template *corev1.PodTemplateSpec, env []corev1.EnvVar) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		for _, e := range env {
+			replaced := false
+			for j := range c.Env {
+				if c.Env[j].Name == e.Name {
+					c.Env[j] = *e.DeepCopy()
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				c.Env = append(c.Env, *e.DeepCopy())
+			}
+		}
+	}
+}
+
+// injectTrustedCABundle mounts the CA bundle held in the named ConfigMap into every container at the
+// standard system location. The hash of the bundle is recorded on the pod template so pods are rolled
+// when the bundle changes. Containers which already mount a volume there or set SSL_CERT_FILE keep their own.
+func injectTrustedCABundle(template *corev1.PodTemplateSpec, configMapName, hash string) {
+	for _, v := range template.Spec.Volumes {
+		if v.Name == trustedCABundleVolume {
+			return
+		}
+	}
+
+	template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+		Name: trustedCABundleVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+				Items: []corev1.KeyToPath{
+					{Key: trustedCABundleKey, Path: trustedCABundleFile},
+				},
+			},
+		},
+	})
+
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		mounted := false
+		for _, m := range c.VolumeMounts {
+			if m.Name == trustedCABundleVolume || m.MountPath == trustedCABundleMountPath {
+				mounted = true
+				break
+			}
+		}
+		if !mounted {
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+				Name:      trustedCABundleVolume,
+				MountPath: trustedCABundleMountPath,
+				ReadOnly:  true,
+			})
+		}
+		set := false
+		for _, e := range c.Env {
+			if e.Name == sslCertFileEnvVar {
+				set = true
+				break
+			}
+		}
+		if !set {
+			c.Env = append(c.Env, corev1.EnvVar{
+				Name:  sslCertFileEnvVar,
+				Value: path.Join(trustedCABundleMountPath, trustedCABundleFile),
+			})
+		}
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[trustedCABundleHashAnnotation] = hash
+}
+
+// setGoMaxProcs sets GOMAXPROCS on each container to its CPU limit, rounded up to a whole CPU. Containers
+// without a CPU limit, or which already set GOMAXPROCS, are left unchanged.
+func setGoMaxProcs(template *corev1.PodTemplateSpec) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		limit, ok := c.Resources.Limits[corev1.ResourceCPU]
+		if !ok || limit.IsZero() {
+			continue
+		}
+		overridden := false
+		for _, e := range c.Env {
+			if e.Name == "GOMAXPROCS" {
+				overridden = true
+				break
+			}
+		}
+		if overridden {
+			continue
+		}
+		procs := (limit.MilliValue() + 999) / 1000
+		c.Env = append(c.Env, corev1.EnvVar{Name: "GOMAXPROCS", Value: strconv.FormatInt(procs, 10)})
+	}
+}
+
+// addImagePullSecret references the named image pull secret from a rendered service account, so that pods
+// running as it can pull images even where the chart does not set the secret on the pod
+func addImagePullSecret(u *unstructured.Unstructured, name string) error {
+	sa := &corev1.ServiceAccount{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, sa); err != nil {
+		return err
+	}
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == name {
+			return nil
+		}
+	}
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sa)
+	if err != nil {
+		return err
+	}
+	u.Object = obj
+	return nil
+}
+
+// enableFIPS makes each container use FIPS validated crypto. Containers which already set GOLANG_FIPS are left
+// unchanged.
+func enableFIPS(template *corev1.PodTemplateSpec) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		set := false
+		for _, e := range c.Env {
+			if e.Name == golangFIPSEnvVar {
+				set = true
+				break
+			}
+		}
+		if !set {
+			c.Env = append(c.Env, corev1.EnvVar{Name: golangFIPSEnvVar, Value: "1"})
+		}
+	}
+}
+
+// removeLivenessProbes removes the liveness probe from each container so a failing check takes the pod out
+// of service rather than restarting it. A container without a readiness probe uses its liveness probe as one.
+func removeLivenessProbes(template *corev1.PodTemplateSpec) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		if c.LivenessProbe == nil {
+			continue
+		}
+		if c.ReadinessProbe == nil {
+			c.ReadinessProbe = c.LivenessProbe
+		}
+		c.LivenessProbe = nil
+	}
+}
+
+// applySecurityContext merges the security context overrides into the pod template. Overrides can only
+// tighten the settings already present in the manifests.
+func applySecurityContext(template *corev1.PodTemplateSpec, sc *v1.SecurityContextOverrides) {
+	if template.Spec.SecurityContext == nil {
+		template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSC := template.Spec.SecurityContext
+
+	if sc.RunAsNonRoot != nil && *sc.RunAsNonRoot {
+		runAsNonRoot := true
+		podSC.RunAsNonRoot = &runAsNonRoot
+	}
+	if sc.SeccompProfile != nil && sc.SeccompProfile.Type != corev1.SeccompProfileTypeUnconfined {
+		podSC.SeccompProfile = sc.SeccompProfile.DeepCopy()
+	}
+
+	if len(sc.DropCapabilities) == 0 {
+		return
+	}
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		if c.SecurityContext.Capabilities == nil {
+			c.SecurityContext.Capabilities = &corev1.Capabilities{}
+		}
+		for _, capability := range sc.DropCapabilities {
+			if !containsCapability(c.SecurityContext.Capabilities.Drop, capability) {
+				c.SecurityContext.Capabilities.Drop = append(c.SecurityContext.Capabilities.Drop, capability)
+			}
+		}
+	}
+}
+
+// applyRestrictedSecurity sets the fields the restricted Pod Security Standard requires on the pod template and
+// all of its containers. NET_BIND_SERVICE is the only capability the standard allows to be added.
+func applyRestrictedSecurity(template *corev1.PodTemplateSpec) {
+	if template.Spec.SecurityContext == nil {
+		template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSC := template.Spec.SecurityContext
+	runAsNonRoot := true
+	podSC.RunAsNonRoot = &runAsNonRoot
+	if podSC.SeccompProfile == nil || podSC.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		podSC.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	containers := []*corev1.Container{}
+	for i := range template.Spec.InitContainers {
+		containers = append(containers, &template.Spec.InitContainers[i])
+	}
+	for i := range template.Spec.Containers {
+		containers = append(containers, &template.Spec.Containers[i])
+	}
+	for _, c := range containers {
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		sc := c.SecurityContext
+		allowPrivilegeEscalation, privileged := false, false
+		sc.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+		sc.Privileged = &privileged
+		if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+			sc.RunAsNonRoot = nil
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			sc.SeccompProfile = nil
+		}
+
+		if sc.Capabilities == nil {
+			sc.Capabilities = &corev1.Capabilities{}
+		}
+		if !containsCapability(sc.Capabilities.Drop, "ALL") {
+			sc.Capabilities.Drop = append(sc.Capabilities.Drop, "ALL")
+		}
+		added := []corev1.Capability{}
+		for _, capability := range sc.Capabilities.Add {
+			if capability == "NET_BIND_SERVICE" {
+				added = append(added, capability)
+			}
+		}
+		sc.Capabilities.Add = added
+	}
+}
+
+func containsCapability(capabilities []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// applyInfraScheduling makes the pods tolerate the infra node taints and prefer nodes with the infra role
+func applyInfraScheduling(template *corev1.PodTemplateSpec) {
+	podSpec := &template.Spec
+	for _, toleration := range utils.InfraTolerations() {
+		tolerated := false
+		for _, t := range podSpec.Tolerations {
+			if t.Key == toleration.Key && t.Operator == toleration.Operator && (t.Effect == toleration.Effect || t.Effect == "") {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			podSpec.Tolerations = append(podSpec.Tolerations, toleration)
+		}
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	podSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		podSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.PreferredSchedulingTerm{
+			Weight: 100,
+			Preference: corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: utils.InfraNodeLabel, Operator: corev1.NodeSelectorOpExists},
+				},
+			},
+		})
+}
+
+// applyAffinity layers the affinity and topology spread overrides over the scheduling defaults rendered from
+// availabilityConfig
+func applyAffinity(deployment *appsv1.Deployment, o *v1.Overrides) {
+	podSpec := &deployment.Spec.Template.Spec
+	if o.Affinity != nil {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		if o.Affinity.NodeAffinity != nil {
+			podSpec.Affinity.NodeAffinity = o.Affinity.NodeAffinity.DeepCopy()
+		}
+		if o.Affinity.PodAffinity != nil {
+			podSpec.Affinity.PodAffinity = o.Affinity.PodAffinity.DeepCopy()
+		}
+		if o.Affinity.PodAntiAffinity != nil {
+			podSpec.Affinity.PodAntiAffinity = o.Affinity.PodAntiAffinity.DeepCopy()
+		}
+	}
+
+	if len(o.TopologySpreadConstraints) > 0 {
+		podSpec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{}
+		for _, tsc := range o.TopologySpreadConstraints {
+			tsc = *tsc.DeepCopy()
+			if tsc.LabelSelector == nil {
+				tsc.LabelSelector = deployment.Spec.Selector.DeepCopy()
+			}
+			podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, tsc)
+		}
+	}
+}
+
+// ApplyArchitectureAffinity requires the pods of a deployment to run on nodes of an architecture all of its images
+// are built for. The architectures are keyed by image reference. A deployment with an image of unknown
+// architectures is left unchanged.
+func ApplyArchitectureAffinity(u *unstructured.Unstructured, architectures map[string][]string) error {
+	if len(architectures) == 0 || u.GetKind() != "Deployment" {
+		return nil
+	}
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+		return err
+	}
+
+	podSpec := &deployment.Spec.Template.Spec
+	var supported []string
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		imageArchitectures, ok := architectures[container.Image]
+		if !ok {
+			return nil
+		}
+		if supported == nil {
+			supported = append([]string{}, imageArchitectures...)
+			continue
+		}
+		common := []string{}
+		for _, arch := range supported {
+			if utils.Contains(imageArchitectures, arch) {
+				common = append(common, arch)
+			}
+		}
+		supported = common
+	}
+	if supported == nil {
+		return nil
+	}
+	if len(supported) == 0 {
+		return fmt.Errorf("the images of deployment %s have no architecture in common", deployment.Name)
+	}
+
+	requirement := corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: supported}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}},
+		}
+	} else {
+		// Terms are alternatives, so each of them must require the architecture
+		for i := range required.NodeSelectorTerms {
+			required.NodeSelectorTerms[i].MatchExpressions = append(required.NodeSelectorTerms[i].MatchExpressions, requirement)
+		}
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	if err != nil {
+		return err
+	}
+	u.Object = obj
+	return nil
+}
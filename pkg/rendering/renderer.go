@@ -18,12 +18,15 @@ import (
 	"helm.sh/helm/v3/pkg/engine"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/yaml"
 )
 
 const (
 	AlwaysChartsDir = "pkg/templates/charts/always"
+	// ComponentLabel sits on every rendered resource and names the component whose chart it was rendered from
+	ComponentLabel = "multicluster.openshift.io/component"
 )
 
 type Values struct {
@@ -39,12 +42,32 @@ type Global struct {
 	Namespace      string            `yaml:"namespace" structs:"namespace"`
 }
 
+// Options are the inputs for rendering the operator looks up for each MultiClusterEngine when it is reconciled,
+// rather than reads from its spec
+type Options struct {
+	// Images are the operand images, keyed by image key
+	Images map[string]string
+	// TrustedCABundle is the ConfigMap holding the trusted CA bundle, and TrustedCABundleHash a hash of its
+	// contents. No bundle is mounted if it is unset.
+	TrustedCABundle     types.NamespacedName
+	TrustedCABundleHash string
+	// FIPS enables FIPS crypto in the pods of components written in Go
+	FIPS bool
+	// ClusterProxy holds the settings of the cluster-wide Proxy resource
+	ClusterProxy map[string]string
+	// DefaultPriorityClassName is given to component pods when the MultiClusterEngine does not set a priority class
+	DefaultPriorityClassName string
+	// OCPVersion is the OpenShift version of the hub cluster
+	OCPVersion string
+}
+
 type HubConfig struct {
 	NodeSelector map[string]string   `yaml:"nodeSelector" structs:"nodeSelector"`
 	ProxyConfigs map[string]string   `yaml:"proxyConfigs" structs:"proxyConfigs"`
 	ReplicaCount int                 `yaml:"replicaCount" structs:"replicaCount"`
 	Tolerations  []corev1.Toleration `yaml:"tolerations" structs:"tolerations"`
 	OCPVersion   string              `yaml:"ocpVersion" structs:"ocpVersion"`
+	LogLevel     string              `yaml:"logLevel" structs:"logLevel"`
 }
 
 func RenderCRDs(crdDir string) ([]*unstructured.Unstructured, []error) {
@@ -82,7 +105,7 @@ func RenderCRDs(crdDir string) ([]*unstructured.Unstructured, []error) {
 	return crds, errs
 }
 
-func RenderCharts(chartDir string, backplaneConfig *v1.MultiClusterEngine, images map[string]string) ([]*unstructured.Unstructured, []error) {
+func RenderCharts(chartDir string, backplaneConfig *v1.MultiClusterEngine, opts Options) ([]*unstructured.Unstructured, []error) {
 	log := log.FromContext(context.Background())
 	var templates []*unstructured.Unstructured
 	errs := []error{}
@@ -95,7 +118,7 @@ func RenderCharts(chartDir string, backplaneConfig *v1.MultiClusterEngine, image
 	}
 	for _, chart := range charts {
 		chartPath := filepath.Join(chartDir, chart.Name())
-		chartTemplates, errs := renderTemplates(chartPath, backplaneConfig, images)
+		chartTemplates, errs := renderTemplates(chartPath, backplaneConfig, opts)
 		if len(errs) > 0 {
 			for _, err := range errs {
 				log.Info(err.Error())
@@ -107,13 +130,36 @@ func RenderCharts(chartDir string, backplaneConfig *v1.MultiClusterEngine, image
 	return templates, nil
 }
 
-func RenderChart(chartPath string, backplaneConfig *v1.MultiClusterEngine, images map[string]string) ([]*unstructured.Unstructured, []error) {
+// ChartComponents returns the components deployed by the charts in chartDir
+func ChartComponents(chartDir string) ([]string, error) {
+	if val, ok := os.LookupEnv("DIRECTORY_OVERRIDE"); ok {
+		chartDir = path.Join(val, chartDir)
+	}
+	charts, err := ioutil.ReadDir(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	components := []string{}
+	for _, dir := range charts {
+		if !dir.IsDir() {
+			continue
+		}
+		chart, err := loader.Load(filepath.Join(chartDir, dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, componentForChart(chart.Name()))
+	}
+	return components, nil
+}
+
+func RenderChart(chartPath string, backplaneConfig *v1.MultiClusterEngine, opts Options) ([]*unstructured.Unstructured, []error) {
 	log := log.FromContext(context.Background())
 	errs := []error{}
 	if val, ok := os.LookupEnv("DIRECTORY_OVERRIDE"); ok {
 		chartPath = path.Join(val, chartPath)
 	}
-	chartTemplates, errs := renderTemplates(chartPath, backplaneConfig, images)
+	chartTemplates, errs := renderTemplates(chartPath, backplaneConfig, opts)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			log.Info(err.Error())
@@ -125,13 +171,13 @@ func RenderChart(chartPath string, backplaneConfig *v1.MultiClusterEngine, image
 }
 
 // RenderChartWithNamespace wraps the RenderChart function, overriding the target namespace
-func RenderChartWithNamespace(chartPath string, backplaneConfig *v1.MultiClusterEngine, images map[string]string, namespace string) ([]*unstructured.Unstructured, []error) {
+func RenderChartWithNamespace(chartPath string, backplaneConfig *v1.MultiClusterEngine, opts Options, namespace string) ([]*unstructured.Unstructured, []error) {
 	mce := backplaneConfig.DeepCopy()
 	mce.Spec.TargetNamespace = namespace
-	return RenderChart(chartPath, mce, images)
+	return RenderChart(chartPath, mce, opts)
 }
 
-func renderTemplates(chartPath string, backplaneConfig *v1.MultiClusterEngine, images map[string]string) ([]*unstructured.Unstructured, []error) {
+func renderTemplates(chartPath string, backplaneConfig *v1.MultiClusterEngine, opts Options) ([]*unstructured.Unstructured, []error) {
 	log := log.FromContext(context.Background())
 	var templates []*unstructured.Unstructured
 	errs := []error{}
@@ -141,7 +187,7 @@ func renderTemplates(chartPath string, backplaneConfig *v1.MultiClusterEngine, i
 		return nil, append(errs, err)
 	}
 	valuesYaml := &Values{}
-	injectValuesOverrides(valuesYaml, backplaneConfig, images)
+	injectValuesOverrides(valuesYaml, backplaneConfig, opts)
 	helmEngine := engine.Engine{
 		Strict:   true,
 		LintMode: false,
@@ -159,21 +205,38 @@ func renderTemplates(chartPath string, backplaneConfig *v1.MultiClusterEngine, i
 		}
 
 		utils.AddBackplaneConfigLabels(unstructured, backplaneConfig.Name)
+		labels := unstructured.GetLabels()
+		labels[ComponentLabel] = componentForChart(chart.Name())
+		unstructured.SetLabels(labels)
 
 		// Add namespace to namespaced resources
 		switch unstructured.GetKind() {
 		case "Deployment", "ServiceAccount", "Role", "RoleBinding", "Service", "ConfigMap":
 			unstructured.SetNamespace(backplaneConfig.Spec.TargetNamespace)
 		}
+
+		if unstructured.GetKind() == "Deployment" {
+			if err := applyDeploymentOverrides(unstructured, backplaneConfig, componentForChart(chart.Name()), opts); err != nil {
+				return nil, append(errs, fmt.Errorf("error applying overrides to %s: %v", fileName, err))
+			}
+		}
+		if err := ApplyMetadataOverrides(unstructured, backplaneConfig); err != nil {
+			return nil, append(errs, fmt.Errorf("error applying label and annotation overrides to %s: %v", fileName, err))
+		}
+		if unstructured.GetKind() == "ServiceAccount" && backplaneConfig.Spec.ImagePullSecret != "" {
+			if err := addImagePullSecret(unstructured, backplaneConfig.Spec.ImagePullSecret); err != nil {
+				return nil, append(errs, fmt.Errorf("error adding image pull secret to %s: %v", fileName, err))
+			}
+		}
 		templates = append(templates, unstructured)
 	}
 
 	return templates, errs
 }
 
-func injectValuesOverrides(values *Values, backplaneConfig *v1.MultiClusterEngine, images map[string]string) {
+func injectValuesOverrides(values *Values, backplaneConfig *v1.MultiClusterEngine, opts Options) {
 
-	values.Global.ImageOverrides = images
+	values.Global.ImageOverrides = opts.Images
 
 	values.Global.PullPolicy = string(utils.GetImagePullPolicy(backplaneConfig))
 
@@ -193,13 +256,14 @@ func injectValuesOverrides(values *Values, backplaneConfig *v1.MultiClusterEngin
 
 	values.Org = "open-cluster-management"
 
-	values.HubConfig.OCPVersion = os.Getenv("ACM_HUB_OCP_VERSION")
+	values.HubConfig.OCPVersion = opts.OCPVersion
+
+	values.HubConfig.LogLevel = "info"
+	if backplaneConfig.Spec.LogLevel != "" {
+		values.HubConfig.LogLevel = backplaneConfig.Spec.LogLevel
+	}
 
-	if utils.ProxyEnvVarsAreSet() {
-		proxyVar := map[string]string{}
-		proxyVar["HTTP_PROXY"] = os.Getenv("HTTP_PROXY")
-		proxyVar["HTTPS_PROXY"] = os.Getenv("HTTPS_PROXY")
-		proxyVar["NO_PROXY"] = os.Getenv("NO_PROXY")
+	if proxyVar := utils.ProxyEnvVars(opts.ClusterProxy); proxyVar != nil {
 		values.HubConfig.ProxyConfigs = proxyVar
 	}
 
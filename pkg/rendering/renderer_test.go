@@ -5,6 +5,7 @@ package renderer
 
 import (
 	"os"
+	"path"
 	"reflect"
 	"testing"
 
@@ -12,14 +13,19 @@ import (
 	"github.com/stolostron/backplane-operator/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
-	chartsDir  = "pkg/templates/charts/toggle"
-	chartsPath = "pkg/templates/charts/toggle/managed-serviceaccount"
-	crdsDir    = "pkg/templates/crds"
+	chartsDir          = "pkg/templates/charts/toggle"
+	chartsPath         = "pkg/templates/charts/toggle/managed-serviceaccount"
+	discoveryChartPath = "pkg/templates/charts/toggle/discovery-operator"
+	crdsDir            = "pkg/templates/crds"
 )
 
 func TestRender(t *testing.T) {
@@ -68,7 +74,7 @@ func TestRender(t *testing.T) {
 	}
 	// multiple charts
 	chartsDir := chartsDir
-	templates, errs := RenderCharts(chartsDir, testBackplane, testImages)
+	templates, errs := RenderCharts(chartsDir, testBackplane, Options{Images: testImages})
 	if len(errs) > 0 {
 		for _, err := range errs {
 			t.Logf(err.Error())
@@ -79,6 +85,22 @@ func TestRender(t *testing.T) {
 		}
 	}
 	for _, template := range templates {
+		if template.GetKind() == "ServiceAccount" {
+			sa := &corev1.ServiceAccount{}
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, sa)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			references := 0
+			for _, s := range sa.ImagePullSecrets {
+				if s.Name == backplaneImagePullSecret {
+					references++
+				}
+			}
+			if references != 1 {
+				t.Fatalf("Image Pull Secret not referenced once by the %s service account", sa.Name)
+			}
+		}
 		if template.GetKind() == "Deployment" {
 			deployment := &appsv1.Deployment{}
 			err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
@@ -143,7 +165,7 @@ func TestRender(t *testing.T) {
 		singleChartTestImages[v] = "quay.io/test/test:Test"
 	}
 	chartsPath := chartsPath
-	singleChartTemplates, errs := RenderChart(chartsPath, testBackplane, singleChartTestImages)
+	singleChartTemplates, errs := RenderChart(chartsPath, testBackplane, Options{Images: singleChartTestImages})
 	if len(errs) > 0 {
 		for _, err := range errs {
 			t.Logf(err.Error())
@@ -250,3 +272,1172 @@ func TestRenderCRDs(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderComponentOverrides(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	progressDeadline := int32(1200)
+	replicas := int32(3)
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				Components: []backplane.ComponentConfig{
+					{Name: backplane.Discovery, Enabled: true, ProgressDeadlineSeconds: &progressDeadline, Replicas: &replicas},
+				},
+				PriorityClassName: "system-cluster-critical",
+			},
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if deployment.Spec.ProgressDeadlineSeconds == nil || *deployment.Spec.ProgressDeadlineSeconds != progressDeadline {
+			t.Fatalf("progressDeadlineSeconds override did not propagate to the %s deployment", deployment.Name)
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != replicas {
+			t.Fatalf("replicas override did not propagate to the %s deployment", deployment.Name)
+		}
+		if deployment.Spec.Template.Spec.PriorityClassName != "system-cluster-critical" {
+			t.Fatalf("priorityClassName did not propagate to the %s deployment", deployment.Name)
+		}
+	}
+}
+
+func TestRenderDefaultPriorityClass(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	tests := []struct {
+		name      string
+		override  string
+		defaulted string
+		want      string
+	}{
+		{name: "Default priority class", defaulted: "system-cluster-critical", want: "system-cluster-critical"},
+		{name: "Priority class set on the MultiClusterEngine", override: "hub-critical", defaulted: "system-cluster-critical", want: "hub-critical"},
+		{name: "Default disabled", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testBackplane := &backplane.MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+				Spec: backplane.MultiClusterEngineSpec{
+					TargetNamespace: "default",
+					Overrides:       &backplane.Overrides{PriorityClassName: tt.override},
+				},
+			}
+
+			templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages, DefaultPriorityClassName: tt.defaulted})
+			if len(errs) > 0 {
+				t.Fatalf("failed to retrieve templates: %v", errs)
+			}
+			for _, template := range templates {
+				if template.GetKind() != "Deployment" {
+					continue
+				}
+				deployment := &appsv1.Deployment{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment); err != nil {
+					t.Fatalf(err.Error())
+				}
+				if got := deployment.Spec.Template.Spec.PriorityClassName; got != tt.want {
+					t.Errorf("priorityClassName of the %s deployment = %q, want %q", deployment.Name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTrustedCABundle(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	tests := []struct {
+		name       string
+		skip       bool
+		wantMounts bool
+	}{
+		{name: "Bundle is mounted", skip: false, wantMounts: true},
+		{name: "Component opts out of bundle", skip: true, wantMounts: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testBackplane := &backplane.MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testBackplane",
+				},
+				Spec: backplane.MultiClusterEngineSpec{
+					TargetNamespace: "default",
+					Overrides: &backplane.Overrides{
+						Components: []backplane.ComponentConfig{
+							{Name: backplane.Discovery, Enabled: true, SkipTrustedCABundle: tt.skip},
+						},
+					},
+				},
+			}
+			opts := Options{
+				Images:              testImages,
+				TrustedCABundle:     types.NamespacedName{Name: "custom-ca", Namespace: "default"},
+				TrustedCABundleHash: "abc123",
+			}
+
+			templates, errs := RenderChart(discoveryChartPath, testBackplane, opts)
+			if len(errs) > 0 {
+				for _, err := range errs {
+					t.Logf(err.Error())
+				}
+				t.Fatalf("failed to retrieve templates")
+			}
+			for _, template := range templates {
+				if template.GetKind() != "Deployment" {
+					continue
+				}
+				deployment := &appsv1.Deployment{}
+				err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+
+				mounted := false
+				for _, v := range deployment.Spec.Template.Spec.Volumes {
+					if v.ConfigMap != nil && v.ConfigMap.Name == "custom-ca" {
+						mounted = true
+					}
+				}
+				if mounted != tt.wantMounts {
+					t.Fatalf("Expected trusted CA bundle mounted to be %t in the %s deployment", tt.wantMounts, deployment.Name)
+				}
+				if tt.wantMounts && deployment.Spec.Template.Annotations[trustedCABundleHashAnnotation] != "abc123" {
+					t.Fatalf("Trusted CA bundle hash not set on the %s deployment", deployment.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderFIPS(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	tests := []struct {
+		name    string
+		fips    bool
+		skip    bool
+		wantEnv bool
+	}{
+		{name: "FIPS mode is propagated", fips: true, wantEnv: true},
+		{name: "Component opts out of FIPS mode", fips: true, skip: true, wantEnv: false},
+		{name: "Cluster is not in FIPS mode", fips: false, wantEnv: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testBackplane := &backplane.MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testBackplane",
+				},
+				Spec: backplane.MultiClusterEngineSpec{
+					TargetNamespace: "default",
+					Overrides: &backplane.Overrides{
+						Components: []backplane.ComponentConfig{
+							{Name: backplane.Discovery, Enabled: true, SkipFIPS: tt.skip},
+						},
+					},
+				},
+			}
+
+			templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages, FIPS: tt.fips})
+			if len(errs) > 0 {
+				for _, err := range errs {
+					t.Logf(err.Error())
+				}
+				t.Fatalf("failed to retrieve templates")
+			}
+			for _, template := range templates {
+				if template.GetKind() != "Deployment" {
+					continue
+				}
+				deployment := &appsv1.Deployment{}
+				err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+
+				for _, c := range deployment.Spec.Template.Spec.Containers {
+					set := false
+					for _, e := range c.Env {
+						if e.Name == golangFIPSEnvVar && e.Value == "1" {
+							set = true
+						}
+					}
+					if set != tt.wantEnv {
+						t.Fatalf("Expected %s set to be %t in container %s of the %s deployment", golangFIPSEnvVar, tt.wantEnv, c.Name, deployment.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []corev1.EnvVar
+		env      []corev1.EnvVar
+		expected []corev1.EnvVar
+	}{
+		{
+			name:     "New variable is added",
+			existing: []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}},
+			env:      []corev1.EnvVar{{Name: "FEATURE_GATE", Value: "true"}},
+			expected: []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}, {Name: "FEATURE_GATE", Value: "true"}},
+		},
+		{
+			name:     "User value overrides operator default",
+			existing: []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}, {Name: "OTHER", Value: "a"}},
+			env:      []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "8"}},
+			expected: []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "8"}, {Name: "OTHER", Value: "a"}},
+		},
+		{
+			name:     "Container without variables",
+			env:      []corev1.EnvVar{{Name: "FEATURE_GATE", Value: "true"}},
+			expected: []corev1.EnvVar{{Name: "FEATURE_GATE", Value: "true"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Env: tt.existing}}}}
+
+			mergeEnv(template, tt.env)
+
+			if got := template.Spec.Containers[0].Env; !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("mergeEnv() env = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  corev1.ResourceRequirements
+		resources corev1.ResourceRequirements
+		expected  corev1.ResourceRequirements
+	}{
+		{
+			name: "Override replaces default of the same resource",
+			existing: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+			},
+			resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+		},
+		{
+			name: "Limits are added to a container without them",
+			existing: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+			},
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test", Resources: tt.existing}}}}
+
+			mergeResources(template, &tt.resources)
+
+			got := template.Spec.Containers[0].Resources
+			for name, want := range tt.expected.Requests {
+				if q := got.Requests[name]; q.Cmp(want) != 0 {
+					t.Errorf("mergeResources() %s request = %s, want %s", name, q.String(), want.String())
+				}
+			}
+			for name, want := range tt.expected.Limits {
+				if q := got.Limits[name]; q.Cmp(want) != 0 {
+					t.Errorf("mergeResources() %s limit = %s, want %s", name, q.String(), want.String())
+				}
+			}
+			if len(got.Requests) != len(tt.expected.Requests) || len(got.Limits) != len(tt.expected.Limits) {
+				t.Errorf("mergeResources() resources = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetGoMaxProcs(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    string
+		env      []corev1.EnvVar
+		expected string
+	}{
+		{name: "Integer CPU limit", limit: "2", expected: "2"},
+		{name: "Fractional CPU limit below one", limit: "300m", expected: "1"},
+		{name: "Fractional CPU limit above one", limit: "1500m", expected: "2"},
+		{name: "No CPU limit", expected: ""},
+		{name: "Explicit override", limit: "4", env: []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "8"}}, expected: "8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := corev1.Container{Name: "test", Env: tt.env}
+			if tt.limit != "" {
+				container.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(tt.limit)}
+			}
+			template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{container}}}
+
+			setGoMaxProcs(template)
+
+			got := ""
+			for _, e := range template.Spec.Containers[0].Env {
+				if e.Name == "GOMAXPROCS" {
+					got = e.Value
+				}
+			}
+			if got != tt.expected {
+				t.Errorf("setGoMaxProcs() GOMAXPROCS = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInjectTrustedCABundle(t *testing.T) {
+	bundle := path.Join(trustedCABundleMountPath, trustedCABundleFile)
+	tests := []struct {
+		name       string
+		env        []corev1.EnvVar
+		mounts     []corev1.VolumeMount
+		wantEnv    string
+		wantMounts int
+	}{
+		{name: "Container without the bundle", wantEnv: bundle, wantMounts: 1},
+		{
+			name:       "Container setting SSL_CERT_FILE",
+			env:        []corev1.EnvVar{{Name: sslCertFileEnvVar, Value: "/etc/ssl/custom.pem"}},
+			wantEnv:    "/etc/ssl/custom.pem",
+			wantMounts: 1,
+		},
+		{
+			name:       "Container mounting its own bundle",
+			mounts:     []corev1.VolumeMount{{Name: "ca", MountPath: trustedCABundleMountPath}},
+			wantEnv:    bundle,
+			wantMounts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "test", Env: tt.env, VolumeMounts: tt.mounts},
+			}}}
+
+			injectTrustedCABundle(template, "custom-ca", "abc123")
+
+			c := template.Spec.Containers[0]
+			values := []string{}
+			for _, e := range c.Env {
+				if e.Name == sslCertFileEnvVar {
+					values = append(values, e.Value)
+				}
+			}
+			if len(values) != 1 || values[0] != tt.wantEnv {
+				t.Errorf("injectTrustedCABundle() SSL_CERT_FILE = %v, want [%s]", values, tt.wantEnv)
+			}
+			if len(c.VolumeMounts) != tt.wantMounts {
+				t.Errorf("injectTrustedCABundle() volume mounts = %v, want %d", c.VolumeMounts, tt.wantMounts)
+			}
+		})
+	}
+}
+
+func TestRenderLivenessFailurePolicy(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	tests := []struct {
+		name         string
+		policy       backplane.LivenessFailurePolicy
+		wantLiveness bool
+	}{
+		{name: "Default policy keeps liveness probe", policy: "", wantLiveness: true},
+		{name: "Restart policy keeps liveness probe", policy: backplane.LivenessFailureRestart, wantLiveness: true},
+		{name: "Unready policy removes liveness probe", policy: backplane.LivenessFailureUnready, wantLiveness: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testBackplane := &backplane.MultiClusterEngine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testBackplane",
+				},
+				Spec: backplane.MultiClusterEngineSpec{
+					TargetNamespace: "default",
+					Overrides: &backplane.Overrides{
+						Components: []backplane.ComponentConfig{
+							{Name: backplane.Discovery, Enabled: true, LivenessFailurePolicy: tt.policy},
+						},
+					},
+				},
+			}
+
+			templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+			if len(errs) > 0 {
+				for _, err := range errs {
+					t.Logf(err.Error())
+				}
+				t.Fatalf("failed to retrieve templates")
+			}
+			for _, template := range templates {
+				if template.GetKind() != "Deployment" {
+					continue
+				}
+				deployment := &appsv1.Deployment{}
+				err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				for _, c := range deployment.Spec.Template.Spec.Containers {
+					if (c.LivenessProbe != nil) != tt.wantLiveness {
+						t.Fatalf("Expected liveness probe present to be %t in container %s", tt.wantLiveness, c.Name)
+					}
+					if c.ReadinessProbe == nil {
+						t.Fatalf("Expected readiness probe in container %s", c.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSecurityContext(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	runAsNonRoot := false
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				SecurityContext: &backplane.SecurityContextOverrides{
+					RunAsNonRoot:     &runAsNonRoot,
+					SeccompProfile:   &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					DropCapabilities: []corev1.Capability{"NET_RAW"},
+				},
+			},
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		podSC := deployment.Spec.Template.Spec.SecurityContext
+		if podSC == nil || podSC.SeccompProfile == nil || podSC.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+			t.Fatalf("seccompProfile did not propagate to the %s deployment", deployment.Name)
+		}
+		if podSC.RunAsNonRoot == nil || !*podSC.RunAsNonRoot {
+			t.Fatalf("runAsNonRoot required by the %s deployment was loosened", deployment.Name)
+		}
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			drop := c.SecurityContext.Capabilities.Drop
+			if !reflect.DeepEqual(drop, []corev1.Capability{"ALL", "NET_RAW"}) {
+				t.Fatalf("Expected dropped capabilities to be merged in container %s. Got %v", c.Name, drop)
+			}
+			if c.SecurityContext.AllowPrivilegeEscalation == nil || *c.SecurityContext.AllowPrivilegeEscalation {
+				t.Fatalf("allowPrivilegeEscalation required by container %s was loosened", c.Name)
+			}
+		}
+	}
+}
+
+func TestRenderRestrictedSecurity(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				SecurityContext: &backplane.SecurityContextOverrides{Restricted: true},
+			},
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	// The always installed charts have no deployments, so the restricted mode is checked on the toggled ones
+	templates, errs := RenderCharts(chartsDir, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	checked := 0
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		checked++
+
+		podSC := deployment.Spec.Template.Spec.SecurityContext
+		if podSC == nil || podSC.RunAsNonRoot == nil || !*podSC.RunAsNonRoot {
+			t.Fatalf("Expected the %s deployment to run as non-root", deployment.Name)
+		}
+		if podSC.SeccompProfile == nil || podSC.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+			t.Fatalf("Expected the %s deployment to use the RuntimeDefault seccomp profile", deployment.Name)
+		}
+		containers := append(deployment.Spec.Template.Spec.InitContainers, deployment.Spec.Template.Spec.Containers...)
+		for _, c := range containers {
+			sc := c.SecurityContext
+			if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+				t.Fatalf("Expected container %s to disallow privilege escalation", c.Name)
+			}
+			if sc.Capabilities == nil || !containsCapability(sc.Capabilities.Drop, "ALL") {
+				t.Fatalf("Expected container %s to drop all capabilities", c.Name)
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatalf("Expected deployments to be rendered")
+	}
+}
+
+func TestRenderAffinity(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "node-role.kubernetes.io/infra", Operator: corev1.NodeSelectorOpExists},
+					},
+				},
+			},
+		},
+	}
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				Affinity: &corev1.Affinity{NodeAffinity: nodeAffinity},
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+					},
+				},
+			},
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		affinity := deployment.Spec.Template.Spec.Affinity
+		if affinity == nil || !reflect.DeepEqual(affinity.NodeAffinity, nodeAffinity) {
+			t.Fatalf("nodeAffinity did not propagate to the %s deployment", deployment.Name)
+		}
+		if affinity.PodAntiAffinity == nil {
+			t.Fatalf("Expected the default podAntiAffinity of the %s deployment to be kept", deployment.Name)
+		}
+
+		tsc := deployment.Spec.Template.Spec.TopologySpreadConstraints
+		if len(tsc) != 1 || tsc[0].TopologyKey != "topology.kubernetes.io/zone" {
+			t.Fatalf("topologySpreadConstraints did not propagate to the %s deployment", deployment.Name)
+		}
+		if !reflect.DeepEqual(tsc[0].LabelSelector, deployment.Spec.Selector) {
+			t.Fatalf("Expected topology spread constraint to select the pods of the %s deployment. Got %v", deployment.Name, tsc[0].LabelSelector)
+		}
+	}
+}
+
+func TestRenderInfraScheduling(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace:   "default",
+			SchedulingProfile: backplane.SchedulingInfra,
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		effects := map[corev1.TaintEffect]int{}
+		for _, toleration := range deployment.Spec.Template.Spec.Tolerations {
+			if toleration.Key == utils.InfraNodeLabel {
+				effects[toleration.Effect]++
+			}
+		}
+		if effects[corev1.TaintEffectNoSchedule] != 1 || effects[corev1.TaintEffectNoExecute] != 1 {
+			t.Fatalf("Expected the %s deployment to tolerate each infra taint once. Got %v", deployment.Name, deployment.Spec.Template.Spec.Tolerations)
+		}
+
+		affinity := deployment.Spec.Template.Spec.Affinity
+		if affinity == nil || affinity.NodeAffinity == nil || len(affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+			t.Fatalf("Expected the %s deployment to prefer infra nodes", deployment.Name)
+		}
+		if affinity.PodAntiAffinity == nil {
+			t.Fatalf("Expected the default podAntiAffinity of the %s deployment to be kept", deployment.Name)
+		}
+	}
+}
+
+func TestRenderPausedComponent(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testBackplane",
+		},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				Components: []backplane.ComponentConfig{
+					{Name: backplane.Discovery, Enabled: true, Paused: true},
+				},
+			},
+		},
+	}
+
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		for _, err := range errs {
+			t.Logf(err.Error())
+		}
+		t.Fatalf("failed to retrieve templates")
+	}
+	for _, template := range templates {
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+			t.Fatalf("Expected the paused %s deployment to be scaled to zero", deployment.Name)
+		}
+		if deployment.GetAnnotations()[utils.AnnotationComponentPaused] != "true" {
+			t.Fatalf("Expected the %s deployment to be annotated as paused", deployment.Name)
+		}
+	}
+}
+
+func TestRenderComponentLabel(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to retrieve templates: %v", errs)
+	}
+	for _, template := range templates {
+		if component := template.GetLabels()[ComponentLabel]; component != backplane.Discovery {
+			t.Errorf("Expected %s %s to be labeled for component %s, got %q", template.GetKind(), template.GetName(), backplane.Discovery, component)
+		}
+	}
+
+	components, err := ChartComponents(chartsDir)
+	if err != nil {
+		t.Fatalf("failed to list chart components: %v", err)
+	}
+	for _, expected := range []string{backplane.Discovery, backplane.HyperShift, backplane.ManagedServiceAccount, backplane.ClusterManager, backplane.ClusterProxyAddon} {
+		found := false
+		for _, component := range components {
+			found = found || component == expected
+		}
+		if !found {
+			t.Errorf("Expected component %s in %v", expected, components)
+		}
+	}
+}
+
+func TestRenderScopedRBAC(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+	os.Setenv("POD_NAMESPACE", "default")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	// API groups owned by an operand, on which that operand may be granted every verb
+	ownedGroups := map[string]bool{
+		"hive.openshift.io":            true,
+		"hiveinternal.openshift.io":    true,
+		"extensions.hive.openshift.io": true,
+	}
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	var templates []*unstructured.Unstructured
+	for _, dir := range []string{chartsDir, AlwaysChartsDir} {
+		rendered, errs := RenderCharts(dir, testBackplane, Options{Images: testImages})
+		if len(errs) > 0 {
+			t.Fatalf("failed to render charts in %s: %v", dir, errs)
+		}
+		templates = append(templates, rendered...)
+	}
+
+	for _, template := range templates {
+		if template.GetKind() != "ClusterRole" && template.GetKind() != "Role" {
+			continue
+		}
+		role := &rbacv1.ClusterRole{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, role); err != nil {
+			t.Fatalf("failed to convert %s %s: %v", template.GetKind(), template.GetName(), err)
+		}
+		for _, rule := range role.Rules {
+			if !utils.Contains(rule.Verbs, rbacv1.VerbAll) {
+				continue
+			}
+			for _, group := range rule.APIGroups {
+				if !ownedGroups[group] {
+					t.Errorf("%s %s grants all verbs on %v in API group %q", template.GetKind(), template.GetName(), rule.Resources, group)
+				}
+			}
+		}
+	}
+
+	managedServiceAccount, errs := RenderChart(chartsPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to render chart: %v", errs)
+	}
+	for _, template := range managedServiceAccount {
+		if template.GetKind() == "Role" && template.GetNamespace() != testBackplane.Spec.TargetNamespace {
+			t.Errorf("Expected Role %s in namespace %s, got %q", template.GetName(), testBackplane.Spec.TargetNamespace, template.GetNamespace())
+		}
+	}
+}
+
+func TestRenderClusterProxyAddon(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/" + v + ":Test"
+	}
+
+	templates, errs := RenderChart("pkg/templates/charts/toggle/cluster-proxy-addon", testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to render chart: %v", errs)
+	}
+	found := map[string]bool{}
+	for _, template := range templates {
+		found[template.GetKind()] = true
+		switch template.GetKind() {
+		case "Deployment":
+			deployment := &appsv1.Deployment{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment); err != nil {
+				t.Fatalf("failed to convert deployment: %v", err)
+			}
+			if image := deployment.Spec.Template.Spec.Containers[0].Image; image != testImages["cluster_proxy"] {
+				t.Errorf("Expected addon manager image %s, got %s", testImages["cluster_proxy"], image)
+			}
+		case "ManagedProxyConfiguration":
+			image, _, _ := unstructured.NestedString(template.Object, "spec", "proxyServer", "image")
+			if image != testImages["apiserver_network_proxy"] {
+				t.Errorf("Expected proxy server image %s, got %s", testImages["apiserver_network_proxy"], image)
+			}
+			namespace, _, _ := unstructured.NestedString(template.Object, "spec", "proxyServer", "namespace")
+			if namespace != testBackplane.Spec.TargetNamespace {
+				t.Errorf("Expected proxy servers in namespace %s, got %s", testBackplane.Spec.TargetNamespace, namespace)
+			}
+		}
+		if component := template.GetLabels()[ComponentLabel]; component != backplane.ClusterProxyAddon {
+			t.Errorf("Expected %s %s to be labeled for component %s, got %q", template.GetKind(), template.GetName(), backplane.ClusterProxyAddon, component)
+		}
+	}
+	for _, kind := range []string{"Deployment", "ClusterManagementAddOn", "ManagedProxyConfiguration"} {
+		if !found[kind] {
+			t.Errorf("Expected the chart to render a %s", kind)
+		}
+	}
+}
+
+func TestRenderHyperShiftAddon(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart("pkg/templates/charts/toggle/hypershift", testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to render chart: %v", errs)
+	}
+	for _, template := range templates {
+		if template.GetKind() == "ClusterManagementAddOn" && template.GetName() == "hypershift-addon" {
+			return
+		}
+	}
+	t.Errorf("Expected the hypershift chart to register the hypershift-addon ClusterManagementAddOn")
+}
+
+func TestRenderMetadataOverrides(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			Overrides: &backplane.Overrides{
+				Labels:      map[string]string{"example.com/cost-center": "1234", "backplaneconfig.name": "other"},
+				Annotations: map[string]string{"example.com/owner": "platform"},
+			},
+		},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to retrieve templates: %v", errs)
+	}
+	for _, template := range templates {
+		if template.GetLabels()["example.com/cost-center"] != "1234" {
+			t.Errorf("Expected %s %s to have the label override", template.GetKind(), template.GetName())
+		}
+		if template.GetLabels()["backplaneconfig.name"] != "testBackplane" {
+			t.Errorf("Expected %s %s to keep the operator's own label", template.GetKind(), template.GetName())
+		}
+		if template.GetAnnotations()["example.com/owner"] != "platform" {
+			t.Errorf("Expected %s %s to have the annotation override", template.GetKind(), template.GetName())
+		}
+		if template.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if deployment.Spec.Template.Labels["example.com/cost-center"] != "1234" || deployment.Spec.Template.Annotations["example.com/owner"] != "platform" {
+			t.Errorf("Expected the pods of %s to have the overrides", deployment.Name)
+		}
+		for key, value := range deployment.Spec.Selector.MatchLabels {
+			if deployment.Spec.Template.Labels[key] != value {
+				t.Errorf("Expected the pods of %s to keep matching the selector", deployment.Name)
+			}
+		}
+	}
+}
+
+func TestApplyArchitectureAffinity(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+	templates, errs := RenderChart(discoveryChartPath, testBackplane, Options{Images: testImages})
+	if len(errs) > 0 {
+		t.Fatalf("failed to retrieve templates: %v", errs)
+	}
+	var template *unstructured.Unstructured
+	for _, tmpl := range templates {
+		if tmpl.GetKind() == "Deployment" {
+			template = tmpl
+		}
+	}
+	if template == nil {
+		t.Fatalf("Expected the chart to render a deployment")
+	}
+
+	t.Run("Unknown image is left unchanged", func(t *testing.T) {
+		u := template.DeepCopy()
+		if err := ApplyArchitectureAffinity(u, map[string][]string{"quay.io/test/other:Test": {"amd64"}}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(u.Object, template.Object) {
+			t.Errorf("Expected a deployment with images of unknown architectures to be left unchanged")
+		}
+	})
+
+	t.Run("Architectures of the images are required", func(t *testing.T) {
+		u := template.DeepCopy()
+		if err := ApplyArchitectureAffinity(u, map[string][]string{"quay.io/test/test:Test": {"amd64", "arm64", "s390x"}}); err != nil {
+			t.Fatal(err)
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			t.Fatalf(err.Error())
+		}
+		want := corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64", "s390x"}}
+		affinity := deployment.Spec.Template.Spec.Affinity
+		if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			t.Fatalf("Expected a required node affinity")
+		}
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			if !reflect.DeepEqual(term.MatchExpressions[len(term.MatchExpressions)-1], want) {
+				t.Errorf("Expected node selector term %v to require %v", term, want)
+			}
+		}
+		if affinity.PodAntiAffinity == nil {
+			t.Errorf("Expected the pod anti-affinity to be kept")
+		}
+	})
+
+	t.Run("Existing node selector terms are narrowed", func(t *testing.T) {
+		u := template.DeepCopy()
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			t.Fatalf(err.Error())
+		}
+		zone := corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+		deployment.Spec.Template.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zone}}},
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u.Object = obj
+
+		if err := ApplyArchitectureAffinity(u, map[string][]string{"quay.io/test/test:Test": {"arm64"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			t.Fatalf(err.Error())
+		}
+		terms := deployment.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		if len(terms) != 1 || len(terms[0].MatchExpressions) != 2 || !reflect.DeepEqual(terms[0].MatchExpressions[0], zone) {
+			t.Errorf("Expected the architecture to be required in addition to the zone, got %v", terms)
+		}
+	})
+}
+
+func TestRenderLogLevel(t *testing.T) {
+	os.Setenv("DIRECTORY_OVERRIDE", "../../")
+	defer os.Unsetenv("DIRECTORY_OVERRIDE")
+
+	testBackplane := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec: backplane.MultiClusterEngineSpec{
+			TargetNamespace: "default",
+			LogLevel:        "debug",
+		},
+	}
+	testImages := map[string]string{}
+	for _, v := range utils.GetTestImages() {
+		testImages[v] = "quay.io/test/test:Test"
+	}
+
+	for _, chart := range []string{discoveryChartPath, "pkg/templates/charts/toggle/hive-operator"} {
+		templates, errs := RenderChart(chart, testBackplane, Options{Images: testImages})
+		if len(errs) > 0 {
+			t.Fatalf("failed to retrieve templates: %v", errs)
+		}
+		for _, template := range templates {
+			if template.GetKind() != "Deployment" {
+				continue
+			}
+			deployment := &appsv1.Deployment{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, deployment); err != nil {
+				t.Fatalf(err.Error())
+			}
+			for _, c := range deployment.Spec.Template.Spec.Containers {
+				found := false
+				for _, e := range c.Env {
+					if e.Name == logLevelEnvVar && e.Value == "debug" {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Expected container %s of %s to have %s=debug", c.Name, deployment.Name, logLevelEnvVar)
+				}
+			}
+			if deployment.Name == "hive-operator" {
+				command := deployment.Spec.Template.Spec.Containers[0].Command
+				if !reflect.DeepEqual(command[len(command)-2:], []string{"--log-level", "debug"}) {
+					t.Errorf("Expected hive-operator to be run with --log-level debug, got %v", command)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderOCPVersion(t *testing.T) {
+	mce := &backplane.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "testBackplane"},
+		Spec:       backplane.MultiClusterEngineSpec{TargetNamespace: "default"},
+	}
+
+	values := &Values{}
+	injectValuesOverrides(values, mce, Options{OCPVersion: "4.12.3"})
+	if values.HubConfig.OCPVersion != "4.12.3" {
+		t.Errorf("Expected the OCP version 4.12.3 to be rendered, got %q", values.HubConfig.OCPVersion)
+	}
+}
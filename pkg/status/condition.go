@@ -7,6 +7,8 @@ import (
 )
 
 const (
+	// ComponentDegradedType is the condition type of a component whose rollout has failed
+	ComponentDegradedType = "Degraded"
 	// ComponentsAvailableReason is when all desired components are running successfully
 	ComponentsAvailableReason = "ComponentsAvailable"
 	// ComponentsUnavailableReason is when one or more components are in an unready state
@@ -15,6 +17,8 @@ const (
 	DeployFailedReason = "FailedDeployingComponent"
 	// DeploySuccessReason is when all component have been deployed
 	DeploySuccessReason = "ComponentsDeployed"
+	// RolloutCompleteReason is when all components have been deployed and are available
+	RolloutCompleteReason = "RolloutComplete"
 	// RequirementsNotMetReason is when there is something missing or misconfigured
 	// that is preventing progress
 	RequirementsNotMetReason = "RequirementsNotMet"
@@ -24,6 +28,53 @@ const (
 	WaitingForResourceReason = "WaitingForResource"
 	// PausedReason is added when the multiclusterengine is paused
 	PausedReason = "Paused"
+	// ComponentsDegradedReason is when one or more components have failed to roll out
+	ComponentsDegradedReason = "ComponentsDegraded"
+	// ComponentsHealthyReason is when no components have failed to roll out
+	ComponentsHealthyReason = "ComponentsHealthy"
+	// ProgressDeadlineExceededReason is set by a deployment that has not progressed within its deadline
+	ProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+	// ImagePullErrorReason is set on a component whose pods cannot pull their image
+	ImagePullErrorReason = "ImagePullError"
+	// CrashLoopBackOffReason is set on a component whose containers keep crashing
+	CrashLoopBackOffReason = "CrashLoopBackOff"
+	// InstallTimeoutReason is set on a component that has not become available within the ready timeout
+	InstallTimeoutReason = "InstallTimeout"
+	// PrerequisitesNotMetReason is when the cluster does not meet a requirement for installing
+	PrerequisitesNotMetReason = "PrerequisitesNotMet"
+	// PrerequisitesMetReason is when the cluster meets all requirements for installing
+	PrerequisitesMetReason = "PrerequisitesMet"
+	// ConfigLoadedReason is when all external configuration was loaded successfully
+	ConfigLoadedReason = "ConfigLoaded"
+	// ConfigLoadFailedReason is when external configuration could not be read or parsed
+	ConfigLoadFailedReason = "ConfigLoadFailed"
+	// DowngradeUnsupportedReason is when the running operator is older than the version that installed the
+	// multiclusterengine
+	DowngradeUnsupportedReason = "DowngradeUnsupported"
+	// DuplicateInstanceReason is when another multiclusterengine is already installed
+	DuplicateInstanceReason = "DuplicateInstance"
+	// OwnershipConflictReason is when a resource the operator manages is already owned by another controller
+	OwnershipConflictReason = "OwnershipConflict"
+	// ApplyConflictReason is when a resource the operator manages was not applied because another field manager
+	// changed fields the operator sets
+	ApplyConflictReason = "ApplyConflict"
+	// AdoptingExistingResourcesReason is when a restored multiclusterengine takes over the resources that already
+	// exist on the cluster
+	AdoptingExistingResourcesReason = "AdoptingExistingResources"
+	// WebhookRecreatedReason is when the validating webhook configuration was deleted and has been recreated
+	WebhookRecreatedReason = "WebhookRecreated"
+	// WebhookRestoredReason is when the validating webhook configuration was changed and has been restored
+	WebhookRestoredReason = "WebhookRestored"
+	// StoredVersionConflictReason is set on a CRD that was not updated because the new definition no longer serves
+	// a version its resources are stored in
+	StoredVersionConflictReason = "StoredVersionConflict"
+	// DryRunReason is when the manifests were rendered for a dry run and nothing was applied
+	DryRunReason = "DryRun"
+	// UpgradingComponentsReason is when the components are being upgraded one at a time and the upgrade waits on a
+	// component to become available
+	UpgradingComponentsReason = "UpgradingComponents"
+	// ComponentsUpgradedReason is when every component has been upgraded to the running operator version
+	ComponentsUpgradedReason = "ComponentsUpgraded"
 )
 
 // NewCondition creates a new condition.
@@ -38,6 +89,14 @@ func NewCondition(condType v1.MultiClusterEngineConditionType, status metav1.Con
 	}
 }
 
+// NewConfigReloadedCondition creates a condition reporting the outcome of loading external configuration
+func NewConfigReloadedCondition(err error) v1.MultiClusterEngineCondition {
+	if err != nil {
+		return NewCondition(v1.MultiClusterEngineConfigReloaded, metav1.ConditionFalse, ConfigLoadFailedReason, err.Error())
+	}
+	return NewCondition(v1.MultiClusterEngineConfigReloaded, metav1.ConditionTrue, ConfigLoadedReason, "External configuration loaded")
+}
+
 // SetCondition sets the status condition. It either overwrites the existing one or creates a new one.
 func setCondition(conditions []v1.MultiClusterEngineCondition, c v1.MultiClusterEngineCondition) []v1.MultiClusterEngineCondition {
 	currentCond := getCondition(conditions, c.Type)
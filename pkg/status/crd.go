@@ -0,0 +1,118 @@
+// Copyright Contributors to the Open Cluster Management project
+package status
+
+import (
+	"context"
+	"fmt"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CRDStatus fulfills the StatusReporter interface for the operand CRDs
+type CRDStatus struct {
+	Name string
+	// Versions are the versions served by the definition of the CRD the operator applies
+	Versions []string
+}
+
+func (cs CRDStatus) GetName() string {
+	return cs.Name
+}
+
+func (cs CRDStatus) GetNamespace() string {
+	return ""
+}
+
+func (cs CRDStatus) GetKind() string {
+	return "CustomResourceDefinition"
+}
+
+// Converts a CRD's status to a backplane component status
+func (cs CRDStatus) Status(k8sClient client.Client) bpv1.ComponentCondition {
+	crd := &apixv1.CustomResourceDefinition{}
+	err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: cs.Name}, crd)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Log.WithName("status").Error(err, "Failed to get CRD", "name", cs.Name)
+		return unknownStatus(cs.GetName(), cs.GetKind())
+	} else if apierrors.IsNotFound(err) {
+		return unknownStatus(cs.GetName(), cs.GetKind())
+	}
+	return mapCRD(crd, cs.Versions)
+}
+
+func mapCRD(crd *apixv1.CustomResourceDefinition, versions []string) bpv1.ComponentCondition {
+	if version := UnservedStoredVersion(crd, versions); version != "" {
+		return bpv1.ComponentCondition{
+			Name:               crd.Name,
+			Kind:               "CustomResourceDefinition",
+			Type:               string(apixv1.Established),
+			Status:             metav1.ConditionFalse,
+			LastUpdateTime:     metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+			Reason:             StoredVersionConflictReason,
+			Message:            fmt.Sprintf("Resources are stored in version %s, which the new definition no longer serves", version),
+			Available:          false,
+		}
+	}
+
+	for _, c := range crd.Status.Conditions {
+		if c.Type != apixv1.Established {
+			continue
+		}
+		return bpv1.ComponentCondition{
+			Name:               crd.Name,
+			Kind:               "CustomResourceDefinition",
+			Type:               string(c.Type),
+			Status:             metav1.ConditionStatus(string(c.Status)),
+			LastUpdateTime:     metav1.Now(),
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			Available:          c.Status == apixv1.ConditionTrue,
+		}
+	}
+	return unknownStatus(crd.Name, "CustomResourceDefinition")
+}
+
+// CRDVersions returns the versions served by a rendered CRD
+func CRDVersions(crd *unstructured.Unstructured) []string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	served := []string{}
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		if isServed, _, _ := unstructured.NestedBool(version, "served"); isServed {
+			served = append(served, name)
+		}
+	}
+	return served
+}
+
+// UnservedStoredVersion returns a version the resources of the CRD are stored in that is not among the served
+// versions, or an empty string if all stored versions are served. Updating the CRD to such a definition would
+// leave the stored resources unreadable.
+func UnservedStoredVersion(crd *apixv1.CustomResourceDefinition, served []string) string {
+	for _, stored := range crd.Status.StoredVersions {
+		found := false
+		for _, v := range served {
+			if v == stored {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return stored
+		}
+	}
+	return ""
+}
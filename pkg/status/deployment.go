@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,10 +43,90 @@ func (ds DeploymentStatus) Status(k8sClient client.Client) bpv1.ComponentConditi
 		return unknownStatus(ds.GetName(), ds.GetKind())
 	}
 
-	return mapDeployment(deploy)
+	cc := mapDeployment(deploy)
+	if !cc.Available {
+		pods := deploymentPods(k8sClient, deploy)
+		if image, message := imagePullFailure(pods); image != "" {
+			cc.Reason = ImagePullErrorReason
+			cc.Message = fmt.Sprintf("Failed to pull image %s: %s", image, message)
+		} else if container, message := crashLoopFailure(pods); container != "" {
+			cc.Reason = CrashLoopBackOffReason
+			cc.Message = fmt.Sprintf("Container %s is crash looping: %s", container, message)
+		}
+	}
+	return cc
+}
+
+// imagePullFailures are the waiting reasons of a container whose image cannot be pulled
+var imagePullFailures = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// deploymentPods returns the pods selected by the deployment. Pods that cannot be listed are not diagnosed.
+func deploymentPods(k8sClient client.Client, deploy *appsv1.Deployment) []corev1.Pod {
+	if deploy.Spec.Selector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil
+	}
+	pods := &corev1.PodList{}
+	err = k8sClient.List(context.TODO(), pods, client.InNamespace(deploy.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil
+	}
+	return pods.Items
+}
+
+// imagePullFailure returns the image and waiting message of the first container of the pods that is stuck
+// pulling its image, or empty strings if there is none
+func imagePullFailure(pods []corev1.Pod) (string, string) {
+	for _, pod := range pods {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && imagePullFailures[cs.State.Waiting.Reason] {
+				return cs.Image, cs.State.Waiting.Message
+			}
+		}
+	}
+	return "", ""
+}
+
+// crashLoopFailure returns the name and waiting message of the first container of the pods that is backing off
+// restarting after crashing, or empty strings if there is none
+func crashLoopFailure(pods []corev1.Pod) (string, string) {
+	for _, pod := range pods {
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return cs.Name, cs.State.Waiting.Message
+			}
+		}
+	}
+	return "", ""
 }
 
 func mapDeployment(ds *appsv1.Deployment) bpv1.ComponentCondition {
+	// A paused component is deliberately scaled down and does not count against availability
+	if ds.GetAnnotations()[utils.AnnotationComponentPaused] == "true" {
+		return bpv1.ComponentCondition{
+			Name:               ds.Name,
+			Kind:               "Deployment",
+			Type:               "Paused",
+			Status:             metav1.ConditionTrue,
+			LastUpdateTime:     metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+			Reason:             PausedReason,
+			Message:            "Component is paused",
+			Available:          true,
+			ReadyReplicas:      ds.Status.ReadyReplicas,
+		}
+	}
+
 	if len(ds.Status.Conditions) < 1 {
 		return unknownStatus(ds.Name, ds.Kind)
 	}
@@ -83,6 +164,27 @@ func mapDeployment(ds *appsv1.Deployment) bpv1.ComponentCondition {
 		}
 	}
 
+	// A deployment that has exceeded its progress deadline has failed its rollout, regardless of
+	// its other conditions
+	if sub := progressingDeployCondition(ds.Status.Conditions); sub.Status == corev1.ConditionFalse && sub.Reason == ProgressDeadlineExceededReason {
+		ret = bpv1.ComponentCondition{
+			Name:               ds.Name,
+			Kind:               "Deployment",
+			Type:               string(sub.Type),
+			Status:             metav1.ConditionStatus(string(sub.Status)),
+			LastUpdateTime:     sub.LastUpdateTime,
+			LastTransitionTime: sub.LastTransitionTime,
+			Reason:             sub.Reason,
+			Message:            sub.Message,
+			Available:          false,
+		}
+	}
+
+	if ds.Spec.Replicas != nil {
+		ret.DesiredReplicas = *ds.Spec.Replicas
+	}
+	ret.ReadyReplicas = ds.Status.ReadyReplicas
+
 	return ret
 }
 
@@ -120,6 +222,20 @@ func successfulDeploy(d *appsv1.Deployment) bool {
 		return false
 	}
 
+	// The deployment controller has not yet acted on the latest spec
+	if d.Status.ObservedGeneration < d.Generation {
+		return false
+	}
+
+	// The rollout of the latest spec is complete once every desired replica is updated and ready, and no
+	// replicas of the previous spec remain
+	if d.Spec.Replicas != nil {
+		desired := *d.Spec.Replicas
+		if d.Status.UpdatedReplicas < desired || d.Status.ReadyReplicas < desired || d.Status.Replicas > d.Status.UpdatedReplicas {
+			return false
+		}
+	}
+
 	return true
 	// latest := latestDeployCondition(d.Status.Conditions)
 }
@@ -0,0 +1,85 @@
+// Copyright Contributors to the Open Cluster Management project
+package status
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// mceLabel is the label naming the MultiClusterEngine a metric is reported for
+const mceLabel = "multiclusterengine"
+
+// progressGauge exposes the installation progress of each multiclusterengine so it can be graphed during installs
+// and upgrades
+var progressGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mce_install_progress_percent",
+	Help: "Percentage of enabled MultiClusterEngine components that are available",
+}, []string{mceLabel})
+
+// componentReadyGauge exposes the availability of each tracked component so a stuck component can be alerted on
+var componentReadyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mce_component_ready",
+	Help: "Whether a MultiClusterEngine component is available (1) or not (0)",
+}, []string{mceLabel, "component"})
+
+// installDurationGauge exposes how long the first install of each multiclusterengine took
+var installDurationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mce_install_duration_seconds",
+	Help: "Seconds from the creation of the MultiClusterEngine until it first became available",
+}, []string{mceLabel})
+
+func init() {
+	metrics.Registry.MustRegister(progressGauge, componentReadyGauge, installDurationGauge)
+}
+
+// reportComponentMetrics sets the ready gauge of each component of the multiclusterengine. Components no longer
+// tracked are dropped.
+func (sm *StatusTracker) reportComponentMetrics(name string, components []bpv1.ComponentCondition) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	reported := make([]string, 0, len(components))
+	for _, c := range components {
+		ready := 0.0
+		if c.Available {
+			ready = 1
+		}
+		componentReadyGauge.WithLabelValues(name, c.Name).Set(ready)
+		reported = append(reported, c.Name)
+	}
+	for _, c := range sm.metricComponents {
+		if !utils.Contains(reported, c) {
+			componentReadyGauge.DeleteLabelValues(name, c)
+		}
+	}
+	sm.metricsName = name
+	sm.metricComponents = reported
+}
+
+// deleteMetrics drops the metrics reported for the multiclusterengine, once it has been deleted
+func (sm *StatusTracker) deleteMetrics() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.metricsName == "" {
+		return
+	}
+	progressGauge.DeleteLabelValues(sm.metricsName)
+	installDurationGauge.DeleteLabelValues(sm.metricsName)
+	for _, c := range sm.metricComponents {
+		componentReadyGauge.DeleteLabelValues(sm.metricsName, c)
+	}
+	sm.metricsName = ""
+	sm.metricComponents = nil
+}
+
+// reportInstallDuration records the install duration when the multiclusterengine becomes available for the first
+// time, which is before any operator version has been recorded
+func reportInstallDuration(mce bpv1.MultiClusterEngine, phase bpv1.PhaseType) {
+	if phase != bpv1.MultiClusterEnginePhaseAvailable || mce.Status.CurrentVersion != "" || mce.CreationTimestamp.IsZero() {
+		return
+	}
+	installDurationGauge.WithLabelValues(mce.Name).Set(time.Since(mce.CreationTimestamp.Time).Seconds())
+}
@@ -0,0 +1,60 @@
+// Copyright Contributors to the Open Cluster Management project
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+)
+
+// RecordReconcile records the outcome of a reconcile of the tracked MultiClusterEngine for ReadyzCheck. Once a
+// reconcile has succeeded the MultiClusterEngine is ready whenever all of its components are available.
+func (sm *StatusTracker) RecordReconcile(mce bpv1.MultiClusterEngine, err error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.name = mce.Name
+	if err == nil {
+		sm.reconciled = true
+	}
+	sm.available = mce.Status.Phase == bpv1.MultiClusterEnginePhaseAvailable
+}
+
+// ReadyzCheck reports ready once every MultiClusterEngine has been reconciled successfully at least once and all
+// of its components are available. If no MultiClusterEngine exists there is nothing to wait for.
+func (sm *StatusTracker) ReadyzCheck(req *http.Request) error {
+	sm.mu.Lock()
+	instances := make([]*StatusTracker, 0, len(sm.instances))
+	for _, tracker := range sm.instances {
+		instances = append(instances, tracker)
+	}
+	sm.mu.Unlock()
+
+	if len(instances) == 0 {
+		mceList := &bpv1.MultiClusterEngineList{}
+		if err := sm.Client.List(context.TODO(), mceList); err != nil {
+			return err
+		}
+		if len(mceList.Items) == 0 {
+			return nil
+		}
+		return errors.New("no MultiClusterEngine has been reconciled")
+	}
+
+	notReady := []string{}
+	for _, tracker := range instances {
+		tracker.mu.Lock()
+		if !tracker.reconciled || !tracker.available {
+			notReady = append(notReady, tracker.name)
+		}
+		tracker.mu.Unlock()
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return fmt.Errorf("MultiClusterEngines not reconciled or not available: %v", notReady)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright Contributors to the Open Cluster Management project
+package status
+
+import (
+	"fmt"
+	"time"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// componentRetry is the retry state of a component that failed to apply
+type componentRetry struct {
+	failures  int
+	lastError string
+	nextRetry time.Time
+}
+
+func reporterKey(sr StatusReporter) string {
+	return fmt.Sprintf("%s/%s/%s", sr.GetKind(), sr.GetNamespace(), sr.GetName())
+}
+
+// ApplyFailed records a failed attempt to apply the component and returns how long to wait before applying it
+// again. The delay doubles with every consecutive failure, starting from base and capped at max.
+func (sm *StatusTracker) ApplyFailed(sr StatusReporter, err error, base, max time.Duration) time.Duration {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.retries == nil {
+		sm.retries = map[string]componentRetry{}
+	}
+
+	retry := sm.retries[reporterKey(sr)]
+	retry.failures++
+	delay := base
+	for i := 1; i < retry.failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	retry.lastError = err.Error()
+	retry.nextRetry = time.Now().Add(delay)
+	sm.retries[reporterKey(sr)] = retry
+	return delay
+}
+
+// ApplySucceeded clears the retry state of the component
+func (sm *StatusTracker) ApplySucceeded(sr StatusReporter) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	delete(sm.retries, reporterKey(sr))
+}
+
+// RetryAfter returns how long to wait before applying the component again after a failed attempt, or zero if it
+// can be applied now
+func (sm *StatusTracker) RetryAfter(sr StatusReporter) time.Duration {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	retry, ok := sm.retries[reporterKey(sr)]
+	if !ok {
+		return 0
+	}
+	if wait := time.Until(retry.nextRetry); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// reportRetry adds the retry state of the component, if it failed to apply, to its status
+func (sm *StatusTracker) reportRetry(sr StatusReporter, cc *bpv1.ComponentCondition) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	retry, ok := sm.retries[reporterKey(sr)]
+	if !ok {
+		return
+	}
+	next := metav1.NewTime(retry.nextRetry)
+	cc.Retries = retry.failures
+	cc.LastError = retry.lastError
+	cc.NextRetryTime = &next
+}
@@ -2,7 +2,14 @@
 package status
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/version"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -12,17 +19,73 @@ type StatusTracker struct {
 	UID        string
 	Components []StatusReporter
 	Conditions []bpv1.MultiClusterEngineCondition
+	// HostedClient reads the component status from the hosted cluster instead of Client when set
+	HostedClient client.Client
+	// ReadyTimeout is how long a component may stay unavailable before it is reported as timed out. Zero
+	// disables the timeout.
+	ReadyTimeout time.Duration
+	// notReadySince records when each tracked component was first seen unavailable
+	notReadySince map[string]time.Time
+	// retries records the components that failed to apply and when to apply them again
+	retries map[string]componentRetry
+	// instances are the trackers of each MultiClusterEngine, keyed by UID
+	instances map[string]*StatusTracker
+	// name is the MultiClusterEngine last reconciled, reconciled records whether a reconcile of it has succeeded
+	// and available whether all of its components were available after the last one
+	name       string
+	reconciled bool
+	available  bool
+	// metricsName and metricComponents are the MultiClusterEngine and components whose metrics were last reported
+	metricsName      string
+	metricComponents []string
+	// mu guards the components, conditions, retries, readiness and instances, which components applied in parallel update
+	mu sync.Mutex
+}
+
+// Instance returns the tracker of the MultiClusterEngine with the given UID, so that the components and
+// conditions of one MultiClusterEngine are not reported in the status of another. It is created on first use
+// with the client and ready timeout of sm.
+func (sm *StatusTracker) Instance(uid string) *StatusTracker {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if tracker, ok := sm.instances[uid]; ok {
+		return tracker
+	}
+	if sm.instances == nil {
+		sm.instances = map[string]*StatusTracker{}
+	}
+	tracker := &StatusTracker{Client: sm.Client, ReadyTimeout: sm.ReadyTimeout}
+	tracker.Reset(uid)
+	sm.instances[uid] = tracker
+	return tracker
+}
+
+// RemoveInstance drops the tracker and the metrics of a MultiClusterEngine that has been deleted
+func (sm *StatusTracker) RemoveInstance(uid string) {
+	sm.mu.Lock()
+	tracker, ok := sm.instances[uid]
+	delete(sm.instances, uid)
+	sm.mu.Unlock()
+	if ok {
+		tracker.deleteMetrics()
+	}
 }
 
 // Flush out any cached data being tracked, and assigns the tracker to a UID
 func (sm *StatusTracker) Reset(uid string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	sm.UID = uid
 	sm.Components = []StatusReporter{}
 	sm.Conditions = []bpv1.MultiClusterEngineCondition{}
+	sm.notReadySince = map[string]time.Time{}
+	sm.retries = map[string]componentRetry{}
 }
 
 // Adds a StatusReporter to the list of statuses to watch
 func (sm *StatusTracker) AddComponent(sr StatusReporter) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	for _, c := range sm.Components {
 		if c.GetName() == sr.GetName() &&
 			c.GetNamespace() == sr.GetNamespace() &&
@@ -36,6 +99,8 @@ func (sm *StatusTracker) AddComponent(sr StatusReporter) {
 
 // Removes a StatusReporter from the list of statuses to watch
 func (sm *StatusTracker) RemoveComponent(sr StatusReporter) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	for i, c := range sm.Components {
 		if c.GetName() == sr.GetName() &&
 			c.GetNamespace() == sr.GetNamespace() &&
@@ -47,47 +112,156 @@ func (sm *StatusTracker) RemoveComponent(sr StatusReporter) {
 }
 
 func (sm *StatusTracker) AddCondition(c bpv1.MultiClusterEngineCondition) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	sm.Conditions = setCondition(sm.Conditions, c)
 }
 
 func (sm *StatusTracker) ReportStatus(mce bpv1.MultiClusterEngine) bpv1.MultiClusterEngineStatus {
 	components := sm.reportComponents()
+	sm.checkReadyTimeout(components)
+	markDegraded(components)
 
 	// Infer available condition from component health
 	if allComponentsReady(components) {
 		sm.AddCondition(NewCondition(bpv1.MultiClusterEngineAvailable, metav1.ConditionTrue, ComponentsAvailableReason, ""))
 
 	} else {
-		sm.AddCondition(NewCondition(bpv1.MultiClusterEngineAvailable, metav1.ConditionFalse, ComponentsUnavailableReason, ""))
+		sm.AddCondition(NewCondition(bpv1.MultiClusterEngineAvailable, metav1.ConditionFalse, ComponentsUnavailableReason,
+			unavailableMessage(components)))
 	}
 
-	conditions := sm.reportConditions()
+	// Infer degraded condition from failed component rollouts
+	if degraded := degradedComponents(components); len(degraded) > 0 {
+		sm.AddCondition(NewCondition(bpv1.MultiClusterEngineDegraded, metav1.ConditionTrue, ComponentsDegradedReason,
+			fmt.Sprintf("The following components have failed to roll out: %s", strings.Join(degraded, ", "))))
+	} else {
+		sm.AddCondition(NewCondition(bpv1.MultiClusterEngineDegraded, metav1.ConditionFalse, ComponentsHealthyReason, ""))
+	}
+
+	conditions := reportRollout(sm.reportConditions(), components, mce.Status.Conditions)
 	phase := sm.reportPhase(mce, components, conditions)
 
+	// Record the operator version once it has fully installed the multiclusterengine
+	currentVersion := mce.Status.CurrentVersion
+	if phase == bpv1.MultiClusterEnginePhaseAvailable {
+		currentVersion = version.Get().GitVersion
+	}
+
+	progress := sm.reportProgress(components, phase)
+	progressGauge.WithLabelValues(mce.Name).Set(float64(progress))
+	sm.reportComponentMetrics(mce.Name, components)
+	reportInstallDuration(mce, phase)
+	available, required := sm.countAvailable(components)
+
 	return bpv1.MultiClusterEngineStatus{
-		Components: components,
-		Conditions: conditions,
-		Phase:      phase,
+		Progress:            progress,
+		AvailableComponents: fmt.Sprintf("%d/%d", available, required),
+		Components:          components,
+		Conditions:          conditions,
+		Phase:               phase,
+		CurrentVersion:      currentVersion,
+		DesiredVersion:      version.Get().GitVersion,
+		ObservedGeneration:  mce.Status.ObservedGeneration,
+		LastReconcileTime:   mce.Status.LastReconcileTime,
+		BlockingResources:   mce.Status.BlockingResources,
+		Images:              mce.Status.Images,
+		RemainingResources:  mce.Status.RemainingResources,
+		FIPSEnabled:         mce.Status.FIPSEnabled,
+		MultiClusterHub:     mce.Status.MultiClusterHub,
 	}
 }
 
 func (sm *StatusTracker) reportComponents() []bpv1.ComponentCondition {
+	componentClient := sm.Client
+	if sm.HostedClient != nil {
+		componentClient = sm.HostedClient
+	}
 	components := []bpv1.ComponentCondition{}
 	for _, c := range sm.Components {
-		components = append(components, c.Status(sm.Client))
+		cc := c.Status(componentClient)
+		cc.Namespace = c.GetNamespace()
+		sm.reportRetry(c, &cc)
+		components = append(components, cc)
 	}
 	return components
 }
 
+// checkReadyTimeout marks the components that have been unavailable for longer than the ready timeout as timed
+// out, so that a stuck install can be told apart from a slow one. The components are still retried.
+func (sm *StatusTracker) checkReadyTimeout(components []bpv1.ComponentCondition) {
+	if sm.ReadyTimeout <= 0 {
+		return
+	}
+	if sm.notReadySince == nil {
+		sm.notReadySince = map[string]time.Time{}
+	}
+	now := time.Now()
+	for i := range components {
+		key := fmt.Sprintf("%s/%s/%s", sm.Components[i].GetKind(), sm.Components[i].GetNamespace(), sm.Components[i].GetName())
+		if components[i].Available {
+			delete(sm.notReadySince, key)
+			continue
+		}
+		if d, ok := sm.Components[i].(disabledReporter); ok && d.Disabled() {
+			continue
+		}
+		since, ok := sm.notReadySince[key]
+		if !ok {
+			sm.notReadySince[key] = now
+			continue
+		}
+		// An image pull error or crash loop already says why the component is stuck
+		if elapsed := now.Sub(since); elapsed >= sm.ReadyTimeout && components[i].Reason != ImagePullErrorReason &&
+			components[i].Reason != CrashLoopBackOffReason {
+			components[i].Reason = InstallTimeoutReason
+			components[i].Message = fmt.Sprintf("Component has not become available after %s", elapsed.Round(time.Second))
+		}
+	}
+}
+
 func (sm *StatusTracker) reportConditions() []bpv1.MultiClusterEngineCondition {
 	return sm.Conditions
 }
 
+// reportRollout derives the Progressing condition from the rollout of the components once all of them have been
+// deployed. Following OpenShift operator conventions, Progressing is false once every component is available.
+// The transition time is kept from the previous status so it doesn't change on every reconcile.
+func reportRollout(conditions []bpv1.MultiClusterEngineCondition, components []bpv1.ComponentCondition, previous []bpv1.MultiClusterEngineCondition) []bpv1.MultiClusterEngineCondition {
+	progress := getCondition(conditions, bpv1.MultiClusterEngineProgressing)
+	if progress == nil || progress.Status != metav1.ConditionTrue || progress.Reason != DeploySuccessReason || !allComponentsReady(components) {
+		return conditions
+	}
+
+	c := NewCondition(bpv1.MultiClusterEngineProgressing, metav1.ConditionFalse, RolloutCompleteReason, "All components are deployed and available")
+	if prev := getCondition(previous, bpv1.MultiClusterEngineProgressing); prev != nil && prev.Status == c.Status && prev.Reason == c.Reason {
+		c.LastUpdateTime = prev.LastUpdateTime
+		c.LastTransitionTime = prev.LastTransitionTime
+	}
+	return setCondition(append([]bpv1.MultiClusterEngineCondition{}, conditions...), c)
+}
+
 func (sm *StatusTracker) reportPhase(mce bpv1.MultiClusterEngine, components []bpv1.ComponentCondition, conditions []bpv1.MultiClusterEngineCondition) bpv1.PhaseType {
 	progress := getCondition(conditions, bpv1.MultiClusterEngineProgressing)
 
+	// If the operator refuses to reconcile an install from a newer version show failed phase
+	if progress != nil && progress.Status == metav1.ConditionFalse && progress.Reason == DowngradeUnsupportedReason {
+		return bpv1.MultiClusterEnginePhaseFailed
+	}
+
+	// A dry run installs nothing, so show the dry run phase rather than an error
+	if progress != nil && progress.Reason == DryRunReason && mce.GetDeletionTimestamp() == nil {
+		return bpv1.MultiClusterEnginePhaseDryRun
+	}
+
+	// If the cluster does not meet the requirements to install or upgrade show failed phase. Once the running
+	// version is installed the components are still reconciled, so the phase reflects them.
+	if prereq := getCondition(conditions, bpv1.MultiClusterEnginePrerequisiteFailed); prereq != nil && prereq.Status == metav1.ConditionTrue && mce.Status.CurrentVersion != version.Get().GitVersion {
+		return bpv1.MultiClusterEnginePhaseFailed
+	}
+
 	// If operator isn't progressing show error phase
-	if progress != nil && progress.Status == metav1.ConditionFalse {
+	if progress != nil && progress.Status == metav1.ConditionFalse && progress.Reason != RolloutCompleteReason {
 		return bpv1.MultiClusterEnginePhaseError
 	}
 
@@ -109,6 +283,38 @@ func (sm *StatusTracker) reportPhase(mce bpv1.MultiClusterEngine, components []b
 	return bpv1.MultiClusterEnginePhaseAvailable
 }
 
+// reportProgress returns the percentage of enabled components that are available. Components that are turned off
+// are not counted. Progress is only complete once the multiclusterengine is available.
+func (sm *StatusTracker) reportProgress(components []bpv1.ComponentCondition, phase bpv1.PhaseType) int {
+	if phase == bpv1.MultiClusterEnginePhaseAvailable {
+		return 100
+	}
+	available, required := sm.countAvailable(components)
+	if required == 0 {
+		return 0
+	}
+	progress := available * 100 / required
+	if progress > 99 {
+		progress = 99
+	}
+	return progress
+}
+
+// countAvailable returns the number of enabled components that are available and the number of enabled
+// components. Components that are turned off are not counted.
+func (sm *StatusTracker) countAvailable(components []bpv1.ComponentCondition) (available, required int) {
+	for i, c := range components {
+		if d, ok := sm.Components[i].(disabledReporter); ok && d.Disabled() {
+			continue
+		}
+		required++
+		if c.Available {
+			available++
+		}
+	}
+	return available, required
+}
+
 func allComponentsReady(components []bpv1.ComponentCondition) bool {
 	if len(components) == 0 {
 		return false
@@ -121,6 +327,51 @@ func allComponentsReady(components []bpv1.ComponentCondition) bool {
 	return true
 }
 
+// unavailableMessage names the components that are not available, with the reason of each
+func unavailableMessage(components []bpv1.ComponentCondition) string {
+	unavailable := []string{}
+	for _, val := range components {
+		if !val.Available {
+			unavailable = append(unavailable, fmt.Sprintf("%s (%s)", val.Name, val.Reason))
+		}
+	}
+	if len(unavailable) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("The following components are not available: %s", strings.Join(unavailable, ", "))
+}
+
+// degradedReasons are the reasons of components whose rollout has failed
+var degradedReasons = map[string]bool{
+	ProgressDeadlineExceededReason: true,
+	InstallTimeoutReason:           true,
+	ImagePullErrorReason:           true,
+	CrashLoopBackOffReason:         true,
+	StoredVersionConflictReason:    true,
+}
+
+// degradedComponents returns the names of components whose rollout has failed
+func degradedComponents(components []bpv1.ComponentCondition) []string {
+	degraded := []string{}
+	for _, val := range components {
+		if degradedReasons[val.Reason] {
+			degraded = append(degraded, val.Name)
+		}
+	}
+	return degraded
+}
+
+// markDegraded reports the components whose rollout has failed with a Degraded condition, so that a failed
+// component can be told apart from one that is still progressing
+func markDegraded(components []bpv1.ComponentCondition) {
+	for i := range components {
+		if degradedReasons[components[i].Reason] || components[i].Type == string(appsv1.DeploymentReplicaFailure) {
+			components[i].Type = ComponentDegradedType
+			components[i].Status = metav1.ConditionTrue
+		}
+	}
+}
+
 // StatusReporter is a resource that can report back a status
 type StatusReporter interface {
 	GetName() string
@@ -129,6 +380,11 @@ type StatusReporter interface {
 	Status(client.Client) bpv1.ComponentCondition
 }
 
+// disabledReporter is implemented by StatusReporters of components that are turned off
+type disabledReporter interface {
+	Disabled() bool
+}
+
 func unknownStatus(name, kind string) bpv1.ComponentCondition {
 	return bpv1.ComponentCondition{
 		Name:               name,
@@ -2,10 +2,20 @@
 package status
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/images"
+	"github.com/stolostron/backplane-operator/pkg/utils"
+	"github.com/stolostron/backplane-operator/pkg/version"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -92,6 +102,36 @@ func Test_RemoveComponent(t *testing.T) {
 	})
 }
 
+func Test_Instance(t *testing.T) {
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build(), ReadyTimeout: time.Minute}
+	a := tracker.Instance("uid-a")
+	b := tracker.Instance("uid-b")
+	a.AddComponent(MockStatus{NamespacedName: types.NamespacedName{Name: "mock-name", Namespace: "mock-ns"}})
+	a.AddCondition(NewCondition(bpv1.MultiClusterEngineProgressing, metav1.ConditionTrue, DeploySuccessReason, "All components deployed"))
+
+	t.Run("Instances are tracked separately", func(t *testing.T) {
+		if len(b.Components) != 0 || len(b.Conditions) != 0 {
+			t.Errorf("StatusTracker.Instance() shares state between instances")
+		}
+		if a.UID != "uid-a" || a.Client != tracker.Client || a.ReadyTimeout != time.Minute {
+			t.Errorf("StatusTracker.Instance() did not set up the instance from its parent")
+		}
+	})
+
+	t.Run("Instance is kept across calls", func(t *testing.T) {
+		if tracker.Instance("uid-a") != a {
+			t.Errorf("StatusTracker.Instance() returned a new tracker for the same UID")
+		}
+	})
+
+	t.Run("Removed instance starts over", func(t *testing.T) {
+		tracker.RemoveInstance("uid-a")
+		if len(tracker.Instance("uid-a").Components) != 0 {
+			t.Errorf("StatusTracker.RemoveInstance() did not drop the tracker")
+		}
+	})
+}
+
 func Test_AddCondition(t *testing.T) {
 	tracker := StatusTracker{}
 
@@ -127,3 +167,641 @@ func Test_AddCondition(t *testing.T) {
 		}
 	})
 }
+
+func Test_DegradedCondition(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-deploy", Namespace: "mock-ns"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+					Reason: "MinimumReplicasAvailable",
+				},
+				{
+					Type:   appsv1.DeploymentProgressing,
+					Status: corev1.ConditionFalse,
+					Reason: ProgressDeadlineExceededReason,
+				},
+			},
+		},
+	}
+
+	t.Run("Map deployment past its progress deadline", func(t *testing.T) {
+		cc := mapDeployment(deploy)
+		if cc.Available {
+			t.Errorf("Deployment past its progress deadline should not be available")
+		}
+		if cc.Reason != ProgressDeadlineExceededReason {
+			t.Errorf("Expected reason %s. Got %s", ProgressDeadlineExceededReason, cc.Reason)
+		}
+	})
+
+	t.Run("Report degraded condition", func(t *testing.T) {
+		tracker := StatusTracker{Client: fake.NewClientBuilder().WithObjects(deploy).Build()}
+		tracker.AddComponent(DeploymentStatus{
+			NamespacedName: types.NamespacedName{Name: "mock-deploy", Namespace: "mock-ns"},
+		})
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+
+		c := getCondition(status.Conditions, bpv1.MultiClusterEngineDegraded)
+		if c == nil {
+			t.Fatalf("Expected a degraded condition to be reported")
+		}
+		if c.Status != metav1.ConditionTrue || c.Reason != ComponentsDegradedReason {
+			t.Errorf("Expected degraded condition to be true. Got %v with reason %s", c.Status, c.Reason)
+		}
+
+		component := status.Components[0]
+		if component.Type != ComponentDegradedType || component.Status != metav1.ConditionTrue {
+			t.Errorf("Expected component condition Degraded=True. Got %s=%s", component.Type, component.Status)
+		}
+		if component.Namespace != "mock-ns" {
+			t.Errorf("Expected component namespace mock-ns. Got %s", component.Namespace)
+		}
+	})
+}
+
+func Test_ConfigReloadedCondition(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "mock-ns")
+	malformed := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-overrides", Namespace: "mock-ns"},
+		Data: map[string]string{
+			"overrides.json": "{not json",
+		},
+	}
+	mce := &bpv1.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mce",
+			Annotations: map[string]string{utils.AnnotationImageOverridesCM: "image-overrides"},
+		},
+	}
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().WithObjects(malformed).Build()}
+
+	t.Run("Malformed image overrides configmap", func(t *testing.T) {
+		_, err := images.GetImagesWithOverrides(tracker.Client, mce)
+		if err == nil {
+			t.Fatalf("Expected an error loading a malformed image overrides configmap")
+		}
+		tracker.AddCondition(NewConfigReloadedCondition(err))
+
+		c := getCondition(tracker.ReportStatus(*mce).Conditions, bpv1.MultiClusterEngineConfigReloaded)
+		if c == nil {
+			t.Fatalf("Expected a ConfigReloaded condition to be reported")
+		}
+		if c.Status != metav1.ConditionFalse || c.Reason != ConfigLoadFailedReason {
+			t.Errorf("Expected ConfigReloaded condition to be false. Got %v with reason %s", c.Status, c.Reason)
+		}
+		if c.Message != err.Error() {
+			t.Errorf("Expected condition message %q. Got %q", err.Error(), c.Message)
+		}
+	})
+
+	t.Run("Config loaded", func(t *testing.T) {
+		tracker.AddCondition(NewConfigReloadedCondition(nil))
+		c := getCondition(tracker.ReportStatus(*mce).Conditions, bpv1.MultiClusterEngineConfigReloaded)
+		if c == nil || c.Status != metav1.ConditionTrue {
+			t.Errorf("Expected ConfigReloaded condition to be true")
+		}
+	})
+}
+
+func Test_ObservedGeneration(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-deploy", Namespace: "mock-ns", Generation: 2},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+					Reason: "MinimumReplicasAvailable",
+				},
+			},
+		},
+	}
+
+	t.Run("Deployment with unobserved spec change", func(t *testing.T) {
+		if cc := mapDeployment(deploy); cc.Available {
+			t.Errorf("Deployment whose latest generation has not been observed should not be available")
+		}
+	})
+
+	t.Run("Carry over observed generation", func(t *testing.T) {
+		now := metav1.Now()
+		mce := bpv1.MultiClusterEngine{
+			Status: bpv1.MultiClusterEngineStatus{ObservedGeneration: 3, LastReconcileTime: &now},
+		}
+		tracker := StatusTracker{Client: fake.NewClientBuilder().Build()}
+		status := tracker.ReportStatus(mce)
+		if status.ObservedGeneration != 3 {
+			t.Errorf("Expected observed generation 3. Got %d", status.ObservedGeneration)
+		}
+		if status.LastReconcileTime == nil || !status.LastReconcileTime.Equal(&now) {
+			t.Errorf("Expected last reconcile time to be carried over")
+		}
+		if status.DesiredVersion != version.Get().GitVersion {
+			t.Errorf("Expected desired version %s. Got %s", version.Get().GitVersion, status.DesiredVersion)
+		}
+	})
+}
+
+func Test_PausedComponent(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mock-deploy",
+			Namespace:   "mock-ns",
+			Annotations: map[string]string{utils.AnnotationComponentPaused: "true"},
+		},
+	}
+
+	cc := mapDeployment(deploy)
+	if !cc.Available {
+		t.Errorf("Paused deployment should not count against availability")
+	}
+	if cc.Type != "Paused" || cc.Reason != PausedReason {
+		t.Errorf("Expected paused deployment to be reported as paused. Got type %s with reason %s", cc.Type, cc.Reason)
+	}
+}
+
+// MockDisabledStatus fulfills the StatusReporter interface for a component that is turned off but still uninstalling
+type MockDisabledStatus struct {
+	MockStatus
+}
+
+func (ms MockDisabledStatus) Disabled() bool {
+	return true
+}
+
+func (ms MockDisabledStatus) Status(k8sClient client.Client) bpv1.ComponentCondition {
+	cc := ms.MockStatus.Status(k8sClient)
+	cc.Available = false
+	return cc
+}
+
+func Test_Progress(t *testing.T) {
+	available := MockStatus{NamespacedName: types.NamespacedName{Name: "mock-available", Namespace: "mock-ns"}}
+	missing := DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-missing", Namespace: "mock-ns"}}
+	disabled := MockDisabledStatus{MockStatus{NamespacedName: types.NamespacedName{Name: "mock-disabled", Namespace: "mock-ns"}}}
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build()}
+	tracker.AddComponent(available)
+	tracker.AddComponent(missing)
+	tracker.AddComponent(disabled)
+
+	t.Run("Disabled components are not counted", func(t *testing.T) {
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		if status.Progress != 50 {
+			t.Errorf("Expected progress 50. Got %d", status.Progress)
+		}
+		if status.AvailableComponents != "1/2" {
+			t.Errorf("Expected 1/2 available components. Got %s", status.AvailableComponents)
+		}
+	})
+
+	t.Run("Progress is incomplete until available", func(t *testing.T) {
+		tracker.RemoveComponent(missing)
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		if status.Phase == bpv1.MultiClusterEnginePhaseAvailable {
+			t.Fatalf("Expected the uninstalling component to keep the multiclusterengine from being available")
+		}
+		if status.Progress != 99 {
+			t.Errorf("Expected progress 99. Got %d", status.Progress)
+		}
+	})
+
+	t.Run("Available", func(t *testing.T) {
+		tracker.RemoveComponent(disabled)
+		if got := tracker.ReportStatus(bpv1.MultiClusterEngine{}).Progress; got != 100 {
+			t.Errorf("Expected progress 100. Got %d", got)
+		}
+	})
+}
+
+func Test_Metrics(t *testing.T) {
+	missing := DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-missing", Namespace: "mock-ns"}}
+	manager := StatusTracker{Client: fake.NewClientBuilder().Build()}
+	tracker := manager.Instance("uid-a")
+	tracker.AddComponent(MockStatus{NamespacedName: types.NamespacedName{Name: "mock-available", Namespace: "mock-ns"}})
+	tracker.AddComponent(missing)
+	mce := bpv1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "mce-a", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+
+	other := manager.Instance("uid-b")
+	other.AddComponent(missing)
+	otherMCE := bpv1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "mce-b", CreationTimestamp: metav1.NewTime(time.Now())}}
+
+	t.Run("Component readiness", func(t *testing.T) {
+		tracker.ReportStatus(mce)
+		other.ReportStatus(otherMCE)
+		if got := testutil.ToFloat64(componentReadyGauge.WithLabelValues("mce-a", "mock-available")); got != 1 {
+			t.Errorf("Expected mock-available to be ready. Got %v", got)
+		}
+		if got := testutil.ToFloat64(componentReadyGauge.WithLabelValues("mce-a", "mock-missing")); got != 0 {
+			t.Errorf("Expected mock-missing not to be ready. Got %v", got)
+		}
+		if got := testutil.ToFloat64(progressGauge.WithLabelValues("mce-a")); got != 50 {
+			t.Errorf("Expected half of the components of mce-a to be available. Got %v", got)
+		}
+		if got := testutil.ToFloat64(progressGauge.WithLabelValues("mce-b")); got != 0 {
+			t.Errorf("Expected mce-b not to overwrite the progress of mce-a. Got %v", got)
+		}
+		if installDurationGauge.DeleteLabelValues("mce-a") {
+			t.Errorf("Expected no install duration before the install completes")
+		}
+	})
+
+	t.Run("Install duration", func(t *testing.T) {
+		tracker.RemoveComponent(missing)
+		tracker.ReportStatus(mce)
+		if got := testutil.ToFloat64(installDurationGauge.WithLabelValues("mce-a")); got < time.Hour.Seconds() {
+			t.Errorf("Expected an install duration of at least an hour. Got %v", got)
+		}
+		if componentReadyGauge.DeleteLabelValues("mce-a", "mock-missing") {
+			t.Errorf("Expected the untracked component to be dropped")
+		}
+		if !componentReadyGauge.DeleteLabelValues("mce-b", "mock-missing") {
+			t.Errorf("Expected the components of mce-b to be kept")
+		}
+	})
+
+	t.Run("Removed instance", func(t *testing.T) {
+		manager.RemoveInstance("uid-a")
+		if progressGauge.DeleteLabelValues("mce-a") {
+			t.Errorf("Expected the progress of the removed instance to be dropped")
+		}
+		if installDurationGauge.DeleteLabelValues("mce-a") {
+			t.Errorf("Expected the install duration of the removed instance to be dropped")
+		}
+		if componentReadyGauge.DeleteLabelValues("mce-a", "mock-available") {
+			t.Errorf("Expected the components of the removed instance to be dropped")
+		}
+		if !progressGauge.DeleteLabelValues("mce-b") {
+			t.Errorf("Expected the progress of the other instance to be kept")
+		}
+	})
+}
+
+func Test_ReadyTimeout(t *testing.T) {
+	missing := DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-missing", Namespace: "mock-ns"}}
+	key := "Deployment/mock-ns/mock-missing"
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build(), ReadyTimeout: 5 * time.Minute}
+	tracker.AddComponent(missing)
+
+	t.Run("Slow component within the timeout", func(t *testing.T) {
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		if status.Components[0].Reason == InstallTimeoutReason {
+			t.Errorf("Expected component not to be timed out yet")
+		}
+		if _, ok := tracker.notReadySince[key]; !ok {
+			t.Fatalf("Expected the time the component became unavailable to be recorded")
+		}
+	})
+
+	t.Run("Stuck component past the timeout", func(t *testing.T) {
+		tracker.notReadySince[key] = time.Now().Add(-6 * time.Minute)
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		if status.Components[0].Reason != InstallTimeoutReason {
+			t.Errorf("Expected component reason %s. Got %s", InstallTimeoutReason, status.Components[0].Reason)
+		}
+		c := getCondition(status.Conditions, bpv1.MultiClusterEngineDegraded)
+		if c == nil || c.Status != metav1.ConditionTrue {
+			t.Errorf("Expected a timed out component to degrade the multiclusterengine")
+		}
+	})
+
+	t.Run("Timeout disabled", func(t *testing.T) {
+		tracker.ReadyTimeout = 0
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		if status.Components[0].Reason == InstallTimeoutReason {
+			t.Errorf("Expected no timeout when the ready timeout is disabled")
+		}
+	})
+}
+
+func Test_PrerequisiteFailedPhase(t *testing.T) {
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build()}
+	tracker.AddComponent(MockStatus{NamespacedName: types.NamespacedName{Name: "mock-available", Namespace: "mock-ns"}})
+
+	tracker.AddCondition(NewCondition(bpv1.MultiClusterEnginePrerequisiteFailed, metav1.ConditionTrue, PrerequisitesNotMetReason, "OpenShift 4.7.0 is not supported"))
+	if phase := tracker.ReportStatus(bpv1.MultiClusterEngine{}).Phase; phase != bpv1.MultiClusterEnginePhaseFailed {
+		t.Errorf("Expected phase %s when prerequisites are not met. Got %s", bpv1.MultiClusterEnginePhaseFailed, phase)
+	}
+
+	upgrading := bpv1.MultiClusterEngine{Status: bpv1.MultiClusterEngineStatus{CurrentVersion: "1.0.0"}}
+	if phase := tracker.ReportStatus(upgrading).Phase; phase != bpv1.MultiClusterEnginePhaseFailed {
+		t.Errorf("Expected phase %s when prerequisites are not met for an upgrade. Got %s", bpv1.MultiClusterEnginePhaseFailed, phase)
+	}
+
+	installed := bpv1.MultiClusterEngine{Status: bpv1.MultiClusterEngineStatus{CurrentVersion: version.Get().GitVersion}}
+	if phase := tracker.ReportStatus(installed).Phase; phase != bpv1.MultiClusterEnginePhaseAvailable {
+		t.Errorf("Expected phase %s when prerequisites are no longer met after install. Got %s", bpv1.MultiClusterEnginePhaseAvailable, phase)
+	}
+
+	tracker.AddCondition(NewCondition(bpv1.MultiClusterEnginePrerequisiteFailed, metav1.ConditionFalse, PrerequisitesMetReason, ""))
+	if phase := tracker.ReportStatus(bpv1.MultiClusterEngine{}).Phase; phase != bpv1.MultiClusterEnginePhaseAvailable {
+		t.Errorf("Expected phase %s once prerequisites are met. Got %s", bpv1.MultiClusterEnginePhaseAvailable, phase)
+	}
+}
+
+func Test_ImagePullError(t *testing.T) {
+	labels := map[string]string{"app": "mock"}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-deploy", Namespace: "mock-ns"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		Status: appsv1.DeploymentStatus{
+			UnavailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-pod", Namespace: "mock-ns", Labels: labels},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "mock",
+					Image: "mirror.example.com/mock:1.0",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image",
+					}},
+				},
+			},
+		},
+	}
+	ds := DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-deploy", Namespace: "mock-ns"}}
+
+	t.Run("Pod stuck pulling its image", func(t *testing.T) {
+		cc := ds.Status(fake.NewClientBuilder().WithObjects(deploy, pod).Build())
+		if cc.Reason != ImagePullErrorReason {
+			t.Fatalf("Expected reason %s. Got %s", ImagePullErrorReason, cc.Reason)
+		}
+		if cc.Message != "Failed to pull image mirror.example.com/mock:1.0: Back-off pulling image" {
+			t.Errorf("Expected the message to name the image. Got %s", cc.Message)
+		}
+	})
+
+	t.Run("Unavailable for another reason", func(t *testing.T) {
+		cc := ds.Status(fake.NewClientBuilder().WithObjects(deploy).Build())
+		if cc.Reason == ImagePullErrorReason {
+			t.Errorf("Expected no image pull error without a failing pod")
+		}
+	})
+}
+
+func Test_CrashLoopBackOff(t *testing.T) {
+	labels := map[string]string{"app": "mock"}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-deploy", Namespace: "mock-ns"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		Status: appsv1.DeploymentStatus{
+			UnavailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-pod", Namespace: "mock-ns", Labels: labels},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "mock",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "CrashLoopBackOff",
+						Message: "back-off 5m0s restarting failed container",
+					}},
+				},
+			},
+		},
+	}
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().WithObjects(deploy, pod).Build()}
+	tracker.AddComponent(DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-deploy", Namespace: "mock-ns"}})
+	status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+
+	if status.Components[0].Reason != CrashLoopBackOffReason {
+		t.Fatalf("Expected reason %s. Got %s", CrashLoopBackOffReason, status.Components[0].Reason)
+	}
+	available := getCondition(status.Conditions, bpv1.MultiClusterEngineAvailable)
+	if available == nil || available.Status != metav1.ConditionFalse {
+		t.Fatalf("Expected the available condition to be false")
+	}
+	if available.Message != "The following components are not available: mock-deploy (CrashLoopBackOff)" {
+		t.Errorf("Expected the message to name the crash looping component. Got %s", available.Message)
+	}
+	if degraded := getCondition(status.Conditions, bpv1.MultiClusterEngineDegraded); degraded == nil || degraded.Status != metav1.ConditionTrue {
+		t.Errorf("Expected a crash looping component to degrade the multiclusterengine")
+	}
+}
+
+func Test_RolloutInProgress(t *testing.T) {
+	replicas := int32(2)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mock-deploy", Namespace: "mock-ns", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           3,
+			UpdatedReplicas:    1,
+			ReadyReplicas:      2,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+			},
+		},
+	}
+
+	t.Run("Old replicas remain", func(t *testing.T) {
+		cc := mapDeployment(deploy)
+		if cc.Available {
+			t.Errorf("Deployment rolling out a new spec should not be available")
+		}
+		if cc.Reason != "ReplicaSetUpdated" {
+			t.Errorf("Expected the progressing reason. Got %s", cc.Reason)
+		}
+	})
+
+	t.Run("Rollout complete", func(t *testing.T) {
+		done := deploy.DeepCopy()
+		done.Status.Replicas = 2
+		done.Status.UpdatedReplicas = 2
+		if cc := mapDeployment(done); !cc.Available {
+			t.Errorf("Deployment with every replica updated and ready should be available")
+		}
+	})
+}
+
+func Test_ProgressingCondition(t *testing.T) {
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build()}
+	missing := DeploymentStatus{NamespacedName: types.NamespacedName{Name: "mock-missing", Namespace: "mock-ns"}}
+	tracker.AddComponent(MockStatus{NamespacedName: types.NamespacedName{Name: "mock-available", Namespace: "mock-ns"}})
+	tracker.AddComponent(missing)
+	tracker.AddCondition(NewCondition(bpv1.MultiClusterEngineProgressing, metav1.ConditionTrue, DeploySuccessReason, "All components deployed"))
+
+	t.Run("Progressing while components roll out", func(t *testing.T) {
+		c := getCondition(tracker.ReportStatus(bpv1.MultiClusterEngine{}).Conditions, bpv1.MultiClusterEngineProgressing)
+		if c == nil || c.Status != metav1.ConditionTrue || c.Reason != DeploySuccessReason {
+			t.Errorf("Expected Progressing to be true while a component is unavailable. Got %v", c)
+		}
+	})
+
+	t.Run("Not progressing once the rollout is complete", func(t *testing.T) {
+		tracker.RemoveComponent(missing)
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		c := getCondition(status.Conditions, bpv1.MultiClusterEngineProgressing)
+		if c == nil || c.Status != metav1.ConditionFalse || c.Reason != RolloutCompleteReason {
+			t.Fatalf("Expected Progressing to be false once all components are available. Got %v", c)
+		}
+		if status.Phase != bpv1.MultiClusterEnginePhaseAvailable {
+			t.Errorf("Expected phase %s. Got %s", bpv1.MultiClusterEnginePhaseAvailable, status.Phase)
+		}
+
+		previous := metav1.NewTime(c.LastTransitionTime.Add(-time.Hour))
+		mce := bpv1.MultiClusterEngine{Status: bpv1.MultiClusterEngineStatus{Conditions: []bpv1.MultiClusterEngineCondition{
+			{Type: bpv1.MultiClusterEngineProgressing, Status: metav1.ConditionFalse, Reason: RolloutCompleteReason, LastTransitionTime: previous},
+		}}}
+		c = getCondition(tracker.ReportStatus(mce).Conditions, bpv1.MultiClusterEngineProgressing)
+		if !c.LastTransitionTime.Equal(&previous) {
+			t.Errorf("Expected the transition time to be kept from the previous status")
+		}
+	})
+}
+
+func Test_CRDStatus(t *testing.T) {
+	crd := &apixv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "mocks.example.com"},
+		Status: apixv1.CustomResourceDefinitionStatus{
+			Conditions: []apixv1.CustomResourceDefinitionCondition{
+				{Type: apixv1.Established, Status: apixv1.ConditionTrue, Reason: "InitialNamesAccepted"},
+			},
+			StoredVersions: []string{"v1alpha1", "v1"},
+		},
+	}
+
+	t.Run("Established CRD", func(t *testing.T) {
+		cc := mapCRD(crd, []string{"v1alpha1", "v1"})
+		if !cc.Available || cc.Type != string(apixv1.Established) {
+			t.Errorf("Expected an established CRD to be available. Got %+v", cc)
+		}
+	})
+
+	t.Run("Stored version no longer served", func(t *testing.T) {
+		cc := mapCRD(crd, []string{"v1"})
+		if cc.Available || cc.Reason != StoredVersionConflictReason {
+			t.Fatalf("Expected reason %s. Got %+v", StoredVersionConflictReason, cc)
+		}
+		if cc.Message != "Resources are stored in version v1alpha1, which the new definition no longer serves" {
+			t.Errorf("Expected the message to name the stored version. Got %s", cc.Message)
+		}
+	})
+
+	t.Run("Not yet established", func(t *testing.T) {
+		pending := crd.DeepCopy()
+		pending.Status.Conditions = nil
+		if cc := mapCRD(pending, []string{"v1alpha1", "v1"}); cc.Available {
+			t.Errorf("Expected a CRD without conditions to be unavailable")
+		}
+	})
+}
+
+func Test_ComponentRetry(t *testing.T) {
+	failing := MockStatus{NamespacedName: types.NamespacedName{Name: "mock-failing", Namespace: "mock-ns"}}
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().Build()}
+	tracker.AddComponent(failing)
+
+	if wait := tracker.RetryAfter(failing); wait != 0 {
+		t.Errorf("Expected a component that never failed to be applied right away. Got a wait of %s", wait)
+	}
+
+	t.Run("Backoff doubles with every failure", func(t *testing.T) {
+		for i, want := range []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second} {
+			if got := tracker.ApplyFailed(failing, errors.New("webhook timed out"), 5*time.Second, 30*time.Second); got != want {
+				t.Errorf("Failure %d: expected a backoff of %s. Got %s", i+1, want, got)
+			}
+		}
+		if wait := tracker.RetryAfter(failing); wait <= 0 || wait > 30*time.Second {
+			t.Errorf("Expected the component to wait up to 30s before it is applied again. Got %s", wait)
+		}
+	})
+
+	t.Run("Retry state in the component status", func(t *testing.T) {
+		status := tracker.ReportStatus(bpv1.MultiClusterEngine{})
+		cc := status.Components[0]
+		if cc.Retries != 5 || cc.LastError != "webhook timed out" || cc.NextRetryTime == nil {
+			t.Errorf("Expected 5 retries, the last error and the next retry time in the status. Got %d, %q, %v", cc.Retries, cc.LastError, cc.NextRetryTime)
+		}
+	})
+
+	t.Run("Success clears the retry state", func(t *testing.T) {
+		tracker.ApplySucceeded(failing)
+		if wait := tracker.RetryAfter(failing); wait != 0 {
+			t.Errorf("Expected the component to be applied right away after a success. Got a wait of %s", wait)
+		}
+		cc := tracker.ReportStatus(bpv1.MultiClusterEngine{}).Components[0]
+		if cc.Retries != 0 || cc.LastError != "" || cc.NextRetryTime != nil {
+			t.Errorf("Expected no retry state in the status after a success")
+		}
+	})
+}
+
+func Test_ReadyzCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := bpv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mce := bpv1.MultiClusterEngine{ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "uid-a"}}
+
+	t.Run("No MultiClusterEngine", func(t *testing.T) {
+		tracker := StatusTracker{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+		if err := tracker.ReadyzCheck(nil); err != nil {
+			t.Errorf("StatusTracker.ReadyzCheck() = %v, want ready without a MultiClusterEngine", err)
+		}
+	})
+
+	tracker := StatusTracker{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(mce.DeepCopy()).Build()}
+	t.Run("Not reconciled", func(t *testing.T) {
+		if err := tracker.ReadyzCheck(nil); err == nil {
+			t.Errorf("StatusTracker.ReadyzCheck() reported ready before the MultiClusterEngine was reconciled")
+		}
+	})
+
+	instance := tracker.Instance(string(mce.UID))
+	record := func(phase bpv1.PhaseType, err error) {
+		reconciled := mce
+		reconciled.Status.Phase = phase
+		instance.RecordReconcile(reconciled, err)
+	}
+
+	t.Run("Reconcile failed", func(t *testing.T) {
+		record(bpv1.MultiClusterEnginePhaseAvailable, errors.New("apply failed"))
+		if err := tracker.ReadyzCheck(nil); err == nil {
+			t.Errorf("StatusTracker.ReadyzCheck() reported ready before a reconcile succeeded")
+		}
+	})
+
+	t.Run("Components not available", func(t *testing.T) {
+		record(bpv1.MultiClusterEnginePhaseProgressing, nil)
+		if err := tracker.ReadyzCheck(nil); err == nil {
+			t.Errorf("StatusTracker.ReadyzCheck() reported ready with components unavailable")
+		}
+	})
+
+	t.Run("Reconciled and available", func(t *testing.T) {
+		record(bpv1.MultiClusterEnginePhaseAvailable, nil)
+		if err := tracker.ReadyzCheck(nil); err != nil {
+			t.Errorf("StatusTracker.ReadyzCheck() = %v, want ready", err)
+		}
+	})
+
+	t.Run("Later reconcile failed", func(t *testing.T) {
+		record(bpv1.MultiClusterEnginePhaseAvailable, errors.New("apply failed"))
+		if err := tracker.ReadyzCheck(nil); err != nil {
+			t.Errorf("StatusTracker.ReadyzCheck() = %v, want ready while the components stay available", err)
+		}
+	})
+}
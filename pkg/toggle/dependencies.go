@@ -0,0 +1,152 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package toggle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+	"github.com/stolostron/backplane-operator/pkg/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ComponentDependencies lists, for each component, the components whose CRDs or services it needs before it
+// can be installed
+var ComponentDependencies = map[string][]string{
+	bpv1.AssistedService:       {bpv1.Hive},
+	bpv1.ClusterBackup:         {bpv1.ClusterManager},
+	bpv1.ClusterLifecycle:      {bpv1.ClusterManager, bpv1.Hive},
+	bpv1.ClusterProxyAddon:     {bpv1.ClusterManager},
+	bpv1.Discovery:             {bpv1.ClusterManager},
+	bpv1.HyperShift:            {bpv1.ClusterManager},
+	bpv1.ManagedServiceAccount: {bpv1.ClusterManager},
+	bpv1.ServerFoundation:      {bpv1.ClusterManager},
+}
+
+// InstallOrder returns the components sorted so that each comes after all of its dependencies. Components
+// with no ordering between them are sorted by name so the order is stable. An error is returned if the
+// dependencies contain a cycle.
+func InstallOrder(components []string, dependencies map[string][]string) ([]string, error) {
+	inDegree := map[string]int{}
+	dependents := map[string][]string{}
+	for _, c := range components {
+		inDegree[c] = 0
+	}
+	for _, c := range components {
+		for _, d := range dependencies[c] {
+			if _, ok := inDegree[d]; !ok {
+				continue
+			}
+			inDegree[c]++
+			dependents[d] = append(dependents[d], c)
+		}
+	}
+
+	ready := []string{}
+	for c, n := range inDegree {
+		if n == 0 {
+			ready = append(ready, c)
+		}
+	}
+
+	order := []string{}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		c := ready[0]
+		ready = ready[1:]
+		order = append(order, c)
+		for _, d := range dependents[c] {
+			inDegree[d]--
+			if inDegree[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	if len(order) != len(inDegree) {
+		cycle := []string{}
+		for c, n := range inDegree {
+			if n > 0 {
+				cycle = append(cycle, c)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("dependency cycle detected among components: %s", strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
+
+// InstallLevels groups the components in levels so that each component only depends on components of earlier
+// levels. The components of a level don't depend on each other, so they can be installed in parallel. An error is
+// returned if the dependencies contain a cycle.
+func InstallLevels(components []string, dependencies map[string][]string) ([][]string, error) {
+	order, err := InstallOrder(components, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	level := map[string]int{}
+	levels := [][]string{}
+	for _, c := range order {
+		l := 0
+		for _, d := range dependencies[c] {
+			if dl, ok := level[d]; ok && dl+1 > l {
+				l = dl + 1
+			}
+		}
+		level[c] = l
+		if l == len(levels) {
+			levels = append(levels, []string{})
+		}
+		levels[l] = append(levels[l], c)
+	}
+	for _, l := range levels {
+		sort.Strings(l)
+	}
+	return levels, nil
+}
+
+func WaitingStatus(namespacedName types.NamespacedName, dependency string) status.StatusReporter {
+	return WaitingOnDependencyStatus{
+		NamespacedName: namespacedName,
+		dependency:     dependency,
+	}
+}
+
+// WaitingOnDependencyStatus fulfills the StatusReporter interface for a component that is not installed
+// because a component it depends on is not yet available
+type WaitingOnDependencyStatus struct {
+	types.NamespacedName
+	dependency string
+}
+
+func (ws WaitingOnDependencyStatus) GetName() string {
+	return ws.Name
+}
+
+func (ws WaitingOnDependencyStatus) GetNamespace() string {
+	return ws.Namespace
+}
+
+func (ws WaitingOnDependencyStatus) GetKind() string {
+	return "Component"
+}
+
+// Converts this component's status to a backplane component status
+func (ws WaitingOnDependencyStatus) Status(k8sClient client.Client) bpv1.ComponentCondition {
+	return bpv1.ComponentCondition{
+		Name:               ws.GetName(),
+		Kind:               ws.GetKind(),
+		Type:               "Available",
+		Status:             metav1.ConditionFalse,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             status.WaitingForResourceReason,
+		Message:            fmt.Sprintf("Waiting on dependency %s", ws.dependency),
+		Available:          false,
+	}
+}
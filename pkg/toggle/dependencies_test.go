@@ -0,0 +1,131 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package toggle
+
+import (
+	"reflect"
+	"testing"
+
+	bpv1 "github.com/stolostron/backplane-operator/api/v1"
+)
+
+func TestInstallOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		components   []string
+		dependencies map[string][]string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:         "No dependencies",
+			components:   []string{"c", "a", "b"},
+			dependencies: map[string][]string{},
+			want:         []string{"a", "b", "c"},
+		},
+		{
+			name:         "Chain",
+			components:   []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"c"}},
+			want:         []string{"c", "b", "a"},
+		},
+		{
+			name:         "Dependency not managed",
+			components:   []string{"a"},
+			dependencies: map[string][]string{"a": {"external"}},
+			want:         []string{"a"},
+		},
+		{
+			name:         "Cycle",
+			components:   []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"a"}},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InstallOrder(tt.components, tt.dependencies)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstallOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InstallOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Component dependencies", func(t *testing.T) {
+		components := []string{}
+		for c := range ComponentDependencies {
+			components = append(components, c)
+		}
+		components = append(components, bpv1.ClusterManager, bpv1.Hive, bpv1.ConsoleMCE)
+		order, err := InstallOrder(components, ComponentDependencies)
+		if err != nil {
+			t.Fatalf("ComponentDependencies contains a cycle: %v", err)
+		}
+		position := map[string]int{}
+		for i, c := range order {
+			position[c] = i
+		}
+		for c, deps := range ComponentDependencies {
+			for _, d := range deps {
+				if position[d] > position[c] {
+					t.Errorf("%s installed before its dependency %s", c, d)
+				}
+			}
+		}
+	})
+}
+
+func TestInstallLevels(t *testing.T) {
+	tests := []struct {
+		name         string
+		components   []string
+		dependencies map[string][]string
+		want         [][]string
+		wantErr      bool
+	}{
+		{
+			name:         "No dependencies",
+			components:   []string{"c", "a", "b"},
+			dependencies: map[string][]string{},
+			want:         [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:         "Chain",
+			components:   []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"c"}},
+			want:         [][]string{{"c"}, {"b"}, {"a"}},
+		},
+		{
+			name:         "Shared dependency",
+			components:   []string{"a", "b", "c", "d"},
+			dependencies: map[string][]string{"a": {"c"}, "b": {"c"}, "d": {"a", "c"}},
+			want:         [][]string{{"c"}, {"a", "b"}, {"d"}},
+		},
+		{
+			name:         "Dependency not managed",
+			components:   []string{"a", "b"},
+			dependencies: map[string][]string{"a": {"external"}},
+			want:         [][]string{{"a", "b"}},
+		},
+		{
+			name:         "Cycle",
+			components:   []string{"a", "b"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"a"}},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InstallLevels(tt.components, tt.dependencies)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstallLevels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InstallLevels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
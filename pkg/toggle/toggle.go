@@ -21,6 +21,8 @@ const (
 	ManagedServiceAccountChartDir = "pkg/templates/charts/toggle/managed-serviceaccount"
 	ConsoleMCEChartsDir           = "pkg/templates/charts/toggle/console-mce"
 	ManagedServiceAccountCRDPath  = "pkg/templates/managed-serviceaccount/crds"
+	ClusterProxyAddonChartDir     = "pkg/templates/charts/toggle/cluster-proxy-addon"
+	ClusterProxyAddonCRDPath      = "pkg/templates/cluster-proxy-addon/crds"
 
 	DiscoveryChartDir        = "pkg/templates/charts/toggle/discovery-operator"
 	HiveChartDir             = "pkg/templates/charts/toggle/hive-operator"
@@ -29,6 +31,7 @@ const (
 	ClusterManagerChartDir   = "pkg/templates/charts/toggle/cluster-manager"
 	ServerFoundationChartDir = "pkg/templates/charts/toggle/server-foundation"
 	HyperShiftChartDir       = "pkg/templates/charts/toggle/hypershift"
+	ClusterBackupChartDir    = "pkg/templates/charts/toggle/cluster-backup"
 )
 
 func EnabledStatus(namespacedName types.NamespacedName) status.StatusReporter {
@@ -70,6 +73,11 @@ func (ts ToggledOffStatus) GetKind() string {
 	return "Component"
 }
 
+// Disabled excludes the component from the installation progress
+func (ts ToggledOffStatus) Disabled() bool {
+	return true
+}
+
 // Converts this component's status to a backplane component status
 func (ts ToggledOffStatus) Status(k8sClient client.Client) bpv1.ComponentCondition {
 	present := []*unstructured.Unstructured{}
@@ -17,6 +17,17 @@ var (
 	AnnotationImageRepo = "imageRepository"
 	// AnnotationImageOverridesCM identifies a configmap name containing an image override mapping
 	AnnotationImageOverridesCM = "imageOverridesCM"
+	// AnnotationForceDowngrade allows the operator to reconcile a multiclusterengine installed by a newer version
+	AnnotationForceDowngrade = "forceDowngrade"
+	// AnnotationComponentPaused sits in the annotations of a component deployment scaled to zero because the
+	// component is paused
+	AnnotationComponentPaused = "multicluster.openshift.io/component-paused"
+	// AnnotationAdopt sits in the annotations of an existing resource owned by another controller to let the
+	// operator take it over
+	AnnotationAdopt = "multicluster.openshift.io/adopt"
+	// AnnotationManifestHash sits in the annotations of every resource the operator applies and holds a hash of
+	// the manifest it was applied from
+	AnnotationManifestHash = "multicluster.openshift.io/manifest-hash"
 )
 
 // IsPaused returns true if the multiclusterengine instance is labeled as paused, and false otherwise
@@ -33,6 +44,11 @@ func IsPaused(instance *backplanev1.MultiClusterEngine) bool {
 	return false
 }
 
+// IsDowngradeForced returns true if the multiclusterengine instance is annotated to allow a downgrade
+func IsDowngradeForced(instance *backplanev1.MultiClusterEngine) bool {
+	return strings.EqualFold(getAnnotation(instance, AnnotationForceDowngrade), "true")
+}
+
 // AnnotationsMatch returns true if all annotation values used by the operator match
 func AnnotationsMatch(old, new map[string]string) bool {
 	return old[AnnotationMCEPause] == new[AnnotationMCEPause] &&
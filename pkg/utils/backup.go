@@ -0,0 +1,44 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupLabel sits on the multiclusterengine and every resource the operator manages so they can be selected
+	// for a Velero backup
+	BackupLabel = "cluster.open-cluster-management.io/backup"
+	// BackupLabelValue is the value of the backup label on resources managed by the operator
+	BackupLabelValue = "multicluster-engine"
+	// VeleroRestoreLabel is added by Velero to every resource it restores, with the name of the restore
+	VeleroRestoreLabel = "velero.io/restore-name"
+)
+
+// IsRestored returns true if the multiclusterengine was restored from a Velero backup
+func IsRestored(instance *backplanev1.MultiClusterEngine) bool {
+	return instance.GetLabels()[VeleroRestoreLabel] != ""
+}
+
+// AddBackupLabel adds the backup label to the multiclusterengine. Returns true if the label was added.
+func AddBackupLabel(instance *backplanev1.MultiClusterEngine) bool {
+	if instance.GetLabels()[BackupLabel] == BackupLabelValue {
+		return false
+	}
+	labels := make(map[string]string)
+	for key, value := range instance.GetLabels() {
+		labels[key] = value
+	}
+	labels[BackupLabel] = BackupLabelValue
+	instance.SetLabels(labels)
+	return true
+}
+
+// isStaleOwner returns true if owner refers to an earlier instance of a restored multiclusterengine. The restored
+// instance has the same name but a new UID, so resources it owned before the backup must be adopted.
+func isStaleOwner(owner *metav1.OwnerReference, instance *backplanev1.MultiClusterEngine) bool {
+	return IsRestored(instance) && owner.Kind == "MultiClusterEngine" && owner.Name == instance.GetName() &&
+		owner.UID != instance.GetUID()
+}
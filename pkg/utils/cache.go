@@ -0,0 +1,43 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LabelFilteredKinds are the kinds only cached when they carry the backplaneconfig.name label. They are among the
+// most numerous kinds on a cluster, while most of those the operator reads are ones it applies. The few without
+// the label that it reads, such as its configuration ConfigMaps, are read from the API server instead. Secrets
+// are not filtered, as they are never cached.
+func LabelFilteredKinds() []client.Object {
+	return []client.Object{&appsv1.Deployment{}, &corev1.ConfigMap{}}
+}
+
+// LabelFilteredCache wraps newCache so that the kinds in LabelFilteredKinds are only cached when they carry the
+// backplaneconfig.name label. A nil newCache builds the default cache.
+func LabelFilteredCache(newCache cache.NewCacheFunc) (cache.NewCacheFunc, error) {
+	if newCache == nil {
+		newCache = cache.New
+	}
+	managed, err := labels.NewRequirement("backplaneconfig.name", selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	selector := cache.ObjectSelector{Label: labels.NewSelector().Add(*managed)}
+
+	selectors := cache.SelectorsByObject{}
+	for _, obj := range LabelFilteredKinds() {
+		selectors[obj] = selector
+	}
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		opts.SelectorsByObject = selectors
+		return newCache(config, opts)
+	}, nil
+}
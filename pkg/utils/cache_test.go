@@ -0,0 +1,47 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+func TestLabelFilteredCache(t *testing.T) {
+	var got cache.Options
+	newCache, err := LabelFilteredCache(func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		got = opts
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("LabelFilteredCache() error = %v", err)
+	}
+	if _, err := newCache(&rest.Config{}, cache.Options{Namespace: "watched"}); err != nil {
+		t.Fatalf("newCache() error = %v", err)
+	}
+
+	if got.Namespace != "watched" {
+		t.Errorf("Namespace = %q, want the options passed through", got.Namespace)
+	}
+	if len(got.SelectorsByObject) != len(LabelFilteredKinds()) {
+		t.Fatalf("SelectorsByObject has %d kinds, want %d", len(got.SelectorsByObject), len(LabelFilteredKinds()))
+	}
+	for obj, selector := range got.SelectorsByObject {
+		switch obj.(type) {
+		case *appsv1.Deployment, *corev1.ConfigMap:
+		default:
+			t.Errorf("unexpected selector for %T", obj)
+		}
+		if !selector.Label.Matches(labels.Set{"backplaneconfig.name": "multiclusterengine"}) {
+			t.Errorf("selector for %T does not match a labeled resource", obj)
+		}
+		if selector.Label.Matches(labels.Set{"app": "other"}) {
+			t.Errorf("selector for %T matches an unlabeled resource", obj)
+		}
+	}
+}
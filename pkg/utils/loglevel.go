@@ -0,0 +1,31 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// LogLevelConfigMap is the name of the ConfigMap in the operator namespace used to change the log level at runtime.
+	// The spec.logLevel of any MultiClusterEngine overrides it.
+	LogLevelConfigMap = "backplane-operator-log-level"
+	// LogLevelKey is the ConfigMap key holding the log level
+	LogLevelKey = "logLevel"
+)
+
+// ParseLogLevel converts one of error, info or debug to a zap level
+func ParseLogLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "error":
+		return zapcore.ErrorLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "debug":
+		return zapcore.DebugLevel, nil
+	}
+	return zapcore.InfoLevel, fmt.Errorf("unknown log level %q. Must be one of error, info or debug", level)
+}
@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PodSecurityRestricted is the most restrictive level of the Pod Security Standards
+	PodSecurityRestricted = "restricted"
+	// AnnotationPodSecurityManaged marks a namespace whose Pod Security labels were set by the operator, so they
+	// are only removed from namespaces it labeled
+	AnnotationPodSecurityManaged = "multicluster.openshift.io/pod-security-managed"
+)
+
+// PodSecurityLabels are the Pod Security Admission labels set on the target namespace in the restricted mode
+var PodSecurityLabels = []string{
+	"pod-security.kubernetes.io/enforce",
+	"pod-security.kubernetes.io/audit",
+	"pod-security.kubernetes.io/warn",
+}
+
+// IsPodSecurityRestricted returns true if the multiclusterengine hardens the component pods to the restricted Pod
+// Security Standard
+func IsPodSecurityRestricted(m *backplanev1.MultiClusterEngine) bool {
+	return m.Spec.Overrides != nil && m.Spec.Overrides.SecurityContext != nil && m.Spec.Overrides.SecurityContext.Restricted
+}
+
+// SetPodSecurityLabels labels the namespace for the restricted Pod Security level while restricted is true, and
+// removes the labels set earlier once it is false. Returns true if the namespace was changed.
+func SetPodSecurityLabels(ns *corev1.Namespace, restricted bool) bool {
+	labels, annotations := ns.GetLabels(), ns.GetAnnotations()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	changed := false
+	if restricted {
+		for _, l := range PodSecurityLabels {
+			if labels[l] != PodSecurityRestricted {
+				labels[l] = PodSecurityRestricted
+				changed = true
+			}
+		}
+		if annotations[AnnotationPodSecurityManaged] != "true" {
+			annotations[AnnotationPodSecurityManaged] = "true"
+			changed = true
+		}
+	} else if annotations[AnnotationPodSecurityManaged] == "true" {
+		for _, l := range PodSecurityLabels {
+			if labels[l] == PodSecurityRestricted {
+				delete(labels, l)
+			}
+		}
+		delete(annotations, AnnotationPodSecurityManaged)
+		changed = true
+	}
+
+	ns.SetLabels(labels)
+	ns.SetAnnotations(annotations)
+	return changed
+}
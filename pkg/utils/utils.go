@@ -3,48 +3,28 @@
 package utils
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
 
 	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var onComponents = []string{
-	backplanev1.AssistedService,
-	backplanev1.ClusterLifecycle,
-	backplanev1.ClusterManager,
-	backplanev1.Discovery,
-	backplanev1.Hive,
-	backplanev1.ServerFoundation,
-	// backplanev1.ConsoleMCE, // determined by OCP version
-	// backplanev1.HyperShift,
-}
-
-var offComponents = []string{
-	backplanev1.ManagedServiceAccount,
-	backplanev1.HyperShift,
-}
+const (
+	// DefaultTrustedCABundle is the name of the ConfigMap used as the trusted CA bundle when none is specified
+	DefaultTrustedCABundle = "trusted-ca-bundle"
+)
 
 // SetDefaultComponents returns true if changes are made
 func SetDefaultComponents(m *backplanev1.MultiClusterEngine) bool {
-	updated := false
-	for _, c := range onComponents {
-		if !m.ComponentPresent(c) {
-			m.Enable(c)
-			updated = true
-		}
-	}
-	for _, c := range offComponents {
-		if !m.ComponentPresent(c) {
-			m.Disable(c)
-			updated = true
-		}
-	}
-	return updated
+	return m.SetDefaultComponents()
 }
 
 // AddBackplaneConfigLabels adds BackplaneConfig Labels ...
@@ -54,10 +34,28 @@ func AddBackplaneConfigLabels(u client.Object, name string) {
 		labels[key] = value
 	}
 	labels["backplaneconfig.name"] = name
+	labels[BackupLabel] = BackupLabelValue
 
 	u.SetLabels(labels)
 }
 
+// ForeignOwner returns a description of the owner of obj if obj is managed by something other than mce, or an
+// empty string if mce may manage it. A resource is foreign if its controller reference points to another owner or
+// it is labeled for a different MultiClusterEngine. Resources annotated for adoption, and resources owned by the
+// instance mce was restored from, are never foreign.
+func ForeignOwner(obj metav1.Object, mce *backplanev1.MultiClusterEngine) string {
+	if strings.EqualFold(obj.GetAnnotations()[AnnotationAdopt], "true") {
+		return ""
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil && owner.UID != mce.GetUID() && !isStaleOwner(owner, mce) {
+		return fmt.Sprintf("%s %s", owner.Kind, owner.Name)
+	}
+	if name, ok := obj.GetLabels()["backplaneconfig.name"]; ok && name != mce.GetName() {
+		return fmt.Sprintf("MultiClusterEngine %s", name)
+	}
+	return ""
+}
+
 // CoreToUnstructured converts a Core Kube resource to unstructured
 func CoreToUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
 	content, err := json.Marshal(obj)
@@ -82,6 +80,27 @@ func ProxyEnvVarsAreSet() bool {
 	return false
 }
 
+// ProxyEnvVars returns the proxy settings for the components. The operator's own proxy variables take
+// precedence, as OLM sets them when the subscription overrides the cluster-wide proxy. Otherwise the
+// settings of the cluster Proxy resource, given in clusterProxy, are used. Returns nil if no proxy is configured.
+func ProxyEnvVars(clusterProxy map[string]string) map[string]string {
+	operatorProxy := ProxyEnvVarsAreSet()
+	proxy := map[string]string{}
+	set := false
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+		if operatorProxy {
+			proxy[name] = os.Getenv(name)
+		} else {
+			proxy[name] = clusterProxy[name]
+		}
+		set = set || proxy[name] != ""
+	}
+	if !set {
+		return nil
+	}
+	return proxy
+}
+
 func DefaultReplicaCount(mce *backplanev1.MultiClusterEngine) int {
 	if mce.Spec.AvailabilityConfig == backplanev1.HABasic {
 		return 1
@@ -147,7 +166,8 @@ func GetTestImages() []string {
 		"assisted_service", "assisted_image_service", "postgresql_12", "assisted_installer_agent", "assisted_installer_controller",
 		"assisted_installer", "console_mce", "hypershift_deployment_controller", "hypershift_addon_operator", "hypershift_operator",
 		"apiserver_network_proxy", "aws_encryption_provider", "cluster_api", "cluster_api_provider_agent", "cluster_api_provider_aws",
-		"cluster_api_provider_azure", "cluster_api_provider_kubevirt"}
+		"cluster_api_provider_azure", "cluster_api_provider_kubevirt", "cluster_backup_controller",
+		"cluster_proxy"}
 }
 
 func DefaultTolerations() []corev1.Toleration {
@@ -165,6 +185,25 @@ func DefaultTolerations() []corev1.Toleration {
 	}
 }
 
+// InfraNodeLabel is the role label and taint key of OpenShift infra nodes
+const InfraNodeLabel = "node-role.kubernetes.io/infra"
+
+// InfraTolerations returns the tolerations for the taints commonly placed on infra nodes
+func InfraTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Effect:   corev1.TaintEffectNoSchedule,
+			Key:      InfraNodeLabel,
+			Operator: corev1.TolerationOpExists,
+		},
+		{
+			Effect:   corev1.TaintEffectNoExecute,
+			Key:      InfraNodeLabel,
+			Operator: corev1.TolerationOpExists,
+		},
+	}
+}
+
 func Contains(s []string, str string) bool {
 	for _, v := range s {
 		if v == str {
@@ -191,3 +230,29 @@ func OperatorNamespace() string {
 	}
 	return deploymentNamespace
 }
+
+// HashData returns a sha256 hash of the given ConfigMap or Secret data
+func HashData(data map[string]string) (string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// ParseWatchNamespaces splits a comma-separated list of namespaces. An empty list means all namespaces. Otherwise
+// the operator's own namespace is added, since the operator always reads its ConfigMaps and secrets there.
+func ParseWatchNamespaces(namespaces string) []string {
+	watched := []string{}
+	seen := map[string]bool{}
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" && !seen[ns] {
+			seen[ns] = true
+			watched = append(watched, ns)
+		}
+	}
+	if len(watched) > 0 && !seen[OperatorNamespace()] {
+		watched = append(watched, OperatorNamespace())
+	}
+	return watched
+}
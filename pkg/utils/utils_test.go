@@ -7,6 +7,11 @@ import (
 	"testing"
 
 	backplanev1 "github.com/stolostron/backplane-operator/api/v1"
+	"go.uber.org/zap/zapcore"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func Test_deduplicate(t *testing.T) {
@@ -47,3 +52,205 @@ func Test_deduplicate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{name: "Error level", level: "error", want: zapcore.ErrorLevel},
+		{name: "Info level", level: "info", want: zapcore.InfoLevel},
+		{name: "Debug level", level: " Debug ", want: zapcore.DebugLevel},
+		{name: "Unknown level", level: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLogLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLogLevel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseLogLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces string
+		want       []string
+	}{
+		{name: "All namespaces", namespaces: "", want: []string{}},
+		{name: "Operator namespace", namespaces: "multicluster-engine", want: []string{"multicluster-engine"}},
+		{name: "Namespace list", namespaces: "multicluster-engine, hive,,", want: []string{"multicluster-engine", "hive"}},
+		{name: "Operator namespace added", namespaces: "hive", want: []string{"hive", "multicluster-engine"}},
+		{name: "Duplicates dropped", namespaces: "hive,multicluster-engine,hive", want: []string{"hive", "multicluster-engine"}},
+	}
+	t.Setenv("POD_NAMESPACE", "multicluster-engine")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseWatchNamespaces(tt.namespaces); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWatchNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForeignOwner(t *testing.T) {
+	mce := &backplanev1.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "mce-uid"},
+	}
+	isController := true
+	ownedBy := func(kind, name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "v1", Kind: kind, Name: name, UID: uid, Controller: &isController}}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		want       string
+	}{
+		{
+			name:       "Unowned deployment",
+			deployment: &appsv1.Deployment{},
+			want:       "",
+		},
+		{
+			name: "Deployment owned by the MultiClusterEngine",
+			deployment: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: ownedBy("MultiClusterEngine", "multiclusterengine", "mce-uid"),
+				Labels:          map[string]string{"backplaneconfig.name": "multiclusterengine"},
+			}},
+			want: "",
+		},
+		{
+			name: "Deployment owned by another controller",
+			deployment: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: ownedBy("ClusterServiceVersion", "other-operator", "other-uid"),
+			}},
+			want: "ClusterServiceVersion other-operator",
+		},
+		{
+			name: "Deployment labeled for another MultiClusterEngine",
+			deployment: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"backplaneconfig.name": "other"},
+			}},
+			want: "MultiClusterEngine other",
+		},
+		{
+			name: "Deployment annotated for adoption",
+			deployment: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: ownedBy("ClusterServiceVersion", "other-operator", "other-uid"),
+				Annotations:     map[string]string{AnnotationAdopt: "true"},
+			}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForeignOwner(tt.deployment, mce); got != tt.want {
+				t.Errorf("ForeignOwner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForeignOwnerRestored(t *testing.T) {
+	isController := true
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: "multicluster.openshift.io/v1",
+			Kind:       "MultiClusterEngine",
+			Name:       "multiclusterengine",
+			UID:        "backed-up-uid",
+			Controller: &isController,
+		}},
+	}}
+	mce := &backplanev1.MultiClusterEngine{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiclusterengine", UID: "mce-uid"},
+	}
+
+	if got := ForeignOwner(deployment, mce); got != "MultiClusterEngine multiclusterengine" {
+		t.Errorf("ForeignOwner() = %q, want the earlier instance to be reported", got)
+	}
+
+	mce.SetLabels(map[string]string{VeleroRestoreLabel: "hub-restore"})
+	if !IsRestored(mce) {
+		t.Fatalf("IsRestored() = false, want true")
+	}
+	if got := ForeignOwner(deployment, mce); got != "" {
+		t.Errorf("ForeignOwner() = %q, want resources of the backed up instance to be adopted", got)
+	}
+}
+
+func TestProxyEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		cluster map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "No proxy configured",
+			want: nil,
+		},
+		{
+			name:    "Cluster proxy",
+			cluster: map[string]string{"HTTP_PROXY": "http://proxy:3128", "NO_PROXY": ".cluster.local"},
+			want:    map[string]string{"HTTP_PROXY": "http://proxy:3128", "HTTPS_PROXY": "", "NO_PROXY": ".cluster.local"},
+		},
+		{
+			name:    "Operator proxy overrides cluster proxy",
+			env:     map[string]string{"HTTPS_PROXY": "https://override:3128"},
+			cluster: map[string]string{"HTTP_PROXY": "http://proxy:3128"},
+			want:    map[string]string{"HTTP_PROXY": "", "HTTPS_PROXY": "https://override:3128", "NO_PROXY": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := ProxyEnvVars(tt.cluster); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProxyEnvVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPodSecurityLabels(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "multicluster-engine",
+		Labels: map[string]string{"team": "hub"},
+	}}
+
+	if SetPodSecurityLabels(ns, false) {
+		t.Errorf("Expected a namespace that was never labeled to be left unchanged")
+	}
+	if !SetPodSecurityLabels(ns, true) {
+		t.Fatalf("Expected the namespace to be labeled")
+	}
+	for _, l := range PodSecurityLabels {
+		if ns.Labels[l] != PodSecurityRestricted {
+			t.Errorf("Expected label %s=%s. Got %q", l, PodSecurityRestricted, ns.Labels[l])
+		}
+	}
+	if SetPodSecurityLabels(ns, true) {
+		t.Errorf("Expected a labeled namespace to be left unchanged")
+	}
+
+	if !SetPodSecurityLabels(ns, false) {
+		t.Fatalf("Expected the labels to be removed")
+	}
+	if !reflect.DeepEqual(ns.Labels, map[string]string{"team": "hub"}) {
+		t.Errorf("Expected only the Pod Security labels to be removed. Got %v", ns.Labels)
+	}
+	if _, ok := ns.Annotations[AnnotationPodSecurityManaged]; ok {
+		t.Errorf("Expected the managed annotation to be removed")
+	}
+}
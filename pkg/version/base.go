@@ -16,4 +16,6 @@ var (
 	gitTreeState = "unknown"
 	// Build date in ISO8601 format, output of $(date -u +'%Y-%m-%dT%H:%M:%SZ')
 	buildDate = "unknown"
+	// Minimum OpenShift version the operator installs on. The -0 suffix admits prerelease builds.
+	minimumOCPVersion = "4.8.0-0"
 )
@@ -5,6 +5,8 @@ package version
 import (
 	"fmt"
 	"runtime"
+
+	semver "github.com/Masterminds/semver"
 )
 
 // Info contains versioning information.
@@ -33,3 +35,30 @@ func Get() Info {
 		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 }
+
+// MinimumOCPVersion returns the oldest OpenShift version the operator installs on
+func MinimumOCPVersion() string {
+	return minimumOCPVersion
+}
+
+// IsDowngrade returns true if running is an older release than installed. Only the major, minor and patch
+// versions are compared, so builds between two tags are treated as the earlier tag. Versions that cannot be
+// parsed are never considered a downgrade.
+func IsDowngrade(installed, running string) bool {
+	installedVersion, err := semver.NewVersion(installed)
+	if err != nil {
+		return false
+	}
+	runningVersion, err := semver.NewVersion(running)
+	if err != nil {
+		return false
+	}
+
+	if runningVersion.Major() != installedVersion.Major() {
+		return runningVersion.Major() < installedVersion.Major()
+	}
+	if runningVersion.Minor() != installedVersion.Minor() {
+		return runningVersion.Minor() < installedVersion.Minor()
+	}
+	return runningVersion.Patch() < installedVersion.Patch()
+}
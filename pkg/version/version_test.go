@@ -0,0 +1,29 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package version
+
+import "testing"
+
+func TestIsDowngrade(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed string
+		running   string
+		want      bool
+	}{
+		{name: "Same version", installed: "v2.1.0", running: "v2.1.0", want: false},
+		{name: "Upgrade", installed: "v2.0.3", running: "v2.1.0", want: false},
+		{name: "Minor downgrade", installed: "v2.1.0", running: "v2.0.3", want: true},
+		{name: "Patch downgrade", installed: "v2.1.2", running: "v2.1.1", want: true},
+		{name: "Build after installed tag", installed: "v2.1.0", running: "v2.1.0-12-gabcdef0", want: false},
+		{name: "Nothing installed", installed: "", running: "v2.1.0", want: false},
+		{name: "Unparseable running version", installed: "v2.1.0", running: "unknown", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDowngrade(tt.installed, tt.running); got != tt.want {
+				t.Errorf("IsDowngrade(%q, %q) = %v, want %v", tt.installed, tt.running, got, tt.want)
+			}
+		})
+	}
+}
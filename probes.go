@@ -0,0 +1,53 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// healthProbes serves the liveness and readiness probes in place of the manager's probe server. The checks in
+// readyz make up /readyz, the operator's own readiness. Those in separate are only served at /readyz/<name>: they
+// report on the MultiClusterEngine, which only the leader reconciles, while every replica serves the webhook and
+// must stay ready to accept the changes needed to fix an unhealthy MultiClusterEngine.
+type healthProbes struct {
+	healthz  healthz.Handler
+	readyz   healthz.Handler
+	separate healthz.Handler
+}
+
+// handler returns the handler of the probe endpoints
+func (p *healthProbes) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", http.StripPrefix("/healthz", &p.healthz))
+	mux.Handle("/healthz/", http.StripPrefix("/healthz", &p.healthz))
+	mux.Handle("/readyz", http.StripPrefix("/readyz", &p.readyz))
+	mux.Handle("/readyz/", http.StripPrefix("/readyz", &p.readyz))
+	for name := range p.separate.Checks {
+		mux.Handle("/readyz/"+name, http.StripPrefix("/readyz", &p.separate))
+	}
+	return mux
+}
+
+// serve serves the probes on addr until ctx is done. It is started before the manager, so the liveness probe
+// succeeds while the caches sync.
+func (p *healthProbes) serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: p.handler()}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -456,7 +456,7 @@ var webhookTests = func() func() {
 				key.Spec.TargetNamespace = "shouldnotexist"
 				err := k8sClient.Update(ctx, key)
 				g.Expect(err).ShouldNot(BeNil())
-				g.Expect(err.Error()).Should(ContainSubstring("changes cannot be made to target namespace"))
+				g.Expect(err.Error()).Should(ContainSubstring("TargetNamespace is immutable after creation"))
 			}, 10*time.Second, time.Second).Should(Succeed())
 
 		})